@@ -0,0 +1,58 @@
+// Package accesslog gives every gRPC call and HTTP request a request ID,
+// taken from Envoy's x-request-id or generated, carries it on the context
+// and echoes it back, and logs one line for each HTTP request to match
+// grpc_logrus's line for each call. zenkit.ContextLogger's entries carry
+// the ID too.
+package accesslog
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+)
+
+const (
+	// Header is the request ID header, and gRPC metadata key, Envoy sets.
+	Header = "x-request-id"
+	// Field is the request ID's log field.
+	Field = "request_id"
+
+	// maxIDLength bounds the IDs taken from callers.
+	maxIDLength = 128
+)
+
+type idKey struct{}
+
+// WithID returns a copy of ctx carrying the request ID.
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, idKey{}, id)
+}
+
+// FromContext returns the request ID on ctx, or "" if there is none.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(idKey{}).(string)
+	return id
+}
+
+// NewID generates a request ID, a random UUID as Envoy's are.
+func NewID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// requestID is the caller's ID if it is sensible, to log as is, and a new
+// one otherwise.
+func requestID(id string) string {
+	if id == "" || len(id) > maxIDLength {
+		return NewID()
+	}
+	for _, c := range id {
+		if c <= ' ' || c > '~' {
+			return NewID()
+		}
+	}
+	return id
+}
@@ -0,0 +1,40 @@
+package accesslog
+
+import (
+	"context"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware"
+	"github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// tagCall puts the call's request ID on its context and tags, and sends it
+// back in the response headers and trailers. Chain it after grpc_ctxtags
+// and before grpc_logrus.
+func tagCall(ctx context.Context) context.Context {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md[Header]) > 0 {
+		id = md[Header][0]
+	}
+	id = requestID(id)
+	grpc_ctxtags.Extract(ctx).Set(Field, id)
+	md := metadata.Pairs(Header, id)
+	grpc.SetHeader(ctx, md)
+	grpc.SetTrailer(ctx, md)
+	return WithID(ctx, id)
+}
+
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(tagCall(ctx), req)
+	}
+}
+
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		wrapped := grpc_middleware.WrapServerStream(ss)
+		wrapped.WrappedContext = tagCall(ss.Context())
+		return handler(srv, wrapped)
+	}
+}
@@ -0,0 +1,147 @@
+package accesslog
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"time"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/logrus/ctxlogrus"
+	"github.com/sirupsen/logrus"
+	"github.com/zenoss/zenkit"
+)
+
+// Middleware logs a line for each HTTP request once it is answered, with
+// its request ID, which is also set on the request for handlers that pass
+// it on, such as the gateway, and in the X-Request-Id response header.
+// Handlers can log with zenkit.ContextLogger and add fields to the line
+// with ctxlogrus.AddFields, such as the caller's zing.tnt and zing.usr;
+// when they haven't, identify, which may be nil, is asked for the identity
+// afterwards. Requests for the quiet paths, such as probes, are logged at
+// debug level.
+func Middleware(log *logrus.Entry, identify func(*http.Request) zenkit.TenantIdentity, next http.Handler, quiet ...string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		id := requestID(r.Header.Get(Header))
+		r.Header.Set(Header, id)
+		w.Header().Set(Header, id)
+
+		entry := log.WithFields(logrus.Fields{
+			Field:             id,
+			"system":          "http",
+			"span.kind":       "server",
+			"http.method":     r.Method,
+			"http.path":       r.URL.Path,
+			"http.start_time": start.Format(time.RFC3339),
+			"peer.address":    r.RemoteAddr,
+		})
+		ctx := ctxlogrus.ToContext(WithID(r.Context(), id), entry)
+		r = r.WithContext(ctx)
+
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(sw, r)
+
+		line := ctxlogrus.Extract(ctx)
+		if _, ok := line.Data[zenkit.LogTenantField]; !ok && identify != nil {
+			if ident := identify(r); ident != nil {
+				line = line.WithFields(logrus.Fields{
+					zenkit.LogTenantField: ident.Tenant(),
+					zenkit.LogUserField:   ident.ID(),
+				})
+			}
+		}
+		line = line.WithFields(logrus.Fields{
+			"http.status":  sw.status,
+			"http.time_ms": float32(time.Since(start).Nanoseconds()/1000) / 1000,
+		})
+		level := statusLevel(sw.status)
+		for _, path := range quiet {
+			if r.URL.Path == path {
+				level = logrus.DebugLevel
+			}
+		}
+		msg := "finished HTTP request with status " + strconv.Itoa(sw.status)
+		switch level {
+		case logrus.DebugLevel:
+			line.Debug(msg)
+		case logrus.InfoLevel:
+			line.Info(msg)
+		case logrus.WarnLevel:
+			line.Warn(msg)
+		default:
+			line.Error(msg)
+		}
+	})
+}
+
+// Recover answers the requests whose handlers panic with a 500, as
+// grpc_recovery does for calls, logging the panic and its stack. A panic
+// once the response has started aborts it instead, as http.ErrAbortHandler
+// does, which is passed on. Chain it inside Middleware, so the request's
+// line records the 500.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			if p == http.ErrAbortHandler {
+				panic(p)
+			}
+			ctxlogrus.Extract(r.Context()).WithFields(logrus.Fields{
+				"panic": fmt.Sprint(p),
+				"stack": string(debug.Stack()),
+			}).Error("recovered from panic")
+			if sw.wroteHeader {
+				panic(http.ErrAbortHandler)
+			}
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}()
+		next.ServeHTTP(sw, r)
+	})
+}
+
+// statusLevel follows grpc_logrus.DefaultCodeToLevel for the codes the
+// statuses stand for.
+func statusLevel(status int) logrus.Level {
+	switch {
+	case status == http.StatusServiceUnavailable:
+		return logrus.WarnLevel
+	case status >= 500:
+		return logrus.ErrorLevel
+	case status == http.StatusForbidden, status == http.StatusTooManyRequests:
+		return logrus.WarnLevel
+	}
+	return logrus.InfoLevel
+}
+
+// statusWriter records the response status. It passes on Flush, which the
+// gateway's streams need.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.wroteHeader = true
+		f.Flush()
+	}
+}
@@ -0,0 +1,133 @@
+// Package apikey exchanges Zenoss API keys for Auth0 access tokens, the job
+// the api-key-server does for the Envoy filters in yaml/.
+package apikey
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	ErrNoKey      = errors.New("no api key")
+	ErrInvalidKey = errors.New("invalid api key")
+)
+
+// Token is an access token issued for an API key.
+type Token struct {
+	AccessToken string    `json:"access_token"`
+	Expiry      time.Time `json:"expiry"`
+}
+
+// Valid reports whether the token is still usable at t.
+func (t *Token) Valid(at time.Time) bool {
+	return t != nil && t.AccessToken != "" && at.Before(t.Expiry)
+}
+
+// TokenSource exchanges an API key for an access token.
+type TokenSource interface {
+	Token(ctx context.Context, key string) (*Token, error)
+}
+
+// Auth0Config configures the client-credentials exchange with Auth0.
+type Auth0Config struct {
+	Domain       string
+	ClientID     string
+	ClientSecret string
+	Audience     string
+	// TokenURL overrides https://<Domain>/oauth/token, e.g. to point at a
+	// local stub.
+	TokenURL string
+	Client   *http.Client
+}
+
+// Auth0 performs a client-credentials grant for each key. The key is passed
+// as the api_key parameter, where the tenant's client credentials exchange
+// hook resolves it to the tenant and user claims of the issued token.
+type Auth0 struct {
+	cfg Auth0Config
+	now func() time.Time
+}
+
+// NewAuth0 creates an Auth0 TokenSource.
+func NewAuth0(cfg Auth0Config) *Auth0 {
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	if cfg.TokenURL == "" {
+		cfg.TokenURL = "https://" + cfg.Domain + "/oauth/token"
+	}
+	return &Auth0{cfg: cfg, now: time.Now}
+}
+
+// maxErrorBody bounds how much of an error response is read for its
+// description.
+const maxErrorBody = 64 << 10
+
+type auth0TokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Token implements TokenSource.
+func (a *Auth0) Token(ctx context.Context, key string) (*Token, error) {
+	if key == "" {
+		return nil, ErrNoKey
+	}
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {a.cfg.ClientID},
+		"client_secret": {a.cfg.ClientSecret},
+		"api_key":       {key},
+	}
+	if a.cfg.Audience != "" {
+		form.Set("audience", a.cfg.Audience)
+	}
+	req, err := http.NewRequest(http.MethodPost, a.cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to build token request")
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := a.cfg.Client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to reach Auth0")
+	}
+	defer resp.Body.Close()
+
+	var body auth0TokenResponse
+	if resp.StatusCode != http.StatusOK {
+		// Error bodies may be HTML, or empty, coming from a proxy in front
+		// of Auth0, so they only add detail when they decode.
+		json.NewDecoder(io.LimitReader(resp.Body, maxErrorBody)).Decode(&body)
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			if body.ErrorDescription != "" {
+				return nil, errors.Wrap(ErrInvalidKey, body.ErrorDescription)
+			}
+			return nil, ErrInvalidKey
+		}
+		detail := strings.TrimSpace(body.Error + " " + body.ErrorDescription)
+		if detail == "" {
+			detail = http.StatusText(resp.StatusCode)
+		}
+		return nil, errors.Errorf("Auth0 returned %d: %s", resp.StatusCode, detail)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, errors.Wrap(err, "unable to decode Auth0 response")
+	}
+	if body.AccessToken == "" {
+		return nil, errors.New("Auth0 returned no access token")
+	}
+	return &Token{
+		AccessToken: body.AccessToken,
+		Expiry:      a.now().Add(time.Duration(body.ExpiresIn) * time.Second),
+	}, nil
+}
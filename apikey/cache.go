@@ -0,0 +1,133 @@
+package apikey
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/go-redis/cache"
+	"github.com/hashicorp/golang-lru/simplelru"
+	"github.com/pkg/errors"
+	"github.com/zenoss/grpctest/metrics"
+	"go.opencensus.io/trace"
+)
+
+// ExpiryMargin is how long before a token expires it stops being served
+// from the cache, so callers never receive a token that is about to lapse.
+const ExpiryMargin = time.Minute
+
+// CacheConfig configures a CachedSource.
+type CacheConfig struct {
+	// Size is the number of tokens kept in process.
+	Size int
+	// TTL caps how long a token is cached, even if it is valid for longer.
+	TTL time.Duration
+	// Redis, if set, is a shared cache checked after the in-process one,
+	// typically from zenkit.NewCacheId.
+	Redis *cache.Codec
+}
+
+// CachedSource caches the tokens of another TokenSource, first in an
+// in-process LRU and then optionally in Redis. Keys are hashed before they
+// are used as cache keys so the raw API keys are never stored.
+type CachedSource struct {
+	source TokenSource
+	cfg    CacheConfig
+	now    func() time.Time
+
+	mu  sync.Mutex
+	lru *simplelru.LRU
+}
+
+// NewCachedSource wraps source with a cache.
+func NewCachedSource(source TokenSource, cfg CacheConfig) (*CachedSource, error) {
+	lru, err := simplelru.NewLRU(cfg.Size, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create token cache")
+	}
+	return &CachedSource{source: source, cfg: cfg, now: time.Now, lru: lru}, nil
+}
+
+// Token implements TokenSource.
+func (c *CachedSource) Token(ctx context.Context, key string) (*Token, error) {
+	if key == "" {
+		return nil, ErrNoKey
+	}
+	id := cacheKey(key)
+	now := c.now()
+	ctx, span := trace.StartSpan(ctx, "apikey.Token")
+	defer span.End()
+
+	c.mu.Lock()
+	v, ok := c.lru.Get(id)
+	c.mu.Unlock()
+	if ok {
+		if tok := v.(*Token); tok.Valid(now) {
+			metrics.RecordCacheLookup(ctx, "apikey_lru", "hit")
+			span.AddAttributes(trace.StringAttribute("cache", "lru"))
+			return tok, nil
+		}
+	}
+	metrics.RecordCacheLookup(ctx, "apikey_lru", "miss")
+
+	if c.cfg.Redis != nil {
+		var tok Token
+		if err := c.cfg.Redis.Get(id, &tok); err == nil && tok.Valid(now) {
+			metrics.RecordCacheLookup(ctx, "apikey_redis", "hit")
+			span.AddAttributes(trace.StringAttribute("cache", "redis"))
+			c.add(id, &tok)
+			return &tok, nil
+		}
+		metrics.RecordCacheLookup(ctx, "apikey_redis", "miss")
+	}
+
+	span.AddAttributes(trace.StringAttribute("cache", "miss"))
+	tok, err := c.source.Token(ctx, key)
+	if err != nil {
+		span.SetStatus(trace.Status{Code: trace.StatusCodeUnknown, Message: err.Error()})
+		return nil, err
+	}
+	// Cached copies expire at whichever comes first, the TTL or just
+	// before the token itself does.
+	cached := &Token{AccessToken: tok.AccessToken, Expiry: tok.Expiry.Add(-ExpiryMargin)}
+	if c.cfg.TTL > 0 && now.Add(c.cfg.TTL).Before(cached.Expiry) {
+		cached.Expiry = now.Add(c.cfg.TTL)
+	}
+	if !cached.Valid(now) {
+		return tok, nil
+	}
+	c.add(id, cached)
+	if c.cfg.Redis != nil {
+		// A shared cache that's down shouldn't fail the exchange.
+		c.cfg.Redis.Set(&cache.Item{
+			Key:        id,
+			Object:     cached,
+			Expiration: cached.Expiry.Sub(now),
+		})
+	}
+	return tok, nil
+}
+
+// Forget drops any cached token for key.
+func (c *CachedSource) Forget(key string) {
+	id := cacheKey(key)
+	c.mu.Lock()
+	c.lru.Remove(id)
+	c.mu.Unlock()
+	if c.cfg.Redis != nil {
+		c.cfg.Redis.Delete(id)
+	}
+}
+
+func (c *CachedSource) add(id string, tok *Token) {
+	c.mu.Lock()
+	c.lru.Add(id, tok)
+	c.mu.Unlock()
+}
+
+func cacheKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return "apikey:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,33 @@
+package apikey
+
+import (
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+// KeyHeader carries the API key on incoming requests.
+const KeyHeader = "z-api-key"
+
+// ExtAuthzHandler implements the contract of Envoy's envoy.ext_authz filter
+// in http_service mode, as configured in yaml/apikeys_auth_ext.yaml. Envoy
+// sends the original method and path with only the z-api-key header; a 200
+// response allows the request and its authorization header is copied
+// upstream, anything else is returned to the client as the denial.
+func ExtAuthzHandler(source TokenSource, log *logrus.Entry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		log := log.WithField("path", r.URL.Path)
+		tok, err := source.Token(r.Context(), r.Header.Get(KeyHeader))
+		if err != nil {
+			if StatusFromError(err) >= http.StatusInternalServerError {
+				log.WithError(err).Error("unable to exchange api key")
+			} else {
+				log.WithError(err).Debug("denied request")
+			}
+			writeResponse(w, http.StatusForbidden, Response{Error: err.Error()})
+			return
+		}
+		w.Header().Set("Authorization", "Bearer "+tok.AccessToken)
+		w.WriteHeader(http.StatusOK)
+	})
+}
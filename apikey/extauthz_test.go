@@ -0,0 +1,91 @@
+package apikey
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+// auth0Stub answers client-credentials grants the way Auth0, or a proxy in
+// front of it, does for each api_key.
+func auth0Stub(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	switch r.PostForm.Get("api_key") {
+	case "good":
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"tok-123","expires_in":3600,"token_type":"Bearer"}`))
+	case "revoked":
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":"access_denied","error_description":"key revoked"}`))
+	case "blocked":
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`<html><body>Forbidden</body></html>`))
+	default:
+		w.WriteHeader(http.StatusBadGateway)
+	}
+}
+
+func TestExtAuthzHandler(t *testing.T) {
+	auth0 := httptest.NewServer(http.HandlerFunc(auth0Stub))
+	defer auth0.Close()
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	handler := ExtAuthzHandler(NewAuth0(Auth0Config{
+		ClientID:     "client",
+		ClientSecret: "secret",
+		TokenURL:     auth0.URL,
+		Client:       auth0.Client(),
+	}), logrus.NewEntry(log))
+
+	for _, tc := range []struct {
+		name          string
+		key           string
+		code          int
+		authorization string
+		// err is part of the JSON error of a denial.
+		err string
+	}{
+		{name: "valid key", key: "good", code: http.StatusOK, authorization: "Bearer tok-123"},
+		{name: "no key", code: http.StatusForbidden, err: "no api key"},
+		{name: "revoked key", key: "revoked", code: http.StatusForbidden, err: "key revoked: invalid api key"},
+		{name: "HTML denial", key: "blocked", code: http.StatusForbidden, err: "invalid api key"},
+		{name: "Auth0 down", key: "down", code: http.StatusForbidden, err: "Auth0 returned 502: Bad Gateway"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// Envoy sends the original method and path with the key.
+			r := httptest.NewRequest(http.MethodGet, "/math/square/3", nil)
+			if tc.key != "" {
+				r.Header.Set(KeyHeader, tc.key)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tc.code {
+				t.Fatalf("got status %d, want %d", w.Code, tc.code)
+			}
+			if got := w.Header().Get("Authorization"); got != tc.authorization {
+				t.Errorf("got Authorization %q, want %q", got, tc.authorization)
+			}
+			if tc.code == http.StatusOK {
+				return
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("got Content-Type %q, want application/json", ct)
+			}
+			var resp Response
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("denial is not JSON: %v", err)
+			}
+			if !strings.Contains(resp.Error, tc.err) {
+				t.Errorf("got error %q, want it to contain %q", resp.Error, tc.err)
+			}
+		})
+	}
+}
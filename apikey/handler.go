@@ -0,0 +1,57 @@
+package apikey
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Response is the body of an /accessToken response: the token in Data on
+// success, or a message in Error.
+type Response struct {
+	Data  string `json:"data,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// AccessTokenHandler serves GET /accessToken?key=<api key>, answering
+// {"data":"<token>"} or {"error":"<message>"} as the Lua filter expects.
+func AccessTokenHandler(source TokenSource, log *logrus.Entry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			writeResponse(w, http.StatusMethodNotAllowed, Response{Error: "method not allowed"})
+			return
+		}
+		tok, err := source.Token(r.Context(), r.URL.Query().Get("key"))
+		if err != nil {
+			code := StatusFromError(err)
+			if code >= http.StatusInternalServerError {
+				log.WithError(err).Error("unable to exchange api key")
+			} else {
+				log.WithError(err).Debug("rejected api key")
+			}
+			writeResponse(w, code, Response{Error: err.Error()})
+			return
+		}
+		writeResponse(w, http.StatusOK, Response{Data: tok.AccessToken})
+	})
+}
+
+// StatusFromError maps an exchange error to an HTTP status code.
+func StatusFromError(err error) int {
+	switch errors.Cause(err) {
+	case ErrNoKey:
+		return http.StatusBadRequest
+	case ErrInvalidKey:
+		return http.StatusForbidden
+	}
+	return http.StatusBadGateway
+}
+
+func writeResponse(w http.ResponseWriter, code int, resp Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(resp)
+}
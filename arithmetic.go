@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Arithmetic methods check their results instead of letting them wrap
+// around. A result that doesn't fit is OutOfRange and a value that can't be
+// parsed is InvalidArgument; both carry a BadRequest naming the request
+// field to blame, which the gateway passes on in the JSON error body.
+
+// maxBigDigits bounds the digits of a SquareBig value. Squaring is
+// quadratic in the digits, and the square of a much longer value wouldn't
+// fit in the 4MB a default client receives anyway.
+const maxBigDigits = 10000
+
+// mul32 multiplies a and b, reporting whether the product fits in an int32.
+func mul32(a, b int32) (int32, bool) {
+	p := int64(a) * int64(b)
+	return int32(p), p >= math.MinInt32 && p <= math.MaxInt32
+}
+
+// mul64 multiplies a and b, reporting whether the product fits in an int64.
+func mul64(a, b int64) (int64, bool) {
+	p := new(big.Int).Mul(big.NewInt(a), big.NewInt(b))
+	return p.Int64(), p.IsInt64()
+}
+
+// bigDigits is the number of digits of a decimal integer, not counting
+// its sign.
+func bigDigits(value string) int {
+	return len(strings.TrimLeft(value, "+-"))
+}
+
+// outOfRange is the error for a result that doesn't fit its type.
+func outOfRange(field, format string, args ...interface{}) error {
+	return badRequest(codes.OutOfRange, field, fmt.Sprintf(format, args...))
+}
+
+// invalidArgument is the error for a field holding an unusable value.
+func invalidArgument(field, format string, args ...interface{}) error {
+	return badRequest(codes.InvalidArgument, field, fmt.Sprintf(format, args...))
+}
+
+func badRequest(code codes.Code, field, description string) error {
+	st := status.New(code, description)
+	if d, err := st.WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{
+			Field:       field,
+			Description: description,
+		}},
+	}); err == nil {
+		st = d
+	}
+	return st.Err()
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	pb "github.com/zenoss/grpctest/pb"
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestSquareBig(t *testing.T) {
+	longest := strings.Repeat("9", maxBigDigits)
+	for _, tc := range []struct {
+		name  string
+		value string
+		code  codes.Code
+		want  string
+	}{
+		{name: "small", value: "-12", want: "144"},
+		{name: "past int64", value: "9223372036854775808", want: "85070591730234615865843651857942052864"},
+		{name: "not a number", value: "12a", code: codes.InvalidArgument},
+		{name: "longest", value: longest, want: strings.Repeat("9", maxBigDigits-1) + "8" + strings.Repeat("0", maxBigDigits-1) + "1"},
+		{name: "signed longest", value: "-" + longest, want: strings.Repeat("9", maxBigDigits-1) + "8" + strings.Repeat("0", maxBigDigits-1) + "1"},
+		{name: "too long", value: "1" + longest, code: codes.InvalidArgument},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := (&server{}).SquareBig(context.Background(), &pb.BigInteger{Value: tc.value})
+			if got := status.Code(err); got != tc.code {
+				t.Fatalf("got code %s, want %s: %v", got, tc.code, err)
+			}
+			if tc.code != codes.OK {
+				return
+			}
+			if out.Value != tc.want {
+				t.Errorf("got %.40s…, want %.40s…", out.Value, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"strings"
+
+	"github.com/zenoss/zenkit"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+// tenantClaims mirrors the Auth0 claims zenkit reads from unverified tokens.
+type tenantClaims struct {
+	jwt.Claims
+	ScopeValue      string   `json:"scope,omitempty"`
+	ScopesValue     string   `json:"scopes,omitempty"`
+	TenantValue     string   `json:"https://dev.zing.ninja/tenant,omitempty"`
+	EmailValue      string   `json:"https://dev.zing.ninja/email,omitempty"`
+	ConnectionValue string   `json:"https://dev.zing.ninja/connection,omitempty"`
+	ClientIDValue   string   `json:"https://dev.zing.ninja/clientid,omitempty"`
+	GroupsValue     []string `json:"https://zenoss.com/groups,omitempty"`
+	RolesValue      []string `json:"https://zenoss.com/roles,omitempty"`
+}
+
+// validate applies the same required-claim checks as
+// zenkit.NewAuth0TenantIdentity.
+func (c *tenantClaims) validate() error {
+	if c.Subject == "" {
+		return zenkit.ErrorNoSubject
+	}
+	if c.ScopesValue == "" && c.ScopeValue == "" {
+		return zenkit.ErrorNoScopes
+	}
+	if c.TenantValue == "" {
+		return zenkit.ErrorNoTenant
+	}
+	if c.ConnectionValue == "" {
+		return zenkit.ErrorNoConnection
+	}
+	return nil
+}
+
+func (c *tenantClaims) ID() string {
+	parts := strings.Split(c.Claims.Subject, "|")
+	return parts[len(parts)-1]
+}
+
+func (c *tenantClaims) Scopes() []string {
+	if c.ScopeValue != "" {
+		return strings.Split(c.ScopeValue, " ")
+	}
+	return strings.Split(c.ScopesValue, " ")
+}
+
+func (c *tenantClaims) Tenant() string     { return c.TenantValue }
+func (c *tenantClaims) Email() string      { return c.EmailValue }
+func (c *tenantClaims) Connection() string { return c.ConnectionValue }
+func (c *tenantClaims) ClientID() string   { return c.ClientIDValue }
+func (c *tenantClaims) Groups() []string   { return c.GroupsValue }
+func (c *tenantClaims) Roles() []string    { return c.RolesValue }
+
+func (c *tenantClaims) HasScope(scope string) bool {
+	return zenkit.StringInSlice(scope, c.Scopes())
+}
+
+func (c *tenantClaims) HasGroup(group string) bool {
+	return zenkit.StringInSlice(group, c.Groups())
+}
+
+func (c *tenantClaims) HasRole(role string) bool {
+	return zenkit.StringInSlice(role, c.Roles())
+}
@@ -0,0 +1,189 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	"github.com/grpc-ecosystem/go-grpc-middleware/util/metautils"
+	"github.com/pkg/errors"
+	"github.com/zenoss/zenkit"
+	"go.opencensus.io/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+// SigningAlgorithm is the only algorithm tokens may be signed with.
+const SigningAlgorithm = jose.RS256
+
+var (
+	ErrNoToken              = errors.New("no bearer token on request")
+	ErrNoKeyID              = errors.New("token header has no key id")
+	ErrUnsupportedAlgorithm = errors.New("token is not signed with " + string(SigningAlgorithm))
+)
+
+// Config configures a Verifier.
+type Config struct {
+	// JWKSURI is where the signing keys are published.
+	JWKSURI string
+	// Issuer must match the iss claim exactly.
+	Issuer string
+	// Audience must all be present in the aud claim. Empty skips the check.
+	Audience []string
+	// Leeway is the clock skew allowed when checking exp and nbf.
+	Leeway time.Duration
+	// Header carries the bearer token, on HTTP requests and in gRPC
+	// metadata. Empty means authorization.
+	Header string
+	// Client fetches the JWKS document. Nil uses http.DefaultClient.
+	Client *http.Client
+}
+
+// Verifier creates tenant identities from signed Auth0 tokens.
+type Verifier struct {
+	cfg  Config
+	keys *KeySet
+	now  func() time.Time
+}
+
+// NewVerifier creates a Verifier that checks tokens against the keys
+// published at cfg.JWKSURI.
+func NewVerifier(cfg Config) *Verifier {
+	if cfg.Header == "" {
+		cfg.Header = "authorization"
+	}
+	return &Verifier{
+		cfg:  cfg,
+		keys: NewKeySet(cfg.JWKSURI, cfg.Client),
+		now:  time.Now,
+	}
+}
+
+// Keys returns the key set used to verify signatures.
+func (v *Verifier) Keys() *KeySet {
+	return v.keys
+}
+
+// Verify checks the token signature, issuer, audience and expiry, and
+// returns the identity from its claims.
+func (v *Verifier) Verify(ctx context.Context, raw string) (zenkit.TenantIdentity, error) {
+	ctx, span := trace.StartSpan(ctx, "auth.Verify")
+	defer span.End()
+	ident, err := v.verify(ctx, raw)
+	if err != nil {
+		span.SetStatus(trace.Status{Code: trace.StatusCodeUnauthenticated, Message: err.Error()})
+		return nil, err
+	}
+	span.AddAttributes(trace.StringAttribute("tenant", ident.Tenant()))
+	return ident, nil
+}
+
+func (v *Verifier) verify(ctx context.Context, raw string) (zenkit.TenantIdentity, error) {
+	tok, err := jwt.ParseSigned(raw)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse token")
+	}
+	if len(tok.Headers) != 1 {
+		return nil, errors.Wrap(zenkit.ErrorInvalidToken, "expected exactly one signature")
+	}
+	header := tok.Headers[0]
+	if header.Algorithm != string(SigningAlgorithm) {
+		return nil, ErrUnsupportedAlgorithm
+	}
+	if header.KeyID == "" {
+		return nil, ErrNoKeyID
+	}
+	key, err := v.keys.Key(ctx, header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	if key.Algorithm != "" && key.Algorithm != string(SigningAlgorithm) {
+		return nil, ErrUnsupportedAlgorithm
+	}
+
+	var claims tenantClaims
+	if err := tok.Claims(key.Key, &claims); err != nil {
+		return nil, errors.Wrap(err, "unable to verify token signature")
+	}
+	expected := jwt.Expected{
+		Issuer:   v.cfg.Issuer,
+		Audience: jwt.Audience(v.cfg.Audience),
+		Time:     v.now(),
+	}
+	if err := claims.ValidateWithLeeway(expected, v.cfg.Leeway); err != nil {
+		return nil, errors.Wrap(err, "invalid token claims")
+	}
+	if claims.Expiry == nil {
+		return nil, errors.Wrap(jwt.ErrExpired, "token has no expiry")
+	}
+	if err := claims.validate(); err != nil {
+		return nil, err
+	}
+	return &claims, nil
+}
+
+// FromRequest verifies the bearer token in the request's authorization
+// header.
+func (v *Verifier) FromRequest(r *http.Request) (zenkit.TenantIdentity, error) {
+	raw, ok := BearerToken(r.Header.Get(v.cfg.Header))
+	if !ok {
+		return nil, ErrNoToken
+	}
+	return v.Verify(r.Context(), raw)
+}
+
+// AuthFunc is a grpc_auth.AuthFunc that puts the verified identity on the
+// context, the way zenkit.UnverifiedIdentity does for unverified tokens.
+func (v *Verifier) AuthFunc(ctx context.Context) (context.Context, error) {
+	meta := metautils.ExtractIncoming(ctx)
+	ctx = meta.ToOutgoing(ctx)
+
+	raw, ok := BearerToken(meta.Get(v.cfg.Header))
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, ErrNoToken.Error())
+	}
+	ident, err := v.Verify(ctx, raw)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	grpc_ctxtags.Extract(ctx).
+		Set(zenkit.LogTenantField, ident.Tenant()).
+		Set(zenkit.LogUserField, ident.ID())
+	return zenkit.WithTenantIdentity(ctx, ident), nil
+}
+
+// BearerToken strips the bearer scheme from an authorization header value.
+func BearerToken(header string) (string, bool) {
+	parts := strings.SplitN(strings.TrimSpace(header), " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], zenkit.AuthHeaderScheme) {
+		return "", false
+	}
+	token := strings.TrimSpace(parts[1])
+	return token, token != ""
+}
+
+// Anonymous is an AuthFunc for services whose callers don't authenticate,
+// such as the ones Envoy calls. zenkit's interceptors expect an identity on
+// every call, so it supplies an empty one.
+func Anonymous(ctx context.Context) (context.Context, error) {
+	return zenkit.WithTenantIdentity(ctx, &tenantClaims{}), nil
+}
+
+// UnverifiedClaims decodes a token's claims without verifying it, to show
+// them; nothing else should trust them.
+func UnverifiedClaims(raw string) (map[string]interface{}, error) {
+	tok, err := jwt.ParseSigned(raw)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse token")
+	}
+	claims := map[string]interface{}{}
+	if err := tok.UnsafeClaimsWithoutVerification(&claims); err != nil {
+		return nil, errors.Wrap(err, "unable to decode token claims")
+	}
+	return claims, nil
+}
@@ -0,0 +1,207 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+const (
+	testIssuer   = "https://zenoss-dev.auth0.com/"
+	testAudience = "https://dev.zing.ninja"
+)
+
+// jwksStub publishes a JWKS document, counting the fetches.
+type jwksStub struct {
+	mu      sync.Mutex
+	keys    []jose.JSONWebKey
+	status  int
+	fetches int
+}
+
+func (s *jwksStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fetches++
+	if s.status != 0 {
+		w.WriteHeader(s.status)
+		return
+	}
+	json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: s.keys})
+}
+
+func (s *jwksStub) publish(keys ...*signingKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = nil
+	for _, k := range keys {
+		s.keys = append(s.keys, jose.JSONWebKey{Key: &k.priv.PublicKey, KeyID: k.kid, Algorithm: string(SigningAlgorithm), Use: "sig"})
+	}
+}
+
+func (s *jwksStub) fail(status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+}
+
+func (s *jwksStub) fetched() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fetches
+}
+
+type signingKey struct {
+	kid  string
+	priv *rsa.PrivateKey
+}
+
+func newSigningKey(t *testing.T, kid string) *signingKey {
+	t.Helper()
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &signingKey{kid: kid, priv: priv}
+}
+
+func (k *signingKey) sign(t *testing.T, claims interface{}) string {
+	t.Helper()
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: SigningAlgorithm, Key: k.priv},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", k.kid),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := jwt.Signed(signer).Claims(claims).CompactSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func testClaims(now time.Time, audience string, expiry time.Time) *tenantClaims {
+	return &tenantClaims{
+		Claims: jwt.Claims{
+			Subject:  "auth0|zcuser",
+			Issuer:   testIssuer,
+			Audience: jwt.Audience{audience},
+			IssuedAt: jwt.NewNumericDate(now.Add(-time.Minute)),
+			Expiry:   jwt.NewNumericDate(expiry),
+		},
+		ScopeValue:      "read:math",
+		TenantValue:     "ACME",
+		EmailValue:      "zcuser@acme.example.com",
+		ConnectionValue: "Username-Password-Authentication",
+	}
+}
+
+// newTestVerifier verifies against stub, with its clock fixed at now.
+func newTestVerifier(stub *jwksStub, now time.Time) (*Verifier, func()) {
+	srv := httptest.NewServer(stub)
+	v := NewVerifier(Config{
+		JWKSURI:  srv.URL,
+		Issuer:   testIssuer,
+		Audience: []string{testAudience},
+		Client:   srv.Client(),
+	})
+	clock := func() time.Time { return now }
+	v.now, v.keys.now = clock, clock
+	return v, srv.Close
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	current := newSigningKey(t, "current")
+	rotated := newSigningKey(t, "rotated")
+	unknown := newSigningKey(t, "unknown")
+
+	for _, tc := range []struct {
+		name string
+		// published are the keys the stub serves when the token is
+		// verified; the verifier has fetched the current key before.
+		published []*signingKey
+		token     func(t *testing.T) string
+		err       error
+		tenant    string
+	}{
+		{
+			name:      "good",
+			published: []*signingKey{current},
+			token: func(t *testing.T) string {
+				return current.sign(t, testClaims(now, testAudience, now.Add(time.Hour)))
+			},
+			tenant: "ACME",
+		},
+		{
+			name:      "expired",
+			published: []*signingKey{current},
+			token: func(t *testing.T) string {
+				return current.sign(t, testClaims(now, testAudience, now.Add(-time.Minute)))
+			},
+			err: jwt.ErrExpired,
+		},
+		{
+			name:      "wrong audience",
+			published: []*signingKey{current},
+			token: func(t *testing.T) string {
+				return current.sign(t, testClaims(now, "https://elsewhere.example.com", now.Add(time.Hour)))
+			},
+			err: jwt.ErrInvalidAudience,
+		},
+		{
+			name:      "unknown kid",
+			published: []*signingKey{current},
+			token: func(t *testing.T) string {
+				return unknown.sign(t, testClaims(now, testAudience, now.Add(time.Hour)))
+			},
+			err: ErrUnknownKey,
+		},
+		{
+			name:      "rotated key",
+			published: []*signingKey{current, rotated},
+			token: func(t *testing.T) string {
+				return rotated.sign(t, testClaims(now, testAudience, now.Add(time.Hour)))
+			},
+			tenant: "ACME",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			stub := &jwksStub{}
+			stub.publish(current)
+			v, done := newTestVerifier(stub, now)
+			defer done()
+			if err := v.Keys().Refresh(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			// Past the throttle, so an unknown kid may fetch again.
+			v.keys.now = func() time.Time { return now.Add(DefaultMinRefreshInterval + time.Second) }
+			stub.publish(tc.published...)
+
+			ident, err := v.Verify(context.Background(), tc.token(t))
+			if tc.err != nil {
+				if errors.Cause(err) != tc.err {
+					t.Fatalf("got error %v, want %v", err, tc.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ident.Tenant() != tc.tenant {
+				t.Errorf("got tenant %q, want %q", ident.Tenant(), tc.tenant)
+			}
+		})
+	}
+}
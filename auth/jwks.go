@@ -0,0 +1,179 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+	"gopkg.in/square/go-jose.v2"
+)
+
+const (
+	// DefaultRefreshInterval is how long a fetched key set is trusted before
+	// it is fetched again.
+	DefaultRefreshInterval = time.Hour
+
+	// DefaultMinRefreshInterval limits how often an unknown key ID can force
+	// a fetch, so a flood of forged tokens can't hammer the JWKS endpoint.
+	DefaultMinRefreshInterval = 30 * time.Second
+)
+
+var (
+	ErrUnknownKey = errors.New("no key in JWKS matches the token key id")
+)
+
+// KeySet fetches and caches a JSON Web Key Set document.
+type KeySet struct {
+	URI                string
+	Client             *http.Client
+	RefreshInterval    time.Duration
+	MinRefreshInterval time.Duration
+
+	// refreshing serializes fetches, so callers that find the keys stale
+	// at the same time wait for one fetch instead of each making their own.
+	refreshing sync.Mutex
+
+	mu        sync.RWMutex
+	keys      map[string]jose.JSONWebKey
+	fetched   time.Time
+	attempted time.Time
+	lastErr   error
+	now       func() time.Time
+}
+
+// NewKeySet creates a KeySet for the JWKS document at uri. A nil client
+// uses http.DefaultClient.
+func NewKeySet(uri string, client *http.Client) *KeySet {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &KeySet{
+		URI:                uri,
+		Client:             client,
+		RefreshInterval:    DefaultRefreshInterval,
+		MinRefreshInterval: DefaultMinRefreshInterval,
+		now:                time.Now,
+	}
+}
+
+// Key returns the key with the given key id. The document is fetched again
+// when the cache is stale, or when the key id is unknown and the keys may
+// have been rotated, but no sooner than MinRefreshInterval after the last
+// attempt, successful or not, so a failing endpoint isn't hammered either.
+func (s *KeySet) Key(ctx context.Context, kid string) (*jose.JSONWebKey, error) {
+	key, found, fetched, attempted := s.lookup(kid)
+	now := s.now()
+	stale := now.Sub(fetched) > s.RefreshInterval
+	due := now.Sub(attempted) > s.MinRefreshInterval
+	if (stale || !found) && due {
+		if err := s.refreshAfter(ctx, attempted); err != nil {
+			if found {
+				// Keep serving the cached key rather than failing closed on
+				// a transient fetch error.
+				return &key, nil
+			}
+			return nil, err
+		}
+		key, found, _, _ = s.lookup(kid)
+	}
+	if !found {
+		s.mu.RLock()
+		err := s.lastErr
+		s.mu.RUnlock()
+		if err != nil {
+			return nil, err
+		}
+		return nil, ErrUnknownKey
+	}
+	return &key, nil
+}
+
+// Refresh fetches the JWKS document and replaces the cached keys.
+func (s *KeySet) Refresh(ctx context.Context) error {
+	s.refreshing.Lock()
+	defer s.refreshing.Unlock()
+	return s.refreshLocked(ctx)
+}
+
+// refreshAfter refreshes the keys unless another caller attempted to since
+// the attempt seen, in which case its outcome is returned.
+func (s *KeySet) refreshAfter(ctx context.Context, seen time.Time) error {
+	s.refreshing.Lock()
+	defer s.refreshing.Unlock()
+	s.mu.RLock()
+	attempted, err := s.attempted, s.lastErr
+	s.mu.RUnlock()
+	if !attempted.Equal(seen) {
+		return err
+	}
+	return s.refreshLocked(ctx)
+}
+
+func (s *KeySet) refreshLocked(ctx context.Context) error {
+	s.mu.Lock()
+	s.attempted = s.now()
+	s.mu.Unlock()
+
+	ctx, span := trace.StartSpan(ctx, "auth.RefreshJWKS")
+	defer span.End()
+	span.AddAttributes(trace.StringAttribute("uri", s.URI))
+	err := s.refresh(ctx)
+	if err != nil {
+		span.SetStatus(trace.Status{Code: trace.StatusCodeUnavailable, Message: err.Error()})
+	}
+	s.mu.Lock()
+	s.lastErr = err
+	s.mu.Unlock()
+	return err
+}
+
+func (s *KeySet) refresh(ctx context.Context) error {
+	req, err := http.NewRequest(http.MethodGet, s.URI, nil)
+	if err != nil {
+		return errors.Wrap(err, "unable to build JWKS request")
+	}
+	resp, err := s.Client.Do(req.WithContext(ctx))
+	if err != nil {
+		return errors.Wrap(err, "unable to fetch JWKS")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("unexpected JWKS response status %d", resp.StatusCode)
+	}
+
+	var doc jose.JSONWebKeySet
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return errors.Wrap(err, "unable to decode JWKS")
+	}
+	keys := make(map[string]jose.JSONWebKey, len(doc.Keys))
+	for _, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		keys[k.KeyID] = k
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.fetched = s.now()
+	s.mu.Unlock()
+	return nil
+}
+
+// LastRefresh returns when the key set was last fetched successfully.
+func (s *KeySet) LastRefresh() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.fetched
+}
+
+func (s *KeySet) lookup(kid string) (key jose.JSONWebKey, found bool, fetched, attempted time.Time) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	key, found = s.keys[kid]
+	return key, found, s.fetched, s.attempted
+}
@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestKeySetThrottle(t *testing.T) {
+	start := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	key := newSigningKey(t, "current")
+
+	for _, tc := range []struct {
+		name string
+		// status is what the endpoint answers after the first fetch.
+		status int
+		// after is when, since the first fetch, an unknown kid is looked up.
+		after   time.Duration
+		fetches int
+	}{
+		{"within the interval", 0, DefaultMinRefreshInterval / 2, 1},
+		{"past the interval", 0, DefaultMinRefreshInterval * 2, 2},
+		{"failing, within the interval", http.StatusInternalServerError, DefaultMinRefreshInterval / 2, 1},
+		{"failing, past the interval", http.StatusInternalServerError, DefaultMinRefreshInterval * 2, 2},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			stub := &jwksStub{}
+			stub.publish(key)
+			srv := httptest.NewServer(stub)
+			defer srv.Close()
+			ks := NewKeySet(srv.URL, srv.Client())
+			now := start
+			ks.now = func() time.Time { return now }
+			ctx := context.Background()
+
+			if _, err := ks.Key(ctx, "current"); err != nil {
+				t.Fatal(err)
+			}
+			stub.fail(tc.status)
+			now = start.Add(tc.after)
+			// The second lookup of the same unknown kid is always throttled.
+			for i := 0; i < 2; i++ {
+				if _, err := ks.Key(ctx, "unknown"); err == nil {
+					t.Fatal("found an unknown key")
+				}
+			}
+			if got := stub.fetched(); got != tc.fetches {
+				t.Errorf("got %d fetches, want %d", got, tc.fetches)
+			}
+			if _, err := ks.Key(ctx, "current"); err != nil {
+				t.Errorf("lost the cached key: %v", err)
+			}
+		})
+	}
+}
+
+func TestKeySetConcurrentRefresh(t *testing.T) {
+	stub := &jwksStub{}
+	stub.publish(newSigningKey(t, "current"))
+	srv := httptest.NewServer(stub)
+	defer srv.Close()
+	ks := NewKeySet(srv.URL, srv.Client())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ks.Key(context.Background(), "unknown")
+		}()
+	}
+	wg.Wait()
+	if got := stub.fetched(); got != 1 {
+		t.Errorf("got %d fetches, want 1", got)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"github.com/zenoss/grpctest/apikey"
+	"github.com/zenoss/grpctest/auth"
+	"github.com/zenoss/grpctest/extauthz"
+	"github.com/zenoss/zenkit"
+)
+
+// newAuthzServer builds the ext_authz Check service from the extauthz and
+// apikey settings. Rules can only come from a config file, and are reloaded
+// when it changes, e.g.
+//
+//	extauthz:
+//	  rules:
+//	  - path: /MathService/Random
+//	    public: true
+//	  - path: /MathService/*
+//	    tenants: [acme]
+func newAuthzServer(settings ExtAuthzSettings, verifier *auth.Verifier, log *logrus.Entry) (*extauthz.Server, error) {
+	cfg := extauthz.Config{
+		Verifier:    verifier,
+		Rules:       settings.Rules,
+		DefaultDeny: settings.DefaultDeny,
+	}
+	if viper.GetBool(APIKeyEnabledConfig) {
+		source, err := newTokenSource()
+		if err != nil {
+			return nil, err
+		}
+		cfg.Source = source
+	}
+	return extauthz.NewServer(cfg, log.WithField("component", "extauthz")), nil
+}
+
+// newTokenSource is the keyserver's cached Auth0 exchange.
+func newTokenSource() (apikey.TokenSource, error) {
+	source := apikey.NewAuth0(apikey.Auth0Config{
+		Domain:       viper.GetString(APIKeyAuth0DomainConfig),
+		ClientID:     viper.GetString(APIKeyAuth0ClientIDConfig),
+		ClientSecret: viper.GetString(APIKeyAuth0ClientSecretConfig),
+		Audience:     viper.GetString(APIKeyAuth0AudienceConfig),
+		TokenURL:     viper.GetString(APIKeyAuth0TokenURLConfig),
+	})
+	cacheCfg := apikey.CacheConfig{
+		Size: viper.GetInt(APIKeyLRUSizeLimitConfig),
+		TTL:  time.Duration(viper.GetInt(APIKeyCacheTimeoutConfig)) * time.Minute,
+	}
+	if viper.GetBool(APIKeyRedisEnabledConfig) {
+		cacheCfg.Redis = zenkit.NewCacheId(viper.GetInt(APIKeyRedisDBIDConfig))
+	}
+	return apikey.NewCachedSource(source, cacheCfg)
+}
@@ -0,0 +1,103 @@
+// Package certs serves TLS from certificate files that may be replaced at
+// any time, the way cert-manager and Istio's SDS rotate secrets: the files
+// are watched and every new handshake uses the latest certificate and
+// client CA bundle, while established connections carry on undisturbed. It
+// also issues the certificates of a development PKI.
+package certs
+
+import (
+	"crypto/tls"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ClientAuth is how client certificates are treated.
+type ClientAuth string
+
+const (
+	// NoClientCert never asks for a client certificate.
+	NoClientCert ClientAuth = "none"
+	// VerifyClientCertIfGiven asks for a client certificate and verifies
+	// one that is sent, but lets clients without one through.
+	VerifyClientCertIfGiven ClientAuth = "request"
+	// RequireClientCert turns away clients without a verified certificate.
+	RequireClientCert ClientAuth = "require"
+)
+
+// Config names the certificate files and the TLS parameters.
+type Config struct {
+	CertFile string `mapstructure:"cert_file"`
+	KeyFile  string `mapstructure:"key_file"`
+	// ClientCAFile is a PEM bundle of the CAs client certificates must
+	// chain to. It is needed unless ClientAuth is none.
+	ClientCAFile string     `mapstructure:"client_ca_file"`
+	ClientAuth   ClientAuth `mapstructure:"client_auth"`
+	// MinVersion is the oldest TLS version accepted: 1.0, 1.1, 1.2 or 1.3.
+	// It defaults to 1.2.
+	MinVersion string `mapstructure:"min_version"`
+	// CipherSuites limits the TLS 1.2 and older cipher suites to these,
+	// named as in crypto/tls, e.g. TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256.
+	// TLS 1.3 suites aren't configurable.
+	CipherSuites []string `mapstructure:"cipher_suites"`
+}
+
+var versions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Validate checks the settings, but not the files.
+func (c Config) Validate() error {
+	if c.CertFile == "" || c.KeyFile == "" {
+		return errors.New("a certificate and key file are needed")
+	}
+	switch c.ClientAuth {
+	case "", NoClientCert:
+	case VerifyClientCertIfGiven, RequireClientCert:
+		if c.ClientCAFile == "" {
+			return errors.Errorf("client_auth %s needs a client_ca_file", c.ClientAuth)
+		}
+	default:
+		return errors.Errorf("unknown client_auth %q", c.ClientAuth)
+	}
+	if _, err := c.minVersion(); err != nil {
+		return err
+	}
+	_, err := c.cipherSuites()
+	return err
+}
+
+func (c Config) minVersion() (uint16, error) {
+	if c.MinVersion == "" {
+		return tls.VersionTLS12, nil
+	}
+	v, ok := versions[strings.TrimPrefix(c.MinVersion, "TLS")]
+	if !ok {
+		return 0, errors.Errorf("unknown min_version %q", c.MinVersion)
+	}
+	return v, nil
+}
+
+// cipherSuites looks the suites up by name. Only the suites crypto/tls
+// considers secure can be picked.
+func (c Config) cipherSuites() ([]uint16, error) {
+	if len(c.CipherSuites) == 0 {
+		return nil, nil
+	}
+	known := map[string]uint16{}
+	for _, s := range tls.CipherSuites() {
+		known[s.Name] = s.ID
+	}
+	ids := make([]uint16, 0, len(c.CipherSuites))
+	for _, name := range c.CipherSuites {
+		id, ok := known[name]
+		if !ok {
+			return nil, errors.Errorf("unknown or insecure cipher suite %q", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
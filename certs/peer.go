@@ -0,0 +1,71 @@
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// Peer is who a verified client certificate says the client is.
+type Peer struct {
+	Subject      string    `json:"subject"`
+	Issuer       string    `json:"issuer"`
+	SerialNumber string    `json:"serial_number"`
+	NotBefore    time.Time `json:"not_before"`
+	NotAfter     time.Time `json:"not_after"`
+	DNSNames     []string  `json:"dns_names,omitempty"`
+	URIs         []string  `json:"uris,omitempty"`
+	// SPIFFEID is the spiffe:// URI SAN Istio puts in workload
+	// certificates, e.g. spiffe://cluster.local/ns/default/sa/grpctest.
+	SPIFFEID string `json:"spiffe_id,omitempty"`
+}
+
+// PeerFromState is the peer of a TLS connection, or nil without a client
+// certificate. The Store's listeners only complete handshakes whose
+// certificate verified.
+func PeerFromState(state *tls.ConnectionState) *Peer {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return nil
+	}
+	c := state.PeerCertificates[0]
+	p := &Peer{
+		Subject:      c.Subject.String(),
+		Issuer:       c.Issuer.String(),
+		SerialNumber: c.SerialNumber.String(),
+		NotBefore:    c.NotBefore,
+		NotAfter:     c.NotAfter,
+		DNSNames:     c.DNSNames,
+	}
+	for _, u := range c.URIs {
+		p.URIs = append(p.URIs, u.String())
+		if strings.EqualFold(u.Scheme, "spiffe") && p.SPIFFEID == "" {
+			p.SPIFFEID = u.String()
+		}
+	}
+	return p
+}
+
+// PeerFromContext is the peer of a gRPC call.
+func PeerFromContext(ctx context.Context) *Peer {
+	pr, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	info, ok := pr.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return nil
+	}
+	return PeerFromState(&info.State)
+}
+
+// Name is the SPIFFE ID if there is one and the subject otherwise.
+func (p *Peer) Name() string {
+	if p.SPIFFEID != "" {
+		return p.SPIFFEID
+	}
+	return p.Subject
+}
@@ -0,0 +1,205 @@
+package certs
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Usage is what an issued certificate may be used for.
+type Usage int
+
+const (
+	// ServerUsage certificates are for listeners.
+	ServerUsage Usage = 1 << iota
+	// ClientUsage certificates are for client authentication.
+	ClientUsage
+)
+
+// Request describes a certificate to issue.
+type Request struct {
+	CommonName string
+	// Hosts become DNS or IP address SANs.
+	Hosts []string
+	// URIs become URI SANs, e.g. a SPIFFE ID from SPIFFEID.
+	URIs     []string
+	Usage    Usage
+	Validity time.Duration
+}
+
+// Authority is a CA that issues development certificates. Its keys and
+// those it issues are ECDSA P-256.
+type Authority struct {
+	Cert *x509.Certificate
+	Key  crypto.Signer
+}
+
+// NewAuthority creates a self-signed CA.
+func NewAuthority(name string, validity time.Duration) (*Authority, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to generate CA key")
+	}
+	tmpl, err := template(name, validity)
+	if err != nil {
+		return nil, err
+	}
+	tmpl.IsCA = true
+	tmpl.BasicConstraintsValid = true
+	tmpl.MaxPathLenZero = true
+	tmpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to create CA certificate")
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse CA certificate")
+	}
+	return &Authority{Cert: cert, Key: key}, nil
+}
+
+// LoadAuthority reads a CA written by WriteFiles.
+func LoadAuthority(certFile, keyFile string) (*Authority, error) {
+	pair, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to load CA")
+	}
+	cert, err := x509.ParseCertificate(pair.Certificate[0])
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse CA certificate")
+	}
+	if !cert.IsCA {
+		return nil, errors.Errorf("%s is not a CA certificate", certFile)
+	}
+	key, ok := pair.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, errors.Errorf("%s can't sign", keyFile)
+	}
+	return &Authority{Cert: cert, Key: key}, nil
+}
+
+// Issue creates a key and a certificate for req, signed by the CA. The
+// certificate doesn't outlive the CA.
+func (ca *Authority) Issue(req Request) (*x509.Certificate, crypto.Signer, error) {
+	if req.Usage == 0 {
+		return nil, nil, errors.New("a certificate needs a usage")
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "unable to generate key")
+	}
+	tmpl, err := template(req.CommonName, req.Validity)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tmpl.NotAfter.After(ca.Cert.NotAfter) {
+		tmpl.NotAfter = ca.Cert.NotAfter
+	}
+	tmpl.KeyUsage = x509.KeyUsageDigitalSignature
+	if req.Usage&ServerUsage != 0 {
+		tmpl.ExtKeyUsage = append(tmpl.ExtKeyUsage, x509.ExtKeyUsageServerAuth)
+	}
+	if req.Usage&ClientUsage != 0 {
+		tmpl.ExtKeyUsage = append(tmpl.ExtKeyUsage, x509.ExtKeyUsageClientAuth)
+	}
+	for _, h := range req.Hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else {
+			tmpl.DNSNames = append(tmpl.DNSNames, h)
+		}
+	}
+	for _, s := range req.URIs {
+		u, err := url.Parse(s)
+		if err != nil || u.Scheme == "" {
+			return nil, nil, errors.Errorf("invalid URI SAN %q", s)
+		}
+		tmpl.URIs = append(tmpl.URIs, u)
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.Cert, key.Public(), ca.Key)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "unable to create certificate")
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "unable to parse certificate")
+	}
+	return cert, key, nil
+}
+
+// SPIFFEID is the identity Istio gives a Kubernetes service account.
+func SPIFFEID(trustDomain, namespace, serviceAccount string) string {
+	u := url.URL{
+		Scheme: "spiffe",
+		Host:   trustDomain,
+		Path:   "/ns/" + namespace + "/sa/" + serviceAccount,
+	}
+	return u.String()
+}
+
+func template(name string, validity time.Duration) (*x509.Certificate, error) {
+	if validity <= 0 {
+		return nil, errors.New("validity must be positive")
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to generate serial number")
+	}
+	// Backdated a little for clocks that are behind.
+	now := time.Now().Add(-5 * time.Minute)
+	return &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name, Organization: []string{"grpctest"}},
+		NotBefore:    now,
+		NotAfter:     now.Add(validity),
+	}, nil
+}
+
+// WriteFiles writes cert and key as PEM files, the key readable by its
+// owner only. Each file is replaced in one rename, so a Store watching them
+// never reads half a file.
+func WriteFiles(certFile, keyFile string, cert *x509.Certificate, key crypto.Signer) error {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return errors.Wrap(err, "unable to encode key")
+	}
+	if err := writeFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		return err
+	}
+	return writeFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}), 0644)
+}
+
+func writeFile(path string, data []byte, perm os.FileMode) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path))
+	if err != nil {
+		return errors.Wrapf(err, "unable to write %s", path)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return errors.Wrapf(err, "unable to write %s", path)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return errors.Wrapf(err, "unable to write %s", path)
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrapf(err, "unable to write %s", path)
+	}
+	return errors.Wrapf(os.Rename(tmp.Name(), path), "unable to write %s", path)
+}
@@ -0,0 +1,202 @@
+package certs
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Store holds the current certificate and client CA bundle.
+type Store struct {
+	cfg Config
+	log *logrus.Entry
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+	cas  *x509.CertPool
+}
+
+// New loads the files of cfg.
+func New(cfg Config, log *logrus.Entry) (*Store, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	s := &Store{cfg: cfg, log: log}
+	if err := s.Load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Load reads the files again. On error the previous certificate and CAs
+// stay in use.
+func (s *Store) Load() error {
+	cert, err := tls.LoadX509KeyPair(s.cfg.CertFile, s.cfg.KeyFile)
+	if err != nil {
+		return errors.Wrap(err, "unable to load certificate")
+	}
+	cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return errors.Wrap(err, "unable to parse certificate")
+	}
+	var cas *x509.CertPool
+	if s.cfg.ClientCAFile != "" {
+		pem, err := ioutil.ReadFile(s.cfg.ClientCAFile)
+		if err != nil {
+			return errors.Wrap(err, "unable to read client CAs")
+		}
+		cas = x509.NewCertPool()
+		if !cas.AppendCertsFromPEM(pem) {
+			return errors.Errorf("no certificates in %s", s.cfg.ClientCAFile)
+		}
+	}
+	s.mu.Lock()
+	s.cert, s.cas = &cert, cas
+	s.mu.Unlock()
+	return nil
+}
+
+// Certificate is the certificate in use.
+func (s *Store) Certificate() *tls.Certificate {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cert
+}
+
+// ServerConfig is the TLS config for a listener. It always presents the
+// current certificate and verifies clients against the current CAs, so it
+// never needs replacing.
+func (s *Store) ServerConfig() *tls.Config {
+	// Both were checked by Validate.
+	minVersion, _ := s.cfg.minVersion()
+	suites, _ := s.cfg.cipherSuites()
+	cfg := &tls.Config{
+		MinVersion:   minVersion,
+		CipherSuites: suites,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return s.Certificate(), nil
+		},
+	}
+	// crypto/tls can only verify against a fixed ClientCAs pool, so the
+	// certificate is requested and verified here instead, against whatever
+	// bundle is current.
+	switch s.cfg.ClientAuth {
+	case VerifyClientCertIfGiven:
+		cfg.ClientAuth = tls.RequestClientCert
+		cfg.VerifyPeerCertificate = s.verifyClient
+	case RequireClientCert:
+		cfg.ClientAuth = tls.RequireAnyClientCert
+		cfg.VerifyPeerCertificate = s.verifyClient
+	}
+	return cfg
+}
+
+// ClientConfig is the TLS config for calling our own listener, which
+// presents our certificate as the client certificate and trusts whatever
+// answers. Only use it to dial a loopback address.
+func (s *Store) ClientConfig() *tls.Config {
+	return &tls.Config{
+		InsecureSkipVerify: true,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return s.Certificate(), nil
+		},
+	}
+}
+
+func (s *Store) verifyClient(raw [][]byte, _ [][]*x509.Certificate) error {
+	if len(raw) == 0 {
+		// tls.RequireAnyClientCert has already turned these away.
+		return nil
+	}
+	s.mu.RLock()
+	own, cas := s.cert, s.cas
+	s.mu.RUnlock()
+	// Our own certificate is what ClientConfig presents.
+	if bytes.Equal(raw[0], own.Certificate[0]) {
+		return nil
+	}
+	certs := make([]*x509.Certificate, len(raw))
+	for i, b := range raw {
+		c, err := x509.ParseCertificate(b)
+		if err != nil {
+			return errors.Wrap(err, "unable to parse client certificate")
+		}
+		certs[i] = c
+	}
+	opts := x509.VerifyOptions{
+		Roots:         cas,
+		Intermediates: x509.NewCertPool(),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	for _, c := range certs[1:] {
+		opts.Intermediates.AddCert(c)
+	}
+	if _, err := certs[0].Verify(opts); err != nil {
+		return errors.Wrap(err, "client certificate not trusted")
+	}
+	return nil
+}
+
+// Watch reloads the files whenever they change, until ctx is done. Their
+// directories are watched rather than the files, so replaced files and
+// Kubernetes Secret symlink swaps are seen too. A certificate and key are
+// often written one after the other; a reload that finds them mismatched
+// is logged and the next change tries again.
+func (s *Store) Watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.Wrap(err, "unable to create certificate watcher")
+	}
+	files := map[string]string{}
+	for _, f := range []string{s.cfg.CertFile, s.cfg.KeyFile, s.cfg.ClientCAFile} {
+		if f == "" {
+			continue
+		}
+		f = filepath.Clean(f)
+		files[f], _ = filepath.EvalSymlinks(f)
+		if err := watcher.Add(filepath.Dir(f)); err != nil {
+			watcher.Close()
+			return errors.Wrap(err, "unable to watch certificate directory")
+		}
+	}
+
+	go func() {
+		defer watcher.Close()
+		log := s.log.WithField("cert_file", s.cfg.CertFile)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err := <-watcher.Errors:
+				log.WithError(err).Warn("certificate watcher error")
+			case event := <-watcher.Events:
+				changed := false
+				for f, realPath := range files {
+					current, _ := filepath.EvalSymlinks(f)
+					if filepath.Clean(event.Name) == f && event.Op&(fsnotify.Write|fsnotify.Create) != 0 ||
+						current != "" && current != realPath {
+						changed = true
+					}
+					files[f] = current
+				}
+				if !changed {
+					continue
+				}
+				if err := s.Load(); err != nil {
+					log.WithError(err).Warn("unable to reload certificate; keeping the previous one")
+					continue
+				}
+				log.WithField("not_after", s.Certificate().Leaf.NotAfter).Info("reloaded certificate")
+			}
+		}
+	}()
+	return nil
+}
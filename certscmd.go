@@ -0,0 +1,169 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+	"github.com/zenoss/grpctest/certs"
+)
+
+const certsUsage = `Usage: grpctest certs [ca|server|client] [flags]
+
+Creates a development PKI for the tls settings. Without a command it
+creates the CA, unless there already is one, and issues a server and a
+client certificate from it:
+
+  grpctest certs
+  GRPCTEST_TLS_ENABLED=true GRPCTEST_TLS_CERT_FILE=pki/server.crt \
+  GRPCTEST_TLS_KEY_FILE=pki/server.key GRPCTEST_TLS_CLIENT_CA_FILE=pki/ca.crt \
+  GRPCTEST_TLS_CLIENT_AUTH=require grpctest
+
+Certificates carry the SPIFFE ID Istio would give the workload's service
+account. Issuing again replaces a certificate in place, which a running
+server picks up.
+`
+
+// certsOptions are the flags of grpctest certs.
+type certsOptions struct {
+	dir         string
+	name        string
+	hosts       []string
+	spiffeID    string
+	trustDomain string
+	namespace   string
+	validity    time.Duration
+	caValidity  time.Duration
+	force       bool
+}
+
+// runCerts is grpctest certs.
+func runCerts(args []string) error {
+	var opts certsOptions
+	flags := pflag.NewFlagSet("certs", pflag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(os.Stderr, certsUsage, "\nFlags:\n", flags.FlagUsages())
+	}
+	flags.StringVar(&opts.dir, "dir", "pki", "directory for the PEM files")
+	flags.StringVar(&opts.name, "name", "", "certificate name, and the file names of server and client certificates (default server, client or the CA's name)")
+	flags.StringSliceVar(&opts.hosts, "hosts", []string{"localhost", "127.0.0.1", "::1"}, "DNS names and IP addresses of the server")
+	flags.StringVar(&opts.spiffeID, "spiffe-id", "", "URI SAN (default spiffe://<trust-domain>/ns/<namespace>/sa/<grpctest or the client name>)")
+	flags.StringVar(&opts.trustDomain, "trust-domain", "cluster.local", "SPIFFE trust domain")
+	flags.StringVar(&opts.namespace, "namespace", "default", "Kubernetes namespace of the SPIFFE ID")
+	flags.DurationVar(&opts.validity, "validity", 30*24*time.Hour, "how long server and client certificates are valid")
+	flags.DurationVar(&opts.caValidity, "ca-validity", 10*365*24*time.Hour, "how long the CA is valid")
+	flags.BoolVar(&opts.force, "force", false, "replace an existing CA, which invalidates everything it issued")
+	if err := flags.Parse(args); err != nil {
+		if err == pflag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if flags.NArg() > 1 {
+		flags.Usage()
+		return errors.New("one command at a time")
+	}
+	cmd := flags.Arg(0)
+	switch cmd {
+	case "", "ca", "server", "client":
+	default:
+		flags.Usage()
+		return errors.Errorf("unknown certs command %q", cmd)
+	}
+	if err := os.MkdirAll(opts.dir, 0755); err != nil {
+		return errors.Wrap(err, "unable to create the certificate directory")
+	}
+
+	switch cmd {
+	case "":
+		if _, err := opts.authority(false); err != nil {
+			return err
+		}
+		server, client := opts, opts
+		server.name, client.name = "server", "client"
+		if err := server.issue(certs.ServerUsage); err != nil {
+			return err
+		}
+		return client.issue(certs.ClientUsage)
+	case "ca":
+		_, err := opts.authority(true)
+		return err
+	case "server":
+		return opts.issue(certs.ServerUsage)
+	default:
+		return opts.issue(certs.ClientUsage)
+	}
+}
+
+func (o certsOptions) caFiles() (string, string) {
+	return filepath.Join(o.dir, "ca.crt"), filepath.Join(o.dir, "ca.key")
+}
+
+// authority creates the CA. An existing one is kept, unless create is set,
+// which then needs force to replace it.
+func (o certsOptions) authority(create bool) (*certs.Authority, error) {
+	certFile, keyFile := o.caFiles()
+	if _, err := os.Stat(certFile); err == nil && !o.force {
+		if create {
+			return nil, errors.Errorf("%s exists; use --force to replace it", certFile)
+		}
+		return certs.LoadAuthority(certFile, keyFile)
+	}
+	name := o.name
+	if name == "" {
+		name = "grpctest development CA"
+	}
+	ca, err := certs.NewAuthority(name, o.caValidity)
+	if err != nil {
+		return nil, err
+	}
+	if err := certs.WriteFiles(certFile, keyFile, ca.Cert, ca.Key); err != nil {
+		return nil, err
+	}
+	fmt.Printf("Wrote %s, valid until %s\n", certFile, ca.Cert.NotAfter.Format(time.RFC3339))
+	return ca, nil
+}
+
+func (o certsOptions) issue(usage certs.Usage) error {
+	ca, err := certs.LoadAuthority(o.caFiles())
+	if err != nil {
+		return errors.Wrap(err, "run grpctest certs ca first")
+	}
+	req := certs.Request{
+		CommonName: o.name,
+		URIs:       []string{o.spiffeID},
+		Usage:      usage,
+		Validity:   o.validity,
+	}
+	account := o.name
+	if usage == certs.ServerUsage {
+		if req.CommonName == "" {
+			req.CommonName = "server"
+		}
+		req.Hosts = o.hosts
+		account = serviceName
+	} else if req.CommonName == "" {
+		req.CommonName = "client"
+	}
+	if o.spiffeID == "" {
+		if account == "" {
+			account = req.CommonName
+		}
+		req.URIs = []string{certs.SPIFFEID(o.trustDomain, o.namespace, account)}
+	}
+
+	cert, key, err := ca.Issue(req)
+	if err != nil {
+		return err
+	}
+	certFile := filepath.Join(o.dir, req.CommonName+".crt")
+	keyFile := filepath.Join(o.dir, req.CommonName+".key")
+	if err := certs.WriteFiles(certFile, keyFile, cert, key); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %s for %s, valid until %s\n", certFile, cert.URIs[0], cert.NotAfter.Format(time.RFC3339))
+	return nil
+}
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/spf13/pflag"
+	pb "github.com/zenoss/grpctest/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// command is one method. setup declares its flags and returns what runs
+// once they are parsed.
+type command struct {
+	usage string
+	setup func(flags *pflag.FlagSet) runFunc
+}
+
+type runFunc func(ctx context.Context, client clients, out *printer, args []string) error
+
+// clients are the services a command may call; MathService's methods are
+// promoted.
+type clients struct {
+	pb.MathServiceClient
+	introspection pb.IntrospectionClient
+}
+
+var commands = map[string]command{
+	"square": {"<int32>", func(*pflag.FlagSet) runFunc {
+		return func(ctx context.Context, client clients, out *printer, args []string) error {
+			v, err := oneValue(args, 32)
+			if err != nil {
+				return err
+			}
+			return unary(out, func(opts ...grpc.CallOption) (proto.Message, error) {
+				return client.Square(ctx, &pb.Request{Value: int32(v)}, opts...)
+			})
+		}
+	}},
+	"square-int64": {"<int64>", func(*pflag.FlagSet) runFunc {
+		return func(ctx context.Context, client clients, out *printer, args []string) error {
+			v, err := oneValue(args, 64)
+			if err != nil {
+				return err
+			}
+			return unary(out, func(opts ...grpc.CallOption) (proto.Message, error) {
+				return client.SquareInt64(ctx, &pb.Int64Request{Value: v}, opts...)
+			})
+		}
+	}},
+	"square-big": {"<integer of any size>", func(*pflag.FlagSet) runFunc {
+		return func(ctx context.Context, client clients, out *printer, args []string) error {
+			if len(args) != 1 {
+				return usagef("expected one value")
+			}
+			return unary(out, func(opts ...grpc.CallOption) (proto.Message, error) {
+				return client.SquareBig(ctx, &pb.BigInteger{Value: args[0]}, opts...)
+			})
+		}
+	}},
+	"random": {"[--min --max --count --distribution --seed ...]", func(flags *pflag.FlagSet) runFunc {
+		request := randomFlags(flags, true)
+		return func(ctx context.Context, client clients, out *printer, args []string) error {
+			in, err := request(args)
+			if err != nil {
+				return err
+			}
+			return unary(out, func(opts ...grpc.CallOption) (proto.Message, error) {
+				return client.Random(ctx, in, opts...)
+			})
+		}
+	}},
+	"random-stream": {"[--count --rate] [random flags]", func(flags *pflag.FlagSet) runFunc {
+		count := flags.Int32("count", 0, "values to stream; 0 streams until the deadline or ^C")
+		rate := flags.Float64("rate", 0, "values per second; 0 sends them as fast as they are read")
+		request := randomFlags(flags, false)
+		return func(ctx context.Context, client clients, out *printer, args []string) error {
+			random, err := request(args)
+			if err != nil {
+				return err
+			}
+			stream, err := client.RandomStream(ctx, &pb.RandomStreamRequest{Count: *count, Rate: *rate, Random: random})
+			if err != nil {
+				return err
+			}
+			return receive(out, stream, func() (proto.Message, error) { return stream.Recv() })
+		}
+	}},
+	"sum": {"<int32>... (default read from stdin)", func(*pflag.FlagSet) runFunc {
+		return func(ctx context.Context, client clients, out *printer, args []string) error {
+			values, err := values(args)
+			if err != nil {
+				return err
+			}
+			stream, err := client.Sum(ctx)
+			if err != nil {
+				return err
+			}
+			for _, v := range values {
+				if err := stream.Send(&pb.Request{Value: v}); err != nil {
+					// The status comes with CloseAndRecv.
+					break
+				}
+			}
+			res, err := stream.CloseAndRecv()
+			header, _ := stream.Header()
+			out.metadata("header", header)
+			out.metadata("trailer", stream.Trailer())
+			if err != nil {
+				return err
+			}
+			out.message(res)
+			return nil
+		}
+	}},
+	"square-stream": {"<int32>... (default read from stdin)", func(*pflag.FlagSet) runFunc {
+		return func(ctx context.Context, client clients, out *printer, args []string) error {
+			values, err := values(args)
+			if err != nil {
+				return err
+			}
+			stream, err := client.SquareStream(ctx)
+			if err != nil {
+				return err
+			}
+			go func() {
+				for _, v := range values {
+					if err := stream.Send(&pb.Request{Value: v}); err != nil {
+						return
+					}
+				}
+				stream.CloseSend()
+			}()
+			return receive(out, stream, func() (proto.Message, error) { return stream.Recv() })
+		}
+	}},
+	"whoami": {"", func(*pflag.FlagSet) runFunc {
+		return func(ctx context.Context, client clients, out *printer, args []string) error {
+			if len(args) != 0 {
+				return usagef("expected no values")
+			}
+			return unary(out, func(opts ...grpc.CallOption) (proto.Message, error) {
+				return client.introspection.WhoAmI(ctx, &pb.WhoAmIRequest{}, opts...)
+			})
+		}
+	}},
+	"echo": {"<message>", func(*pflag.FlagSet) runFunc {
+		return func(ctx context.Context, client clients, out *printer, args []string) error {
+			return unary(out, func(opts ...grpc.CallOption) (proto.Message, error) {
+				return client.introspection.Echo(ctx, &pb.EchoRequest{Message: strings.Join(args, " ")}, opts...)
+			})
+		}
+	}},
+}
+
+// unary makes a call and prints its result and metadata.
+func unary(out *printer, call func(...grpc.CallOption) (proto.Message, error)) error {
+	var header, trailer metadata.MD
+	res, err := call(grpc.Header(&header), grpc.Trailer(&trailer))
+	out.metadata("header", header)
+	out.metadata("trailer", trailer)
+	if err != nil {
+		return err
+	}
+	out.message(res)
+	return nil
+}
+
+// receive prints a server stream as it arrives.
+func receive(out *printer, stream grpc.ClientStream, recv func() (proto.Message, error)) error {
+	headerShown := false
+	for {
+		res, err := recv()
+		if !headerShown {
+			header, _ := stream.Header()
+			out.metadata("header", header)
+			headerShown = true
+		}
+		if err == io.EOF {
+			out.metadata("trailer", stream.Trailer())
+			return nil
+		}
+		if err != nil {
+			out.metadata("trailer", stream.Trailer())
+			return err
+		}
+		out.message(res)
+	}
+}
+
+// randomFlags declares the RandomRequest flags; --count only when the
+// request's count is used.
+func randomFlags(flags *pflag.FlagSet, withCount bool) func(args []string) (*pb.RandomRequest, error) {
+	min := flags.Int32("min", 0, "smallest value (default 0)")
+	max := flags.Int32("max", 0, "largest value (default 2147483647)")
+	count := new(int32)
+	if withCount {
+		flags.Int32Var(count, "count", 1, "number of values")
+	}
+	distribution := flags.String("distribution", "uniform", "uniform, normal or exponential")
+	mean := flags.Float64("mean", 0, "mean of the normal and exponential distributions")
+	stddev := flags.Float64("stddev", 0, "standard deviation of the normal distribution")
+	seed := flags.Int64("seed", 0, "seed, to draw the same values again")
+	source := flags.String("source", "math", "math or crypto")
+	parity := flags.String("parity", "any", "any, even or odd")
+
+	return func(args []string) (*pb.RandomRequest, error) {
+		if len(args) != 0 {
+			return nil, usagef("unexpected arguments %v", args)
+		}
+		in := &pb.RandomRequest{Count: *count}
+		if flags.Changed("min") {
+			in.Min = &wrappers.Int32Value{Value: *min}
+		}
+		if flags.Changed("max") {
+			in.Max = &wrappers.Int32Value{Value: *max}
+		}
+		if flags.Changed("mean") {
+			in.Mean = &wrappers.DoubleValue{Value: *mean}
+		}
+		if flags.Changed("stddev") {
+			in.Stddev = &wrappers.DoubleValue{Value: *stddev}
+		}
+		if flags.Changed("seed") {
+			in.Seed = &wrappers.Int64Value{Value: *seed}
+		}
+		d, ok := pb.RandomRequest_Distribution_value[strings.ToUpper(*distribution)]
+		if !ok {
+			return nil, usagef("unknown distribution %q", *distribution)
+		}
+		in.Distribution = pb.RandomRequest_Distribution(d)
+		s, ok := pb.RandomRequest_Source_value[strings.ToUpper(*source)]
+		if !ok {
+			return nil, usagef("unknown source %q", *source)
+		}
+		in.Source = pb.RandomRequest_Source(s)
+		p, ok := pb.RandomRequest_Parity_value[strings.ToUpper(*parity)]
+		if !ok {
+			return nil, usagef("unknown parity %q", *parity)
+		}
+		in.Parity = pb.RandomRequest_Parity(p)
+		return in, nil
+	}
+}
+
+func oneValue(args []string, bits int) (int64, error) {
+	if len(args) != 1 {
+		return 0, usagef("expected one value")
+	}
+	v, err := strconv.ParseInt(args[0], 10, bits)
+	if err != nil {
+		return 0, usagef("%q is not an int%d", args[0], bits)
+	}
+	return v, nil
+}
+
+// values are the int32 arguments, or whitespace separated ones from stdin
+// when there are none.
+func values(args []string) ([]int32, error) {
+	if len(args) == 0 {
+		scanner := bufio.NewScanner(os.Stdin)
+		scanner.Split(bufio.ScanWords)
+		for scanner.Scan() {
+			args = append(args, scanner.Text())
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, usagef("unable to read stdin: %v", err)
+		}
+	}
+	values := make([]int32, len(args))
+	for i, a := range args {
+		v, err := strconv.ParseInt(a, 10, 32)
+		if err != nil {
+			return nil, usagef("%q is not an int32", a)
+		}
+		values[i] = int32(v)
+	}
+	return values, nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+)
+
+// dial connects lazily, so a server that isn't there fails the call with
+// UNAVAILABLE.
+func dial(opts options) (*grpc.ClientConn, error) {
+	mode := opts.tls
+	if opts.plaintext {
+		mode = "off"
+	}
+	if mode == "" {
+		mode = defaultTLS(opts)
+	}
+	switch mode {
+	case "off":
+		return grpc.Dial(opts.addr, grpc.WithInsecure())
+	case "verify", "skip-verify":
+	default:
+		return nil, usagef("unknown TLS mode %q", opts.tls)
+	}
+
+	cfg := &tls.Config{
+		ServerName:         opts.serverName,
+		InsecureSkipVerify: mode == "skip-verify",
+	}
+	if opts.caFile != "" {
+		pem, err := ioutil.ReadFile(opts.caFile)
+		if err != nil {
+			return nil, usageError{errors.Wrap(err, "unable to read the CAs")}
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, usagef("no certificates in %s", opts.caFile)
+		}
+	}
+	if opts.certFile != "" || opts.keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(opts.certFile, opts.keyFile)
+		if err != nil {
+			return nil, usageError{errors.Wrap(err, "unable to load the client certificate")}
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return grpc.Dial(opts.addr, grpc.WithTransportCredentials(credentials.NewTLS(cfg)))
+}
+
+// defaultTLS is the TLS mode when none is given. A server on this host,
+// like the one `go run .` starts, is plaintext unless certificates say
+// otherwise; anything further away is expected to be behind TLS.
+func defaultTLS(opts options) string {
+	if opts.caFile != "" || opts.certFile != "" {
+		return "verify"
+	}
+	host, _, err := net.SplitHostPort(opts.addr)
+	if err != nil {
+		host = opts.addr
+	}
+	if ip := net.ParseIP(host); host == "localhost" || ip != nil && ip.IsLoopback() {
+		return "off"
+	}
+	return "verify"
+}
+
+// outgoingContext carries the credentials and headers of opts.
+func outgoingContext(ctx context.Context, opts options) (context.Context, error) {
+	md := metadata.MD{}
+	token := opts.token
+	if opts.tokenFile != "" {
+		b, err := ioutil.ReadFile(opts.tokenFile)
+		if err != nil {
+			return nil, usageError{errors.Wrap(err, "unable to read the token")}
+		}
+		token = strings.TrimSpace(string(b))
+	}
+	if token != "" {
+		md.Set("authorization", "Bearer "+token)
+	}
+	if opts.apiKey != "" {
+		md.Set("z-api-key", opts.apiKey)
+	}
+	for _, h := range opts.headers {
+		name, value, err := parseHeader(h)
+		if err != nil {
+			return nil, err
+		}
+		md.Append(name, value)
+	}
+	return metadata.NewOutgoingContext(ctx, md), nil
+}
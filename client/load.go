@@ -0,0 +1,397 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+	pb "github.com/zenoss/grpctest/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// The --rps bounds: one call every 31 years or so, and one a nanosecond.
+const (
+	minRPS = 1e-9
+	maxRPS = 1e9
+)
+
+// loadOptions are the flags of the load command.
+type loadOptions struct {
+	concurrency int
+	connections int
+	rps         float64
+	total       int64
+	duration    time.Duration
+	interval    time.Duration
+	methods     string
+	tokensFile  string
+	httpURL     string
+}
+
+// loadMethod is a method the load command can call, over gRPC and over
+// its transcoded HTTP endpoint.
+type loadMethod struct {
+	grpc func(ctx context.Context, client pb.MathServiceClient, v int32) error
+	http func(base string, v int32) (*http.Request, error)
+}
+
+var loadMethods = map[string]loadMethod{
+	"square": {
+		grpc: func(ctx context.Context, client pb.MathServiceClient, v int32) error {
+			_, err := client.Square(ctx, &pb.Request{Value: v})
+			return err
+		},
+		http: func(base string, v int32) (*http.Request, error) {
+			return http.NewRequest(http.MethodPost, base+"/math/square", strings.NewReader(fmt.Sprint(v)))
+		},
+	},
+	"square-int64": {
+		grpc: func(ctx context.Context, client pb.MathServiceClient, v int32) error {
+			_, err := client.SquareInt64(ctx, &pb.Int64Request{Value: int64(v)})
+			return err
+		},
+		http: func(base string, v int32) (*http.Request, error) {
+			return http.NewRequest(http.MethodPost, base+"/math/square/int64", strings.NewReader(fmt.Sprintf(`"%d"`, v)))
+		},
+	},
+	"square-big": {
+		grpc: func(ctx context.Context, client pb.MathServiceClient, v int32) error {
+			_, err := client.SquareBig(ctx, &pb.BigInteger{Value: fmt.Sprint(v)})
+			return err
+		},
+		http: func(base string, v int32) (*http.Request, error) {
+			return http.NewRequest(http.MethodPost, base+"/math/square/big", strings.NewReader(fmt.Sprintf(`"%d"`, v)))
+		},
+	},
+	"random": {
+		grpc: func(ctx context.Context, client pb.MathServiceClient, v int32) error {
+			_, err := client.Random(ctx, &pb.RandomRequest{})
+			return err
+		},
+		http: func(base string, v int32) (*http.Request, error) {
+			return http.NewRequest(http.MethodGet, base+"/math/random", nil)
+		},
+	},
+}
+
+// weighted picks among methods or tokens in proportion to their weights.
+type weighted struct {
+	names  []string
+	totals []int
+}
+
+func (w *weighted) add(name string, weight int) {
+	total := weight
+	if n := len(w.totals); n > 0 {
+		total += w.totals[n-1]
+	}
+	w.names = append(w.names, name)
+	w.totals = append(w.totals, total)
+}
+
+func (w *weighted) pick(rng *rand.Rand) int {
+	n := rng.Intn(w.totals[len(w.totals)-1])
+	for i, t := range w.totals {
+		if n < t {
+			return i
+		}
+	}
+	return len(w.totals) - 1
+}
+
+// parseMethods reads "square=3,random" into weights, 1 when left out.
+func parseMethods(s string) (*weighted, error) {
+	w := &weighted{}
+	for _, part := range strings.Split(s, ",") {
+		name, weight := strings.TrimSpace(part), 1
+		if i := strings.Index(name, "="); i >= 0 {
+			n, err := strconv.Atoi(name[i+1:])
+			if err != nil || n < 1 {
+				return nil, usagef("weight of %q is not a positive integer", part)
+			}
+			name, weight = name[:i], n
+		}
+		if _, ok := loadMethods[name]; !ok {
+			return nil, usagef("load can't call %q", name)
+		}
+		w.add(name, weight)
+	}
+	return w, nil
+}
+
+// tenantToken is a line of the tokens file: a token, optionally preceded
+// by a name to report it under, e.g. the tenant it belongs to.
+type tenantToken struct {
+	name, token string
+}
+
+func readTokens(path string) ([]tenantToken, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, usageError{errors.Wrap(err, "unable to read the tokens")}
+	}
+	defer f.Close()
+	var tokens []tenantToken
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		switch {
+		case len(fields) == 0 || strings.HasPrefix(fields[0], "#"):
+		case len(fields) == 1:
+			tokens = append(tokens, tenantToken{fmt.Sprintf("token%d", len(tokens)+1), fields[0]})
+		default:
+			tokens = append(tokens, tenantToken{fields[0], fields[1]})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, usageError{errors.Wrap(err, "unable to read the tokens")}
+	}
+	if len(tokens) == 0 {
+		return nil, usagef("no tokens in %s", path)
+	}
+	return tokens, nil
+}
+
+// sample is the outcome of one call.
+type sample struct {
+	method, tenant, code string
+	latency              time.Duration
+	done                 time.Duration
+}
+
+// runLoad is the load command. It returns once the duration is up, the
+// total has been sent or ^C is pressed, and reports what happened.
+func runLoad(opts options, out *printer, args []string) error {
+	var lo loadOptions
+	flags := pflag.NewFlagSet("load", pflag.ContinueOnError)
+	flags.IntVarP(&lo.concurrency, "concurrency", "c", 10, "calls in flight at once")
+	flags.IntVar(&lo.connections, "connections", 1, "connections to spread the calls over")
+	flags.Float64Var(&lo.rps, "rps", 0, "target calls per second over all workers; 0 is as fast as they go")
+	flags.Int64VarP(&lo.total, "total", "n", 0, "stop after this many calls; 0 for no limit")
+	flags.DurationVarP(&lo.duration, "duration", "d", 10*time.Second, "stop after this long; 0 for no limit (default 10s unless --total is set)")
+	flags.DurationVar(&lo.interval, "interval", time.Second, "width of the throughput timeline's buckets")
+	flags.StringVar(&lo.methods, "methods", "square", `methods to call and their weights, e.g. "square=3,random"; one of `+strings.Join(loadMethodNames(), ", "))
+	flags.StringVar(&lo.tokensFile, "tokens-file", "", `file of bearer tokens, one per line as "[tenant] token", used in turn at random`)
+	flags.StringVar(&lo.httpURL, "http", "", "call the transcoded endpoints under this URL, e.g. http://localhost:8081, instead of gRPC")
+	if err := flags.Parse(args); err != nil {
+		if err == pflag.ErrHelp {
+			return nil
+		}
+		return usageError{err}
+	}
+	if flags.NArg() > 0 {
+		return usagef("unexpected arguments %v", flags.Args())
+	}
+	if lo.total > 0 && !flags.Changed("duration") {
+		lo.duration = 0
+	}
+	if lo.concurrency < 1 || lo.connections < 1 || lo.interval <= 0 {
+		return usagef("concurrency, connections and interval must be positive")
+	}
+	// The rate sets the ticker's interval, which must be at least a
+	// nanosecond and fit in a time.Duration.
+	if !(lo.rps == 0 || lo.rps >= minRPS && lo.rps <= maxRPS) {
+		return usagef("rps must be 0 or between %g and %g", minRPS, maxRPS)
+	}
+	if out.format == "prototext" {
+		return usagef("load reports as text or json")
+	}
+	methods, err := parseMethods(lo.methods)
+	if err != nil {
+		return err
+	}
+	var tokens []tenantToken
+	if lo.tokensFile != "" {
+		if tokens, err = readTokens(lo.tokensFile); err != nil {
+			return err
+		}
+	}
+
+	ctx, err := outgoingContext(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+	if lo.duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, lo.duration)
+		defer cancel()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+	go func() {
+		select {
+		case <-interrupt:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	call, closeAll, err := newCaller(opts, lo)
+	if err != nil {
+		return err
+	}
+	defer closeAll()
+
+	// With a target rate the workers take turns from a ticker.
+	var ticks <-chan time.Time
+	if lo.rps > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / lo.rps))
+		defer ticker.Stop()
+		ticks = ticker.C
+	}
+
+	start := time.Now()
+	samples := make(chan sample, lo.concurrency)
+	var sent int64
+	var wg sync.WaitGroup
+	for i := 0; i < lo.concurrency; i++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(time.Now().UnixNano() + int64(worker)))
+			for {
+				if ticks != nil {
+					select {
+					case <-ticks:
+					case <-ctx.Done():
+						return
+					}
+				}
+				if ctx.Err() != nil || lo.total > 0 && atomic.AddInt64(&sent, 1) > lo.total {
+					return
+				}
+				s := sample{method: methods.names[methods.pick(rng)]}
+				callCtx := ctx
+				if len(tokens) > 0 {
+					t := tokens[rng.Intn(len(tokens))]
+					s.tenant = t.name
+					callCtx = withToken(ctx, t.token)
+				}
+				began := time.Now()
+				s.code = call(callCtx, worker, s.method, int32(rng.Intn(1000)+1))
+				s.latency = time.Since(began)
+				s.done = time.Since(start)
+				if ctx.Err() != nil {
+					// Cut short by the end of the run, not the server.
+					return
+				}
+				samples <- s
+			}
+		}(i)
+	}
+	go func() {
+		wg.Wait()
+		close(samples)
+	}()
+
+	r := newReport(lo.interval)
+	for s := range samples {
+		r.add(s)
+	}
+	r.finish(time.Since(start))
+	return r.print(out)
+}
+
+func loadMethodNames() []string {
+	names := make([]string, 0, len(loadMethods))
+	for name := range loadMethods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// withToken replaces the bearer token of ctx.
+func withToken(ctx context.Context, token string) context.Context {
+	md, _ := metadata.FromOutgoingContext(ctx)
+	md = md.Copy()
+	md.Set("authorization", "Bearer "+token)
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// newCaller opens the connections and returns what makes one call on a
+// worker's connection, returning the status it got: the gRPC code name, or
+// the HTTP status code.
+func newCaller(opts options, lo loadOptions) (func(ctx context.Context, worker int, method string, v int32) string, func(), error) {
+	if lo.httpURL != "" {
+		base := strings.TrimRight(lo.httpURL, "/")
+		clients := make([]*http.Client, lo.connections)
+		for i := range clients {
+			// Each transport has its own connections.
+			clients[i] = &http.Client{Transport: &http.Transport{MaxIdleConnsPerHost: lo.concurrency}}
+		}
+		call := func(ctx context.Context, worker int, method string, v int32) string {
+			req, err := loadMethods[method].http(base, v)
+			if err != nil {
+				return "ERROR"
+			}
+			if opts.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, opts.timeout)
+				defer cancel()
+			}
+			md, _ := metadata.FromOutgoingContext(ctx)
+			for k, vs := range md {
+				for _, v := range vs {
+					req.Header.Add(k, v)
+				}
+			}
+			req.Header.Set("Content-Type", "application/json")
+			res, err := clients[worker%len(clients)].Do(req.WithContext(ctx))
+			if err != nil {
+				return "ERROR"
+			}
+			io.Copy(ioutil.Discard, res.Body)
+			res.Body.Close()
+			return strconv.Itoa(res.StatusCode)
+		}
+		return call, func() {}, nil
+	}
+
+	conns := make([]*grpc.ClientConn, 0, lo.connections)
+	closeAll := func() {
+		for _, c := range conns {
+			c.Close()
+		}
+	}
+	clients := make([]pb.MathServiceClient, 0, lo.connections)
+	for i := 0; i < lo.connections; i++ {
+		conn, err := dial(opts)
+		if err != nil {
+			closeAll()
+			return nil, nil, err
+		}
+		conns = append(conns, conn)
+		clients = append(clients, pb.NewMathServiceClient(conn))
+	}
+	call := func(ctx context.Context, worker int, method string, v int32) string {
+		if opts.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, opts.timeout)
+			defer cancel()
+		}
+		err := loadMethods[method].grpc(ctx, clients[worker%len(clients)], v)
+		return status.Code(err).String()
+	}
+	return call, closeAll, nil
+}
@@ -1,47 +1,172 @@
+// Command client calls the grpctest MathService and Introspection:
+//
+//	client [flags] <method> [method flags] [values]
+//
+// or puts load on it and reports on how it held up:
+//
+//	client [flags] load [load flags]
+//
+// The exit status is the numeric gRPC status code of the call, so 0 on
+// success, or 64 when the command line can't be understood.
 package main
 
 import (
-	"crypto/tls"
 	"context"
 	"fmt"
-	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
 	pb "github.com/zenoss/grpctest/pb"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
-// var addr = "35.244.172.248:443"
-var addr = "jpl.zenoss.io:443"
-func main() {
-	// opt := grpc.WithTransportCredentials(credentials.NewClientTLSFromCert(nil, ""))
+// exitUsage is EX_USAGE, clear of the gRPC status codes.
+const exitUsage = 64
+
+// options are the flags shared by every method.
+type options struct {
+	addr       string
+	tls        string
+	plaintext  bool
+	caFile     string
+	certFile   string
+	keyFile    string
+	serverName string
 
-	// conn, err := grpc.Dial(addr, opt)
-	// dialOpts := []grpc.DialOption{
-	// 	grpc.WithBlock(),
-	// 	grpc.WithInsecure(),
-	// }
+	token     string
+	tokenFile string
+	apiKey    string
+	headers   []string
+
+	timeout time.Duration
+	output  string
+	verbose bool
+}
+
+// usageError is a command line that can't be understood.
+type usageError struct{ error }
+
+func usagef(format string, args ...interface{}) error {
+	return usageError{errors.Errorf(format, args...)}
+}
 
-	tlsCreds := credentials.NewTLS(&tls.Config{InsecureSkipVerify: true})
-	opt := grpc.WithTransportCredentials(tlsCreds)
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
 
-	conn, err := grpc.Dial(addr, opt)
+func run(args []string) int {
+	var opts options
+	flags := pflag.NewFlagSet("client", pflag.ContinueOnError)
+	// Flags after the method name are the method's.
+	flags.SetInterspersed(false)
+	flags.StringVar(&opts.addr, "addr", "localhost:8080", "server address")
+	flags.StringVar(&opts.tls, "tls", "", "TLS mode: verify, skip-verify or off (default off for a loopback --addr without --cacert or --cert, verify otherwise)")
+	flags.BoolVar(&opts.plaintext, "plaintext", false, "same as --tls off")
+	flags.StringVar(&opts.caFile, "cacert", "", "PEM file of the CAs to verify the server with (default the system roots)")
+	flags.StringVar(&opts.certFile, "cert", "", "PEM client certificate, for servers that ask for one")
+	flags.StringVar(&opts.keyFile, "key", "", "PEM key of the client certificate")
+	flags.StringVar(&opts.serverName, "server-name", "", "name to verify the server certificate against (default the host of --addr)")
+	flags.StringVar(&opts.token, "token", "", "bearer token for the authorization header")
+	flags.StringVar(&opts.tokenFile, "token-file", "", "file holding the bearer token")
+	flags.StringVar(&opts.apiKey, "api-key", "", "Zenoss API key for the z-api-key header")
+	flags.StringArrayVarP(&opts.headers, "header", "H", nil, `metadata to send, as "name: value"; repeatable`)
+	flags.DurationVar(&opts.timeout, "timeout", 0, "deadline of the call (default none)")
+	flags.StringVarP(&opts.output, "output", "o", "text", "output format: text, json or prototext")
+	flags.BoolVarP(&opts.verbose, "verbose", "v", false, "print response headers and trailers to stderr")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: client [flags] <method> [method flags] [values]\n\nMethods:\n")
+		names := make([]string, 0, len(commands))
+		for name := range commands {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(os.Stderr, "  %-14s %s\n", name, commands[name].usage)
+		}
+		fmt.Fprintf(os.Stderr, "\n  %-14s %s\n", "load", "[--concurrency --rps --total --duration --methods --tokens-file --http ...]")
+		fmt.Fprintf(os.Stderr, "\nFlags:\n%s", flags.FlagUsages())
+	}
+	if err := flags.Parse(args); err != nil {
+		if err == pflag.ErrHelp {
+			return 0
+		}
+		return exitUsage
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return exitUsage
+	}
+	name := flags.Arg(0)
+	cmd, ok := commands[name]
+	if !ok && name != "load" {
+		fmt.Fprintf(os.Stderr, "unknown method %q\n", flags.Arg(0))
+		flags.Usage()
+		return exitUsage
+	}
+	out, err := newPrinter(opts.output, opts.verbose)
 	if err != nil {
-		log.Fatalf("You do not deserve a connection: %v", err)
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
 	}
-	defer conn.Close()
-	client := pb.NewMathServiceClient(conn)
 
-	resp, err := client.Square(context.Background(), &pb.Request{Value: 20})
-	if err != nil {
-		log.Fatalf("A bad request: %v", err)
+	if name == "load" {
+		err = runLoad(opts, out, flags.Args()[1:])
+	} else {
+		err = call(opts, out, cmd, name, flags.Args()[1:])
+	}
+	if err == nil {
+		return 0
+	}
+	if _, ok := err.(usageError); ok {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+	st := status.Convert(err)
+	out.status(st)
+	if st.Code() == codes.OK {
+		// Not a gRPC error after all.
+		return int(codes.Unknown)
+	}
+	return int(st.Code())
+}
+
+func call(opts options, out *printer, cmd command, name string, args []string) error {
+	flags := pflag.NewFlagSet(name, pflag.ContinueOnError)
+	run := cmd.setup(flags)
+	if err := flags.Parse(args); err != nil {
+		if err == pflag.ErrHelp {
+			return nil
+		}
+		return usageError{err}
 	}
-	fmt.Println("Received value:", resp.Value)
 
-	resp, err = client.Random(context.Background(), &pb.Empty{})
+	ctx, err := outgoingContext(context.Background(), opts)
 	if err != nil {
-		log.Fatalf("A bad request: %v", err)
+		return err
 	}
-	fmt.Println("Received Random value:", resp.Value)
+	if opts.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.timeout)
+		defer cancel()
+	}
+	conn, err := dial(opts)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	return run(ctx, clients{pb.NewMathServiceClient(conn), pb.NewIntrospectionClient(conn)}, out, flags.Args())
+}
 
+// parseHeader splits a "name: value" header.
+func parseHeader(h string) (string, string, error) {
+	i := strings.Index(h, ":")
+	if i <= 0 {
+		return "", "", usagef("header %q is not name: value", h)
+	}
+	return strings.ToLower(strings.TrimSpace(h[:i])), strings.TrimSpace(h[i+1:]), nil
 }
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/pkg/errors"
+	pb "github.com/zenoss/grpctest/pb"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	// So jsonpb can print the error details the server sends.
+	_ "google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+// printer writes responses to stdout and errors to stderr. Streams print
+// one message per line in the json format, so the output is JSON lines.
+type printer struct {
+	format  string
+	verbose bool
+	out     io.Writer
+	err     io.Writer
+	json    *jsonpb.Marshaler
+}
+
+func newPrinter(format string, verbose bool) (*printer, error) {
+	switch format {
+	case "text", "json", "prototext":
+	default:
+		return nil, usagef("unknown output format %q", format)
+	}
+	return &printer{
+		format:  format,
+		verbose: verbose,
+		out:     os.Stdout,
+		err:     os.Stderr,
+		json:    &jsonpb.Marshaler{},
+	}, nil
+}
+
+func (p *printer) message(m proto.Message) {
+	switch p.format {
+	case "json":
+		s, err := p.json.MarshalToString(m)
+		if err != nil {
+			fmt.Fprintln(p.err, "unable to print response:", err)
+			return
+		}
+		fmt.Fprintln(p.out, s)
+	case "prototext":
+		fmt.Fprint(p.out, proto.MarshalTextString(m))
+	default:
+		fmt.Fprintln(p.out, text(m))
+	}
+}
+
+// text is the plain value of the MathService results.
+func text(m proto.Message) string {
+	switch m := m.(type) {
+	case *pb.Result:
+		return fmt.Sprint(m.GetValue())
+	case *pb.Int64Result:
+		return fmt.Sprint(m.GetValue())
+	case *pb.BigInteger:
+		return m.GetValue()
+	case *pb.RandomResult:
+		values := make([]string, len(m.GetValues()))
+		for i, v := range m.GetValues() {
+			values[i] = fmt.Sprint(v)
+		}
+		s := strings.Join(values, " ")
+		if m.GetSeed() != nil {
+			s += fmt.Sprintf("\nseed %d", m.GetSeed().GetValue())
+		}
+		return s
+	}
+	return proto.CompactTextString(m)
+}
+
+// metadata prints the response headers or trailers when verbose.
+func (p *printer) metadata(kind string, md metadata.MD) {
+	if !p.verbose || len(md) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(md))
+	for k := range md {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range md[k] {
+			fmt.Fprintf(p.err, "%s %s: %s\n", kind, k, v)
+		}
+	}
+}
+
+// status prints a failed call's status and details.
+func (p *printer) status(st *status.Status) {
+	switch p.format {
+	case "json":
+		s, err := p.json.MarshalToString(st.Proto())
+		if err == nil {
+			fmt.Fprintln(p.err, s)
+			return
+		}
+	case "prototext":
+		fmt.Fprint(p.err, proto.MarshalTextString(st.Proto()))
+		return
+	}
+	fmt.Fprintf(p.err, "ERROR: %s: %s\n", st.Code(), st.Message())
+	for _, any := range st.Proto().GetDetails() {
+		var detail ptypes.DynamicAny
+		if err := ptypes.UnmarshalAny(any, &detail); err != nil {
+			fmt.Fprintf(p.err, "  %s\n", errors.Wrap(err, any.GetTypeUrl()))
+			continue
+		}
+		fmt.Fprintf(p.err, "  %s: %s\n", proto.MessageName(detail.Message), proto.CompactTextString(detail.Message))
+	}
+}
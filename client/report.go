@@ -0,0 +1,281 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// report summarizes the samples of a load run.
+type report struct {
+	interval  time.Duration
+	elapsed   time.Duration
+	latencies []time.Duration
+	codes     map[string]int
+	methods   map[string]map[string]int
+	tenants   map[string]map[string]int
+	timeline  []loadBucket
+}
+
+type loadBucket struct {
+	Calls  int `json:"calls"`
+	Errors int `json:"errors"`
+}
+
+func newReport(interval time.Duration) *report {
+	return &report{
+		interval: interval,
+		codes:    map[string]int{},
+		methods:  map[string]map[string]int{},
+		tenants:  map[string]map[string]int{},
+	}
+}
+
+// succeeded is whether a sample's code is gRPC's OK or a 2xx HTTP status.
+func succeeded(code string) bool {
+	return code == "OK" || len(code) == 3 && code[0] == '2'
+}
+
+func (r *report) add(s sample) {
+	r.latencies = append(r.latencies, s.latency)
+	r.codes[s.code]++
+	count(r.methods, s.method, s.code)
+	if s.tenant != "" {
+		count(r.tenants, s.tenant, s.code)
+	}
+	i := int(s.done / r.interval)
+	for len(r.timeline) <= i {
+		r.timeline = append(r.timeline, loadBucket{})
+	}
+	r.timeline[i].Calls++
+	if !succeeded(s.code) {
+		r.timeline[i].Errors++
+	}
+}
+
+func count(m map[string]map[string]int, key, code string) {
+	if m[key] == nil {
+		m[key] = map[string]int{}
+	}
+	m[key][code]++
+}
+
+func (r *report) finish(elapsed time.Duration) {
+	r.elapsed = elapsed
+	sort.Slice(r.latencies, func(i, j int) bool { return r.latencies[i] < r.latencies[j] })
+}
+
+// percentile is the nearest-rank percentile of the sorted latencies.
+func (r *report) percentile(p float64) time.Duration {
+	if len(r.latencies) == 0 {
+		return 0
+	}
+	i := int(math.Ceil(p/100*float64(len(r.latencies)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	return r.latencies[i]
+}
+
+func (r *report) mean() time.Duration {
+	if len(r.latencies) == 0 {
+		return 0
+	}
+	var sum time.Duration
+	for _, l := range r.latencies {
+		sum += l
+	}
+	return sum / time.Duration(len(r.latencies))
+}
+
+// histogramBucket counts the latencies up to Le.
+type histogramBucket struct {
+	Le    time.Duration `json:"le"`
+	Count int           `json:"count"`
+}
+
+// histogram splits the latencies into ten buckets between the fastest and
+// the slowest.
+func (r *report) histogram() []histogramBucket {
+	if len(r.latencies) == 0 {
+		return nil
+	}
+	min, max := r.latencies[0], r.latencies[len(r.latencies)-1]
+	width := (max - min) / 10
+	if width == 0 {
+		return []histogramBucket{{Le: max, Count: len(r.latencies)}}
+	}
+	buckets := make([]histogramBucket, 10)
+	for i := range buckets {
+		buckets[i].Le = min + time.Duration(i+1)*width
+	}
+	buckets[9].Le = max
+	i := 0
+	for _, l := range r.latencies {
+		for l > buckets[i].Le {
+			i++
+		}
+		buckets[i].Count++
+	}
+	return buckets
+}
+
+var reportPercentiles = []float64{50, 90, 95, 99, 99.9}
+
+func (r *report) print(out *printer) error {
+	if out.format == "json" {
+		return r.printJSON(out)
+	}
+	total := len(r.latencies)
+	ok := 0
+	for code, n := range r.codes {
+		if succeeded(code) {
+			ok += n
+		}
+	}
+	w := out.out
+	fmt.Fprintf(w, "Summary:\n")
+	fmt.Fprintf(w, "  Calls:       %d in %s\n", total, r.elapsed.Round(time.Millisecond))
+	fmt.Fprintf(w, "  Throughput:  %.1f calls/s\n", rate(total, r.elapsed))
+	if total > 0 {
+		fmt.Fprintf(w, "  Succeeded:   %.2f%%\n", 100*float64(ok)/float64(total))
+	}
+	if total == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(w, "\nLatency:\n")
+	fmt.Fprintf(w, "  min    %s\n  mean   %s\n", round(r.latencies[0]), round(r.mean()))
+	for _, p := range reportPercentiles {
+		fmt.Fprintf(w, "  p%-5g %s\n", p, round(r.percentile(p)))
+	}
+	fmt.Fprintf(w, "  max    %s\n", round(r.latencies[total-1]))
+
+	fmt.Fprintf(w, "\nHistogram:\n")
+	hist := r.histogram()
+	most := 0
+	for _, b := range hist {
+		if b.Count > most {
+			most = b.Count
+		}
+	}
+	for _, b := range hist {
+		fmt.Fprintf(w, "  ≤ %-10s %8d %s\n", round(b.Le), b.Count, strings.Repeat("■", 40*b.Count/most))
+	}
+
+	fmt.Fprintf(w, "\nStatus codes:\n")
+	printCodes(w, "  ", r.codes)
+	printBreakdown(w, "By method", r.methods)
+	printBreakdown(w, "By tenant", r.tenants)
+
+	fmt.Fprintf(w, "\nTimeline:\n")
+	for i, b := range r.timeline {
+		fmt.Fprintf(w, "  %8s %8.1f calls/s %6d errors\n", time.Duration(i)*r.interval, rate(b.Calls, r.interval), b.Errors)
+	}
+	return nil
+}
+
+func printCodes(w io.Writer, indent string, codes map[string]int) {
+	for _, code := range sortedKeys(codes) {
+		fmt.Fprintf(w, "%s%-20s %d\n", indent, code, codes[code])
+	}
+}
+
+func printBreakdown(w io.Writer, title string, m map[string]map[string]int) {
+	if len(m) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n%s:\n", title)
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(w, "  %s\n", k)
+		printCodes(w, "    ", m[k])
+	}
+}
+
+func (r *report) printJSON(out *printer) error {
+	latency := map[string]string{}
+	if len(r.latencies) > 0 {
+		latency["min"] = r.latencies[0].String()
+		latency["mean"] = r.mean().String()
+		latency["max"] = r.latencies[len(r.latencies)-1].String()
+		for _, p := range reportPercentiles {
+			latency[fmt.Sprintf("p%g", p)] = r.percentile(p).String()
+		}
+	}
+	type timelineEntry struct {
+		Start string  `json:"start"`
+		Rate  float64 `json:"rate"`
+		loadBucket
+	}
+	timeline := make([]timelineEntry, len(r.timeline))
+	for i, b := range r.timeline {
+		timeline[i] = timelineEntry{(time.Duration(i) * r.interval).String(), rate(b.Calls, r.interval), b}
+	}
+	type bucket struct {
+		Le    string `json:"le"`
+		Count int    `json:"count"`
+	}
+	var hist []bucket
+	for _, b := range r.histogram() {
+		hist = append(hist, bucket{b.Le.String(), b.Count})
+	}
+	enc := json.NewEncoder(out.out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Calls      int                       `json:"calls"`
+		Elapsed    string                    `json:"elapsed"`
+		Throughput float64                   `json:"throughput"`
+		Latency    map[string]string         `json:"latency"`
+		Histogram  []bucket                  `json:"histogram"`
+		Codes      map[string]int            `json:"codes"`
+		Methods    map[string]map[string]int `json:"methods"`
+		Tenants    map[string]map[string]int `json:"tenants,omitempty"`
+		Timeline   []timelineEntry           `json:"timeline"`
+	}{
+		Calls:      len(r.latencies),
+		Elapsed:    r.elapsed.String(),
+		Throughput: rate(len(r.latencies), r.elapsed),
+		Latency:    latency,
+		Histogram:  hist,
+		Codes:      r.codes,
+		Methods:    r.methods,
+		Tenants:    r.tenants,
+		Timeline:   timeline,
+	})
+}
+
+func rate(n int, d time.Duration) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return float64(n) / d.Seconds()
+}
+
+// round keeps three significant digits or so.
+func round(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(time.Microsecond)
+	}
+	return d
+}
+
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
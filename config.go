@@ -0,0 +1,553 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/zenoss/grpctest/certs"
+	"github.com/zenoss/grpctest/extauthz"
+	"github.com/zenoss/grpctest/fault"
+	"github.com/zenoss/grpctest/metrics"
+	"github.com/zenoss/grpctest/profile"
+	"github.com/zenoss/grpctest/quota"
+	"github.com/zenoss/grpctest/ratelimit"
+	"github.com/zenoss/grpctest/redact"
+	"github.com/zenoss/zenkit"
+)
+
+// Config is the typed grpctest configuration, read from the defaults, the
+// config file, GRPCTEST_* environment variables and the command line flags,
+// each overriding the one before. The config file is watched; the log
+// level, random parity, quotas, rate limits, ext_authz rules, shutdown
+// timings, metric label limits, trace sample rate, version, header
+// redaction, fault injection and behavior profiles are applied as soon
+// as it changes, and everything else needs a restart.
+type Config struct {
+	GRPC       GRPCSettings               `mapstructure:"grpc"`
+	HTTP       HTTPSettings               `mapstructure:"http"`
+	TLS        TLSSettings                `mapstructure:"tls"`
+	Auth       AuthSettings               `mapstructure:"auth"`
+	Log        LogSettings                `mapstructure:"log"`
+	Random     RandomSettings             `mapstructure:"random"`
+	Gateway    GatewaySettings            `mapstructure:"gateway"`
+	ExtAuthz   ExtAuthzSettings           `mapstructure:"extauthz"`
+	Quota      QuotaSettings              `mapstructure:"quota"`
+	RateLimit  RateLimitSettings          `mapstructure:"ratelimit"`
+	Shutdown   ShutdownSettings           `mapstructure:"shutdown"`
+	Health     HealthSettings             `mapstructure:"health"`
+	Prometheus PrometheusSettings         `mapstructure:"prometheus"`
+	Tracing    TracingSettings            `mapstructure:"tracing"`
+	Debug      DebugSettings              `mapstructure:"debug"`
+	Fault      fault.Config               `mapstructure:"fault"`
+	Version    string                     `mapstructure:"version"`
+	Profile    string                     `mapstructure:"profile"`
+	Profiles   map[string]profile.Profile `mapstructure:"profiles"`
+}
+
+type GRPCSettings struct {
+	ListenAddr string `mapstructure:"listen_addr"`
+	HealthAddr string `mapstructure:"health_addr"`
+}
+
+type HTTPSettings struct {
+	ListenAddr string `mapstructure:"listen_addr"`
+}
+
+// TLSSettings serves the gRPC and HTTP ports over TLS, optionally asking
+// clients for certificates. The files are reloaded when they change, but
+// the settings themselves need a restart. The health port stays plaintext
+// for the kubelet.
+type TLSSettings struct {
+	Enabled      bool `mapstructure:"enabled"`
+	certs.Config `mapstructure:",squash"`
+}
+
+// AuthSettings picks between verified Auth0 tokens and, when Disabled,
+// zenkit's dev identity.
+type AuthSettings struct {
+	Disabled bool          `mapstructure:"disabled"`
+	Header   string        `mapstructure:"header"`
+	JWKSURI  string        `mapstructure:"jwks_uri"`
+	Issuer   string        `mapstructure:"issuer"`
+	Audience []string      `mapstructure:"audience"`
+	Leeway   time.Duration `mapstructure:"leeway"`
+}
+
+type LogSettings struct {
+	Level       string `mapstructure:"level"`
+	Format      string `mapstructure:"format"`
+	Stackdriver bool   `mapstructure:"stackdriver"`
+}
+
+type RandomSettings struct {
+	Parity string `mapstructure:"parity"`
+}
+
+type GatewaySettings struct {
+	Enabled     bool     `mapstructure:"enabled"`
+	Descriptor  string   `mapstructure:"descriptor"`
+	Services    []string `mapstructure:"services"`
+	AutoMapping bool     `mapstructure:"auto_mapping"`
+}
+
+type ExtAuthzSettings struct {
+	Enabled     bool            `mapstructure:"enabled"`
+	Rules       []extauthz.Rule `mapstructure:"rules"`
+	DefaultDeny bool            `mapstructure:"default_deny"`
+}
+
+type QuotaSettings struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	Backend      string        `mapstructure:"backend"`
+	Redis        RedisSettings `mapstructure:"redis"`
+	quota.Config `mapstructure:",squash"`
+}
+
+type RateLimitSettings struct {
+	Enabled          bool          `mapstructure:"enabled"`
+	Backend          string        `mapstructure:"backend"`
+	Redis            RedisSettings `mapstructure:"redis"`
+	ratelimit.Config `mapstructure:",squash"`
+}
+
+type ShutdownSettings struct {
+	DrainDelay time.Duration `mapstructure:"drain_delay"`
+	Timeout    time.Duration `mapstructure:"timeout"`
+}
+
+type HealthSettings struct {
+	Interval     time.Duration `mapstructure:"interval"`
+	Timeout      time.Duration `mapstructure:"timeout"`
+	JWKSMaxAge   time.Duration `mapstructure:"jwks_max_age"`
+	KeyServerURL string        `mapstructure:"keyserver_url"`
+}
+
+type PrometheusSettings struct {
+	Enabled    bool   `mapstructure:"enabled"`
+	Path       string `mapstructure:"path"`
+	MaxTenants int    `mapstructure:"max_tenants"`
+	MaxMethods int    `mapstructure:"max_methods"`
+}
+
+type TracingSettings struct {
+	Exporter   string         `mapstructure:"exporter"`
+	SampleRate float64        `mapstructure:"samplerate"`
+	JSONL      JSONLSettings  `mapstructure:"jsonl"`
+	Zipkin     ZipkinSettings `mapstructure:"zipkin"`
+}
+
+type JSONLSettings struct {
+	Path string `mapstructure:"path"`
+}
+
+type ZipkinSettings struct {
+	URL string `mapstructure:"url"`
+}
+
+type DebugSettings struct {
+	Redact redact.Config `mapstructure:"redact"`
+}
+
+type RedisSettings struct {
+	DBID int `mapstructure:"dbid"`
+}
+
+// defaults are the grpctest settings' defaults.
+var defaults = map[string]interface{}{
+	HTTPListenAddrConfig:  ":8081",
+	TLSEnabledConfig:      false,
+	TLSCertFileConfig:     "",
+	TLSKeyFileConfig:      "",
+	TLSClientCAFileConfig: "",
+	TLSClientAuthConfig:   string(certs.NoClientCert),
+	TLSMinVersionConfig:   "1.2",
+	TLSCipherSuitesConfig: []string{},
+	AuthHeaderConfig:      "authorization",
+	// Same origin as yaml/poc-jwt.yaml
+	AuthJWKSURIConfig:         "https://zenoss-dev.auth0.com/.well-known/jwks.json",
+	AuthIssuerConfig:          "https://zenoss-dev.auth0.com/",
+	AuthAudienceConfig:        []string{},
+	AuthLeewayConfig:          "30s",
+	GatewayEnabledConfig:      true,
+	GatewayDescriptorConfig:   "",
+	GatewayServicesConfig:     []string{"MathService", "Introspection"},
+	GatewayAutoMappingConfig:  true,
+	ExtAuthzEnabledConfig:     false,
+	ExtAuthzDefaultDenyConfig: false,
+	APIKeyEnabledConfig:       true,
+	APIKeyAuth0DomainConfig:   "zenoss-dev.auth0.com",
+	APIKeyAuth0AudienceConfig: "",
+	APIKeyAuth0TokenURLConfig: "",
+	// minutes
+	APIKeyCacheTimeoutConfig:  1440,
+	APIKeyLRUSizeLimitConfig:  2500,
+	APIKeyRedisEnabledConfig:  false,
+	APIKeyRedisDBIDConfig:     0,
+	QuotaEnabledConfig:        false,
+	QuotaBackendConfig:        "memory",
+	QuotaRedisDBIDConfig:      0,
+	RateLimitEnabledConfig:    false,
+	RateLimitShadowModeConfig: false,
+	RateLimitBackendConfig:    "memory",
+	RateLimitRedisDBIDConfig:  0,
+	RandomParityConfig:        "any",
+	LogFormatConfig:           "",
+	// Envoy's endpoints take a few seconds to catch up
+	ShutdownDrainDelayConfig: "5s",
+	ShutdownTimeoutConfig:    "30s",
+	HealthIntervalConfig:     "10s",
+	HealthTimeoutConfig:      "2s",
+	// twice auth.DefaultRefreshInterval
+	HealthJWKSMaxAgeConfig:     "2h",
+	HealthKeyServerURLConfig:   "",
+	PrometheusEnabledConfig:    true,
+	PrometheusPathConfig:       "/metrics",
+	PrometheusMaxTenantsConfig: metrics.DefaultMaxTenants,
+	PrometheusMaxMethodsConfig: metrics.DefaultMaxMethods,
+	TracingExporterConfig:      "none",
+	TracingJSONLPathConfig:     "-",
+	TracingZipkinURLConfig:     "http://localhost:9411/api/v2/spans",
+	DebugRedactHeadersConfig:   []string{},
+	DebugRedactValuesConfig:    []string{},
+	DebugRedactClaimsConfig:    true,
+	FaultEnabledConfig:         false,
+	FaultAllowConfig:           []string{"delay", "abort", "reset", "panic"},
+	FaultMaxDelayConfig:        "10s",
+	FaultMaxPercentConfig:      100,
+	VersionConfig:              "",
+	ProfileConfig:              profile.Default,
+}
+
+// zenkitDefaults are zenkit's defaults for the zenkit settings in Config.
+var zenkitDefaults = map[string]interface{}{
+	zenkit.GRPCListenAddrConfig:    ":8080",
+	zenkit.AuthDisabledConfig:      false,
+	zenkit.LogLevelConfig:          "info",
+	zenkit.LogStackdriverConfig:    true,
+	zenkit.TracingSampleRateConfig: 1.0,
+}
+
+// zenkitOverrides are zenkit settings grpctest defaults differently.
+var zenkitOverrides = map[string]interface{}{
+	// The http mux already owns :8081
+	zenkit.GRPCHealthAddrConfig: ":8082",
+	zenkit.TracingEnabledConfig: false,
+	zenkit.MetricsEnabledConfig: false,
+}
+
+// configFlags maps the command line flags to settings.
+var configFlags = []struct {
+	name, key, usage string
+}{
+	{"config", ConfigFileConfig, "yaml, toml or json config file, watched for changes"},
+	{"grpc-listen-addr", zenkit.GRPCListenAddrConfig, "gRPC listen address"},
+	{"http-listen-addr", HTTPListenAddrConfig, "HTTP listen address"},
+	{"log-level", zenkit.LogLevelConfig, "log level"},
+	{"random-parity", RandomParityConfig, "parity of Random values: any, even or odd"},
+	{"profile", ProfileConfig, "behavior profile, e.g. even-only or slow-10pct"},
+}
+
+func newFlagSet() *pflag.FlagSet {
+	flags := pflag.NewFlagSet(serviceName, pflag.ExitOnError)
+	for _, f := range configFlags {
+		flags.String(f.name, "", f.usage)
+	}
+	flags.Bool("auth-disabled", false, "use zenkit's dev identity instead of verifying tokens")
+	return flags
+}
+
+func bindFlags(v *viper.Viper, flags *pflag.FlagSet) {
+	for _, f := range configFlags {
+		v.BindPFlag(f.key, flags.Lookup(f.name))
+	}
+	v.BindPFlag(zenkit.AuthDisabledConfig, flags.Lookup("auth-disabled"))
+}
+
+// initConfig layers the grpctest settings over zenkit's in the global
+// viper, which zenkit reads. zenkit.InitConfig resets viper defaults every
+// time it runs, so the zenkit overrides are merged in as config instead,
+// under the config file, where environment variables can still override
+// them.
+func initConfig(flags *pflag.FlagSet) {
+	zenkit.InitConfig(serviceName)
+	for key, value := range defaults {
+		viper.SetDefault(key, value)
+	}
+	for key, value := range zenkitOverrides {
+		viper.MergeConfigMap(nest(key, value))
+	}
+	bindFlags(viper.GetViper(), flags)
+	if file := viper.GetString(ConfigFileConfig); file != "" {
+		viper.SetConfigFile(file)
+		if err := viper.MergeInConfig(); err != nil {
+			bootLogger.WithError(err).WithField("config_file", file).Fatal("unable to read config")
+		}
+	}
+}
+
+// nest turns a dotted key into the nested maps MergeConfigMap expects.
+func nest(key string, value interface{}) map[string]interface{} {
+	parts := strings.Split(key, ".")
+	m := map[string]interface{}{parts[len(parts)-1]: value}
+	for i := len(parts) - 2; i >= 0; i-- {
+		m = map[string]interface{}{parts[i]: m}
+	}
+	return m
+}
+
+// loadConfig reads and validates the typed config. It uses a viper of its
+// own, so a reload never disturbs the global one.
+func loadConfig(file string, flags *pflag.FlagSet) (*Config, error) {
+	v := viper.New()
+	for _, m := range []map[string]interface{}{defaults, zenkitDefaults, zenkitOverrides} {
+		for key, value := range m {
+			v.SetDefault(key, value)
+		}
+	}
+	v.SetEnvPrefix(serviceName)
+	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+	v.AutomaticEnv()
+	bindFlags(v, flags)
+	if file != "" {
+		v.SetConfigFile(file)
+		if err := v.ReadInConfig(); err != nil {
+			return nil, errors.Wrapf(err, "unable to read %s", file)
+		}
+	}
+	cfg := &Config{}
+	if err := v.Unmarshal(cfg); err != nil {
+		return nil, errors.Wrap(err, "unable to decode config")
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid config")
+	}
+	return cfg, nil
+}
+
+// Validate checks every setting, so a bad config is refused before any of
+// it is used.
+func (c *Config) Validate() error {
+	for _, addr := range []struct{ key, value string }{
+		{zenkit.GRPCListenAddrConfig, c.GRPC.ListenAddr},
+		{zenkit.GRPCHealthAddrConfig, c.GRPC.HealthAddr},
+		{HTTPListenAddrConfig, c.HTTP.ListenAddr},
+	} {
+		if _, _, err := net.SplitHostPort(addr.value); err != nil {
+			return errors.Wrapf(err, "%s", addr.key)
+		}
+	}
+	if c.TLS.Enabled {
+		if err := c.TLS.Config.Validate(); err != nil {
+			return errors.Wrap(err, "tls")
+		}
+		if _, err := tls.LoadX509KeyPair(c.TLS.CertFile, c.TLS.KeyFile); err != nil {
+			return errors.Wrap(err, "unable to load the tls key pair")
+		}
+	}
+	if c.Auth.Header == "" {
+		return errors.Errorf("%s must not be empty", AuthHeaderConfig)
+	}
+	if !c.Auth.Disabled && (c.Auth.JWKSURI == "" || c.Auth.Issuer == "") {
+		return errors.Errorf("%s and %s are needed unless %s is set", AuthJWKSURIConfig, AuthIssuerConfig, zenkit.AuthDisabledConfig)
+	}
+	if c.Auth.Leeway < 0 {
+		return errors.Errorf("%s must not be negative", AuthLeewayConfig)
+	}
+	if _, err := logrus.ParseLevel(c.Log.Level); err != nil {
+		return errors.Wrapf(err, "%s", zenkit.LogLevelConfig)
+	}
+	if f := c.Log.Format; f != "" && f != "stackdriver" && f != "text" {
+		return errors.Errorf("unknown %s %q", LogFormatConfig, f)
+	}
+	if _, err := parseParity(c.Random.Parity); err != nil {
+		return errors.Wrapf(err, "%s", RandomParityConfig)
+	}
+	if c.Shutdown.DrainDelay < 0 {
+		return errors.Errorf("%s must not be negative", ShutdownDrainDelayConfig)
+	}
+	if c.Shutdown.Timeout <= 0 {
+		return errors.Errorf("%s must be positive", ShutdownTimeoutConfig)
+	}
+	if c.Health.Interval <= 0 || c.Health.Timeout <= 0 || c.Health.JWKSMaxAge <= 0 {
+		return errors.Errorf("%s, %s and %s must be positive", HealthIntervalConfig, HealthTimeoutConfig, HealthJWKSMaxAgeConfig)
+	}
+	if c.Health.KeyServerURL != "" {
+		if u, err := url.Parse(c.Health.KeyServerURL); err != nil || u.Host == "" {
+			return errors.Errorf("%s %q is not a URL", HealthKeyServerURLConfig, c.Health.KeyServerURL)
+		}
+	}
+	if !strings.HasPrefix(c.Prometheus.Path, "/") {
+		return errors.Errorf("%s must start with /", PrometheusPathConfig)
+	}
+	if c.Prometheus.MaxTenants < 1 || c.Prometheus.MaxMethods < 1 {
+		return errors.Errorf("%s and %s must be positive", PrometheusMaxTenantsConfig, PrometheusMaxMethodsConfig)
+	}
+	switch c.Tracing.Exporter {
+	case "none":
+	case "jsonl":
+		if c.Tracing.JSONL.Path == "" {
+			return errors.Errorf("%s must not be empty", TracingJSONLPathConfig)
+		}
+	case "zipkin":
+		if u, err := url.Parse(c.Tracing.Zipkin.URL); err != nil || u.Host == "" {
+			return errors.Errorf("%s %q is not a URL", TracingZipkinURLConfig, c.Tracing.Zipkin.URL)
+		}
+	default:
+		return errors.Errorf("unknown %s %q", TracingExporterConfig, c.Tracing.Exporter)
+	}
+	if c.Tracing.SampleRate < 0 || c.Tracing.SampleRate > 1 {
+		return errors.Errorf("%s must be between 0 and 1", zenkit.TracingSampleRateConfig)
+	}
+	if err := c.Debug.Redact.Validate(); err != nil {
+		return errors.Wrap(err, "debug.redact")
+	}
+	if err := c.Fault.Validate(); err != nil {
+		return errors.Wrap(err, "fault")
+	}
+	for name, p := range c.Profiles {
+		if err := p.Validate(); err != nil {
+			return errors.Wrapf(err, "%s.%s", ProfilesConfig, name)
+		}
+	}
+	if _, err := profile.Lookup(c.Profiles, c.Profile); err != nil {
+		return errors.Wrapf(err, "%s", ProfileConfig)
+	}
+	if c.Quota.Enabled {
+		if err := checkBackend(QuotaBackendConfig, c.Quota.Backend); err != nil {
+			return err
+		}
+		if err := c.Quota.Config.Validate(); err != nil {
+			return errors.Wrap(err, "quota")
+		}
+	}
+	if c.RateLimit.Enabled {
+		if err := checkBackend(RateLimitBackendConfig, c.RateLimit.Backend); err != nil {
+			return err
+		}
+		if err := c.RateLimit.Config.Validate(); err != nil {
+			return errors.Wrap(err, "ratelimit")
+		}
+	}
+	return nil
+}
+
+func checkBackend(key, backend string) error {
+	if backend != "memory" && backend != "redis" {
+		return errors.Errorf("unknown %s %q", key, backend)
+	}
+	return nil
+}
+
+// keepStartup puts back the settings of old that only take effect at
+// startup, returning the sections that had changed.
+func (c *Config) keepStartup(old *Config) []string {
+	var changed []string
+	keep := func(name string, next, prev interface{}) {
+		dst, src := reflect.ValueOf(next).Elem(), reflect.ValueOf(prev).Elem()
+		if !reflect.DeepEqual(dst.Interface(), src.Interface()) {
+			changed = append(changed, name)
+			dst.Set(src)
+		}
+	}
+	keep("grpc", &c.GRPC, &old.GRPC)
+	keep("http", &c.HTTP, &old.HTTP)
+	keep("tls", &c.TLS, &old.TLS)
+	keep("auth", &c.Auth, &old.Auth)
+	keep("log.format", &c.Log.Format, &old.Log.Format)
+	keep("log.stackdriver", &c.Log.Stackdriver, &old.Log.Stackdriver)
+	keep("gateway", &c.Gateway, &old.Gateway)
+	keep("health", &c.Health, &old.Health)
+	keep("prometheus.enabled", &c.Prometheus.Enabled, &old.Prometheus.Enabled)
+	keep("prometheus.path", &c.Prometheus.Path, &old.Prometheus.Path)
+	keep("tracing.exporter", &c.Tracing.Exporter, &old.Tracing.Exporter)
+	keep("tracing.jsonl", &c.Tracing.JSONL, &old.Tracing.JSONL)
+	keep("tracing.zipkin", &c.Tracing.Zipkin, &old.Tracing.Zipkin)
+	keep("extauthz.enabled", &c.ExtAuthz.Enabled, &old.ExtAuthz.Enabled)
+	keep("quota.enabled", &c.Quota.Enabled, &old.Quota.Enabled)
+	keep("quota.backend", &c.Quota.Backend, &old.Quota.Backend)
+	keep("quota.redis", &c.Quota.Redis, &old.Quota.Redis)
+	keep("ratelimit.enabled", &c.RateLimit.Enabled, &old.RateLimit.Enabled)
+	keep("ratelimit.backend", &c.RateLimit.Backend, &old.RateLimit.Backend)
+	keep("ratelimit.redis", &c.RateLimit.Redis, &old.RateLimit.Redis)
+	return changed
+}
+
+var current struct {
+	sync.RWMutex
+	cfg *Config
+}
+
+// currentConfig is the config in effect.
+func currentConfig() *Config {
+	current.RLock()
+	defer current.RUnlock()
+	return current.cfg
+}
+
+func setConfig(cfg *Config) {
+	current.Lock()
+	current.cfg = cfg
+	current.Unlock()
+}
+
+// watchConfig reloads the config file whenever it changes, until ctx is
+// done. Like gateway.WatchFile it watches the directory, so replaced files
+// and Kubernetes ConfigMap symlink swaps are seen. A config that fails to
+// load or validate is logged and ignored; otherwise the startup settings
+// are kept, apply is called with the new config, and it becomes current.
+func watchConfig(ctx context.Context, file string, flags *pflag.FlagSet, log *logrus.Entry, apply func(*Config)) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.Wrap(err, "unable to create config watcher")
+	}
+	file = filepath.Clean(file)
+	if err := watcher.Add(filepath.Dir(file)); err != nil {
+		watcher.Close()
+		return errors.Wrap(err, "unable to watch config directory")
+	}
+	realPath, _ := filepath.EvalSymlinks(file)
+
+	go func() {
+		defer watcher.Close()
+		log := log.WithField("config_file", file)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err := <-watcher.Errors:
+				log.WithError(err).Warn("config watcher error")
+			case event := <-watcher.Events:
+				current, _ := filepath.EvalSymlinks(file)
+				changed := filepath.Clean(event.Name) == file && event.Op&(fsnotify.Write|fsnotify.Create) != 0
+				if !changed && (current == "" || current == realPath) {
+					continue
+				}
+				realPath = current
+				next, err := loadConfig(file, flags)
+				if err != nil {
+					log.WithError(err).Error("unable to reload config; keeping the previous one")
+					continue
+				}
+				if changed := next.keepStartup(currentConfig()); len(changed) > 0 {
+					log.WithField("settings", changed).Warn("config changes need a restart to apply")
+				}
+				apply(next)
+				setConfig(next)
+				log.Info("reloaded config")
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zenoss/grpctest/accesslog"
+	"github.com/zenoss/grpctest/auth"
+	"github.com/zenoss/grpctest/certs"
+	pb "github.com/zenoss/grpctest/pb"
+	"github.com/zenoss/grpctest/redact"
+)
+
+// debugInfo is what reached the server with an HTTP request.
+type debugInfo struct {
+	Method     string      `json:"method"`
+	Path       string      `json:"path"`
+	Host       string      `json:"host"`
+	RemoteAddr string      `json:"remote_addr"`
+	RequestID  string      `json:"request_id"`
+	Headers    http.Header `json:"headers"`
+	// Claims are the bearer token's, unverified.
+	Claims      map[string]interface{} `json:"claims,omitempty"`
+	Identity    *pb.CallInfo_Identity  `json:"identity"`
+	Certificate *certs.Peer            `json:"certificate,omitempty"`
+	// Errors say why there is no identity or claims.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// debugHandler is the HTTP root. It shows what reached the server: the
+// headers Istio, the Lua filter and ext_authz forwarded, with their
+// credentials redacted, the identity the token carries and the client
+// certificate. It answers in text or, when the request prefers it, JSON.
+// A missing or invalid token is reported, not failed.
+func debugHandler(verifier *auth.Verifier, redactor *redact.Redactor) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info := &debugInfo{
+			Method:      r.Method,
+			Path:        r.URL.Path,
+			Host:        r.Host,
+			RemoteAddr:  r.RemoteAddr,
+			RequestID:   accesslog.FromContext(r.Context()),
+			Headers:     redactor.Header(r.Header),
+			Certificate: certs.PeerFromState(r.TLS),
+		}
+		if ident, err := requestIdentity(r, verifier); err != nil {
+			info.Errors = append(info.Errors, "no identity: "+err.Error())
+		} else if ident != nil {
+			info.Identity = identityMessage(ident)
+		}
+		if raw, ok := auth.BearerToken(r.Header.Get(currentConfig().Auth.Header)); ok && redactor.Claims() {
+			claims, err := auth.UnverifiedClaims(raw)
+			if err != nil {
+				info.Errors = append(info.Errors, "no claims: "+err.Error())
+			}
+			info.Claims = claims
+		}
+
+		if wantsJSON(r) {
+			w.Header().Set("Content-Type", "application/json")
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			enc.Encode(info)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		info.writeText(w)
+	})
+}
+
+func (info *debugInfo) writeText(w io.Writer) {
+	if info.Identity == nil {
+		fmt.Fprintln(w, "No identity on the token")
+	} else {
+		fmt.Fprintf(w, "You are: %s\n", info.Identity.Email)
+	}
+	for _, err := range info.Errors {
+		fmt.Fprintf(w, "Error: %s\n", err)
+	}
+	if info.Certificate != nil {
+		fmt.Fprintf(w, "Your certificate says: %s\n", info.Certificate.Name())
+	}
+	if len(info.Claims) > 0 {
+		fmt.Fprintln(w, "Your token claims:")
+		for _, name := range sortedKeys(info.Claims) {
+			value, _ := json.Marshal(info.Claims[name])
+			fmt.Fprintf(w, "  %s=%s\n", name, value)
+		}
+	}
+	names := make([]string, 0, len(info.Headers))
+	for name := range info.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintln(w, "Your headers:")
+	for i, name := range names {
+		fmt.Fprintf(w, "  Header#%d: %s=%s\n", i+1, name, info.Headers[name])
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// wantsJSON is whether the request asks for ?format=json, or its Accept
+// header ranks JSON above plain text.
+func wantsJSON(r *http.Request) bool {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format == "json"
+	}
+	var jsonQ, textQ float64
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		switch mediaType {
+		case "application/json", "application/*":
+			jsonQ = math.Max(jsonQ, q)
+		case "text/plain", "text/*", "*/*":
+			textQ = math.Max(textQ, q)
+		}
+	}
+	return jsonQ > textQ
+}
@@ -0,0 +1,236 @@
+// Package extauthz serves Envoy's gRPC external authorization API
+// (envoy.service.auth.v2.Authorization), deciding per request from the API
+// key or bearer token, the request path and the calling workload's
+// principal. It is the grpc_service counterpart of apikey.ExtAuthzHandler.
+package extauthz
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/zenoss/grpctest/accesslog"
+	"github.com/zenoss/grpctest/apikey"
+	"github.com/zenoss/grpctest/auth"
+	"github.com/zenoss/grpctest/metrics"
+	pb "github.com/zenoss/grpctest/pb/envoy/auth"
+	"github.com/zenoss/zenkit"
+	rpcstatus "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+)
+
+// Headers added to allowed requests, on top of the authorization header
+// when the caller sent an API key.
+const (
+	TenantHeader = "x-zenoss-tenant"
+	UserHeader   = "x-zenoss-user"
+)
+
+// Rule decides who may call the paths it matches. Rules are tried in
+// order and the first match applies.
+type Rule struct {
+	// Path is a request path such as /MathService/Square. A trailing *
+	// matches any suffix, so /MathService/* covers the whole service.
+	Path string `mapstructure:"path"`
+	// Public allows the request without any credentials.
+	Public bool `mapstructure:"public"`
+	// Deny rejects every request.
+	Deny bool `mapstructure:"deny"`
+	// Principals, if set, are the only source principals allowed, e.g. the
+	// SPIFFE IDs of the workloads that may call the path.
+	Principals []string `mapstructure:"principals"`
+	// Tenants, if set, are the only tenants allowed.
+	Tenants []string `mapstructure:"tenants"`
+}
+
+func (r *Rule) matches(path string) bool {
+	if strings.HasSuffix(r.Path, "*") {
+		return strings.HasPrefix(path, strings.TrimSuffix(r.Path, "*"))
+	}
+	return path == r.Path
+}
+
+// Config configures a Server.
+type Config struct {
+	// Source exchanges the z-api-key header for a token. Nil rejects API
+	// keys.
+	Source apikey.TokenSource
+	// Verifier checks the token, whether sent or exchanged.
+	Verifier *auth.Verifier
+	// Rules are matched against the request path. Requests no rule matches
+	// need a valid identity, unless DefaultDeny is set.
+	Rules       []Rule
+	DefaultDeny bool
+}
+
+// Server implements the Authorization service.
+type Server struct {
+	log *logrus.Entry
+
+	mu  sync.RWMutex
+	cfg Config
+}
+
+// NewServer creates an Authorization server.
+func NewServer(cfg Config, log *logrus.Entry) *Server {
+	return &Server{cfg: cfg, log: log}
+}
+
+// UpdateRules replaces the rules and DefaultDeny; checks already under way
+// finish with the old ones.
+func (s *Server) UpdateRules(rules []Rule, defaultDeny bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cfg.Rules = rules
+	s.cfg.DefaultDeny = defaultDeny
+}
+
+// AuthFuncOverride lets Envoy call Check without credentials of its own;
+// the credentials being checked are in the request.
+func (s *Server) AuthFuncOverride(ctx context.Context, fullMethodName string) (context.Context, error) {
+	return auth.Anonymous(ctx)
+}
+
+// Check implements pb.AuthorizationServer. Denials are answered with a
+// CheckResponse, not an error, so Envoy returns our status and body to the
+// caller.
+func (s *Server) Check(ctx context.Context, req *pb.CheckRequest) (*pb.CheckResponse, error) {
+	path := req.GetAttributes().GetRequest().GetHttp().GetPath()
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path = path[:i]
+	}
+	// Log the check under the ID of the request it is for.
+	if id := req.GetAttributes().GetRequest().GetHttp().GetId(); id != "" {
+		grpc_ctxtags.Extract(ctx).Set(accesslog.Field, id)
+	}
+	resp := s.check(ctx, req, path)
+	if code := codes.Code(resp.GetStatus().GetCode()); code != codes.OK {
+		metrics.RecordAuthFailure(ctx, path, code)
+	}
+	return resp, nil
+}
+
+func (s *Server) check(ctx context.Context, req *pb.CheckRequest, path string) *pb.CheckResponse {
+	attrs := req.GetAttributes()
+	httpReq := attrs.GetRequest().GetHttp()
+	principal := attrs.GetSource().GetPrincipal()
+	log := s.log.WithFields(logrus.Fields{
+		"path":      path,
+		"principal": principal,
+	})
+
+	s.mu.RLock()
+	cfg := s.cfg
+	s.mu.RUnlock()
+	rule := cfg.match(path)
+	switch {
+	case rule == nil && cfg.DefaultDeny, rule != nil && rule.Deny:
+		return denied(log, codes.PermissionDenied, "path not allowed")
+	case rule != nil && len(rule.Principals) > 0 && !zenkit.StringInSlice(principal, rule.Principals):
+		return denied(log, codes.PermissionDenied, "principal not allowed")
+	case rule != nil && rule.Public:
+		return allowed(nil)
+	}
+
+	headers := httpReq.GetHeaders()
+	var inject []*pb.HeaderValueOption
+	raw, ok := auth.BearerToken(headers["authorization"])
+	if key := headers[apikey.KeyHeader]; key != "" {
+		if cfg.Source == nil {
+			return denied(log, codes.Unauthenticated, "api keys are not accepted")
+		}
+		tok, err := cfg.Source.Token(ctx, key)
+		if err != nil {
+			code := codeFromError(err)
+			if code == codes.Unavailable {
+				log.WithError(err).Error("unable to exchange api key")
+			}
+			return denied(log, code, err.Error())
+		}
+		raw, ok = tok.AccessToken, true
+		inject = append(inject, header("authorization", "Bearer "+raw))
+	}
+	if !ok {
+		return denied(log, codes.Unauthenticated, auth.ErrNoToken.Error())
+	}
+
+	ident, err := cfg.Verifier.Verify(ctx, raw)
+	if err != nil {
+		return denied(log, codes.Unauthenticated, err.Error())
+	}
+	if rule != nil && len(rule.Tenants) > 0 && !zenkit.StringInSlice(ident.Tenant(), rule.Tenants) {
+		return denied(log, codes.PermissionDenied, "tenant not allowed")
+	}
+	grpc_ctxtags.Extract(ctx).
+		Set(zenkit.LogTenantField, ident.Tenant()).
+		Set(zenkit.LogUserField, ident.ID())
+	inject = append(inject,
+		header(TenantHeader, ident.Tenant()),
+		header(UserHeader, ident.ID()),
+	)
+	return allowed(inject)
+}
+
+func (c *Config) match(path string) *Rule {
+	for i := range c.Rules {
+		if c.Rules[i].matches(path) {
+			return &c.Rules[i]
+		}
+	}
+	return nil
+}
+
+// codeFromError maps an API key exchange error to a gRPC code, following
+// apikey.StatusFromError.
+func codeFromError(err error) codes.Code {
+	switch errors.Cause(err) {
+	case apikey.ErrNoKey:
+		return codes.Unauthenticated
+	case apikey.ErrInvalidKey:
+		return codes.PermissionDenied
+	}
+	return codes.Unavailable
+}
+
+func allowed(headers []*pb.HeaderValueOption) *pb.CheckResponse {
+	return &pb.CheckResponse{
+		Status: &rpcstatus.Status{Code: int32(codes.OK)},
+		HttpResponse: &pb.CheckResponse_OkResponse{
+			OkResponse: &pb.OkHttpResponse{Headers: headers},
+		},
+	}
+}
+
+func denied(log *logrus.Entry, code codes.Code, msg string) *pb.CheckResponse {
+	log.WithFields(logrus.Fields{
+		"code":   code,
+		"reason": msg,
+	}).Debug("denied request")
+	httpCode := http.StatusForbidden
+	switch code {
+	case codes.Unauthenticated:
+		httpCode = http.StatusUnauthorized
+	case codes.Unavailable:
+		httpCode = http.StatusBadGateway
+	}
+	body, _ := json.Marshal(apikey.Response{Error: msg})
+	return &pb.CheckResponse{
+		Status: &rpcstatus.Status{Code: int32(code), Message: msg},
+		HttpResponse: &pb.CheckResponse_DeniedResponse{
+			DeniedResponse: &pb.DeniedHttpResponse{
+				Status:  &pb.HttpStatus{Code: int32(httpCode)},
+				Headers: []*pb.HeaderValueOption{header("content-type", "application/json")},
+				Body:    string(body),
+			},
+		},
+	}
+}
+
+func header(key, value string) *pb.HeaderValueOption {
+	return &pb.HeaderValueOption{Header: &pb.HeaderValue{Key: key, Value: value}}
+}
@@ -0,0 +1,220 @@
+// Package fault makes the server misbehave on request, to exercise Istio's
+// retry, timeout and outlier detection policies: calls carrying the control
+// headers are delayed, aborted, reset part way through a stream or made to
+// panic. Faults only apply when the Config enables them, and only those it
+// allows.
+package fault
+
+import (
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/zenoss/grpctest/grpcutil"
+	"google.golang.org/grpc/codes"
+)
+
+// The control headers, and gRPC metadata keys.
+const (
+	// HeaderDelay delays the call by a duration such as 250ms.
+	HeaderDelay = "x-grpctest-delay"
+	// HeaderAbortCode fails the call with a gRPC code, by name or number,
+	// or an HTTP status of 400 and up.
+	HeaderAbortCode = "x-grpctest-abort-code"
+	// HeaderFailPercent is the share of calls, 0 to 100, the fault applies
+	// to; by default the config's maximum.
+	HeaderFailPercent = "x-grpctest-fail-percent"
+	// HeaderResetAfter resets streams once that many messages, or HTTP
+	// writes, have been sent.
+	HeaderResetAfter = "x-grpctest-reset-after"
+	// HeaderPanic panics in the handler when true; the panic is recovered
+	// into INTERNAL, or a 500 over HTTP.
+	HeaderPanic = "x-grpctest-panic"
+)
+
+// ErrNotAllowed is the cause of the errors for faults the Config doesn't
+// allow.
+var ErrNotAllowed = errors.New("not allowed")
+
+// Headers are the control headers.
+var Headers = []string{HeaderDelay, HeaderAbortCode, HeaderFailPercent, HeaderResetAfter, HeaderPanic}
+
+// Kind is a fault that can be allowed.
+type Kind string
+
+const (
+	Delay Kind = "delay"
+	Abort Kind = "abort"
+	Reset Kind = "reset"
+	Panic Kind = "panic"
+)
+
+// Config guards fault injection, which is off by default.
+type Config struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Allow lists the kinds of faults callers may ask for.
+	Allow []Kind `mapstructure:"allow"`
+	// MaxDelay and MaxPercent bound what callers may ask for.
+	MaxDelay   time.Duration `mapstructure:"max_delay"`
+	MaxPercent float64       `mapstructure:"max_percent"`
+}
+
+// Validate checks the allowed kinds and bounds.
+func (c Config) Validate() error {
+	for _, k := range c.Allow {
+		switch k {
+		case Delay, Abort, Reset, Panic:
+		default:
+			return errors.Errorf("unknown fault %q", k)
+		}
+	}
+	if c.MaxDelay < 0 {
+		return errors.New("max_delay must not be negative")
+	}
+	if c.MaxPercent < 0 || c.MaxPercent > 100 {
+		return errors.New("max_percent must be between 0 and 100")
+	}
+	return nil
+}
+
+func (c Config) allows(k Kind) bool {
+	for _, a := range c.Allow {
+		if a == k {
+			return true
+		}
+	}
+	return false
+}
+
+// Fault is what a call asked for.
+type Fault struct {
+	Delay time.Duration
+	// Code is the gRPC code to abort with, and HTTPStatus the status; one
+	// is derived from the other. Both are zero when not aborting.
+	Code       codes.Code
+	HTTPStatus int
+	// ResetAfter is the number of messages sent before a reset, or -1.
+	ResetAfter int
+	Panic      bool
+	Percent    float64
+}
+
+// Parse reads the fault a call's headers ask for from get, which returns a
+// header's value or "". It returns nil when fault injection is disabled or
+// no fault was asked for; asking for a fault the config doesn't allow, or
+// one beyond its bounds, is an error.
+func Parse(cfg Config, get func(string) string) (*Fault, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	f := &Fault{ResetAfter: -1, Percent: cfg.MaxPercent}
+	asked := false
+	if v := get(HeaderDelay); v != "" {
+		if !cfg.allows(Delay) {
+			return nil, errNotAllowed(Delay)
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return nil, errors.Errorf("%s %q is not a duration", HeaderDelay, v)
+		}
+		if d > cfg.MaxDelay {
+			return nil, errors.Errorf("%s %s is over the maximum of %s", HeaderDelay, d, cfg.MaxDelay)
+		}
+		f.Delay, asked = d, true
+	}
+	if v := get(HeaderAbortCode); v != "" {
+		if !cfg.allows(Abort) {
+			return nil, errNotAllowed(Abort)
+		}
+		code, status, err := ParseCode(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s", HeaderAbortCode)
+		}
+		f.Code, f.HTTPStatus, asked = code, status, true
+	}
+	if v := get(HeaderResetAfter); v != "" {
+		if !cfg.allows(Reset) {
+			return nil, errNotAllowed(Reset)
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, errors.Errorf("%s %q is not a number of messages", HeaderResetAfter, v)
+		}
+		f.ResetAfter, asked = n, true
+	}
+	if v := get(HeaderPanic); v != "" {
+		p, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, errors.Errorf("%s %q is not true or false", HeaderPanic, v)
+		}
+		if p && !cfg.allows(Panic) {
+			return nil, errNotAllowed(Panic)
+		}
+		f.Panic, asked = p, asked || p
+	}
+	if !asked {
+		return nil, nil
+	}
+	if v := get(HeaderFailPercent); v != "" {
+		p, err := strconv.ParseFloat(v, 64)
+		if err != nil || p < 0 || p > 100 {
+			return nil, errors.Errorf("%s %q is not between 0 and 100", HeaderFailPercent, v)
+		}
+		if p > cfg.MaxPercent {
+			return nil, errors.Errorf("%s %g is over the maximum of %g", HeaderFailPercent, p, cfg.MaxPercent)
+		}
+		f.Percent = p
+	}
+	if f.ResetAfter >= 0 && f.Code == codes.OK {
+		// A reset looks like this to a gRPC client.
+		f.Code, f.HTTPStatus = codes.Unavailable, grpcutil.HTTPStatusFromCode(codes.Unavailable)
+	}
+	return f, nil
+}
+
+func errNotAllowed(k Kind) error {
+	return errors.Wrapf(ErrNotAllowed, "fault %s", k)
+}
+
+// Applies draws whether this call gets the fault.
+func (f *Fault) Applies() bool {
+	return f.Percent >= 100 || rand.Float64()*100 < f.Percent
+}
+
+// ParseCode reads a gRPC code, by name or number, or an HTTP error status,
+// returning the code and the status, each derived from the other.
+func ParseCode(v string) (codes.Code, int, error) {
+	n, err := strconv.Atoi(v)
+	switch {
+	case err != nil:
+		name := strings.ToUpper(v)
+		if name == "CANCELLED" {
+			// The spec's spelling.
+			name = "CANCELED"
+		}
+		for c := codes.Canceled; c <= codes.Unauthenticated; c++ {
+			if codeName(c) == name {
+				return c, grpcutil.HTTPStatusFromCode(c), nil
+			}
+		}
+	case n > int(codes.OK) && n <= int(codes.Unauthenticated):
+		return codes.Code(n), grpcutil.HTTPStatusFromCode(codes.Code(n)), nil
+	case n >= 400 && n <= 599:
+		return grpcutil.CodeFromHTTPStatus(n), n, nil
+	}
+	return codes.OK, 0, errors.Errorf("%q is neither a gRPC code other than OK nor an HTTP error status", v)
+}
+
+// codeName is the code's name as in the gRPC spec, e.g. DEADLINE_EXCEEDED.
+func codeName(c codes.Code) string {
+	var b strings.Builder
+	for i, r := range c.String() {
+		if i > 0 && 'A' <= r && r <= 'Z' {
+			b.WriteByte('_')
+		}
+		b.WriteRune(r)
+	}
+	return strings.ToUpper(b.String())
+}
@@ -0,0 +1,138 @@
+package fault
+
+import (
+	"context"
+	"time"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	"github.com/pkg/errors"
+	"github.com/zenoss/grpctest/grpcutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// fromContext is the fault the call's metadata asks for, if it applies to
+// this call. A fault that can't be had is an InvalidArgument or, when not
+// allowed, a PermissionDenied status.
+func fromContext(ctx context.Context, cfg Config) (*Fault, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	f, err := Parse(cfg, func(key string) string {
+		if values := md[key]; len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	})
+	if err != nil {
+		if errors.Cause(err) == ErrNotAllowed {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if f == nil || !f.Applies() {
+		return nil, nil
+	}
+	tags := grpc_ctxtags.Extract(ctx)
+	if f.Delay > 0 {
+		tags.Set("fault.delay", f.Delay.String())
+	}
+	if f.Code != codes.OK {
+		tags.Set("fault.code", f.Code.String())
+	}
+	if f.ResetAfter >= 0 {
+		tags.Set("fault.reset_after", f.ResetAfter)
+	}
+	if f.Panic {
+		tags.Set("fault.panic", true)
+	}
+	return f, nil
+}
+
+// start delays the call, then panics or aborts it as asked. Streams that
+// are to be reset are aborted later, by resetStream.
+func (f *Fault) start(ctx context.Context, streaming bool) error {
+	if f.Delay > 0 {
+		t := time.NewTimer(f.Delay)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+	if f.Panic {
+		panic("fault: injected panic")
+	}
+	if f.Code != codes.OK && !(streaming && f.ResetAfter >= 0) {
+		return f.err()
+	}
+	return nil
+}
+
+func (f *Fault) err() error {
+	return status.Errorf(f.Code, "fault: injected %s", f.Code)
+}
+
+// UnaryServerInterceptor injects the faults the calls of services ask for;
+// other calls, such as health checks and Envoy's, are left alone. It reads
+// the Config from config on every call, so changes apply at once. Chain it
+// after grpc_recovery, which turns injected panics into INTERNAL.
+func UnaryServerInterceptor(config func() Config, services ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !grpcutil.InServices(info.FullMethod, services) {
+			return handler(ctx, req)
+		}
+		f, err := fromContext(ctx, config())
+		if err != nil {
+			return nil, err
+		}
+		if f != nil {
+			// A unary call has nothing to reset part way.
+			if err := f.start(ctx, false); err != nil {
+				return nil, err
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is UnaryServerInterceptor for streams, which it
+// can also reset once they have sent some messages.
+func StreamServerInterceptor(config func() Config, services ...string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !grpcutil.InServices(info.FullMethod, services) {
+			return handler(srv, ss)
+		}
+		f, err := fromContext(ss.Context(), config())
+		if err != nil {
+			return err
+		}
+		if f == nil {
+			return handler(srv, ss)
+		}
+		if err := f.start(ss.Context(), true); err != nil {
+			return err
+		}
+		if f.ResetAfter >= 0 {
+			ss = &resetStream{ServerStream: ss, left: f.ResetAfter, err: f.err()}
+		}
+		return handler(srv, ss)
+	}
+}
+
+// resetStream fails the sends after the first left, ending the call with
+// err as handlers return it.
+type resetStream struct {
+	grpc.ServerStream
+	left int
+	err  error
+}
+
+func (s *resetStream) SendMsg(m interface{}) error {
+	if s.left == 0 {
+		return s.err
+	}
+	s.left--
+	return s.ServerStream.SendMsg(m)
+}
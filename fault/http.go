@@ -0,0 +1,96 @@
+package fault
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/logrus/ctxlogrus"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+)
+
+// Middleware injects the faults HTTP requests ask for, reading the Config
+// from config on every request. The control headers are removed before
+// next sees the request, so the gateway doesn't inject them again. Aborts
+// answer with the HTTP status; a panic is left to the server's recovery,
+// accesslog.Recover, which answers with a 500; a reset aborts the response
+// after that many writes, which Envoy reports as an upstream reset.
+func Middleware(config func() Config, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, err := Parse(config(), r.Header.Get)
+		for _, h := range Headers {
+			r.Header.Del(h)
+		}
+		if err != nil {
+			code := http.StatusBadRequest
+			if errors.Cause(err) == ErrNotAllowed {
+				code = http.StatusForbidden
+			}
+			http.Error(w, err.Error(), code)
+			return
+		}
+		if f == nil || !f.Applies() {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx := r.Context()
+		fields := logrus.Fields{}
+		if f.Delay > 0 {
+			fields["fault.delay"] = f.Delay.String()
+		}
+		if f.Code != codes.OK && f.ResetAfter < 0 {
+			fields["fault.code"] = f.HTTPStatus
+		}
+		if f.ResetAfter >= 0 {
+			fields["fault.reset_after"] = f.ResetAfter
+		}
+		if f.Panic {
+			fields["fault.panic"] = true
+		}
+		ctxlogrus.AddFields(ctx, fields)
+
+		if f.Delay > 0 {
+			t := time.NewTimer(f.Delay)
+			defer t.Stop()
+			select {
+			case <-t.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+		switch {
+		case f.Panic:
+			panic("fault: injected panic")
+		case f.ResetAfter >= 0:
+			next.ServeHTTP(&resetWriter{ResponseWriter: w, r: r, left: f.ResetAfter}, r)
+		case f.Code != codes.OK:
+			http.Error(w, "fault: injected abort", f.HTTPStatus)
+		default:
+			next.ServeHTTP(w, r)
+		}
+	})
+}
+
+// resetWriter aborts the response on the write after the first left.
+type resetWriter struct {
+	http.ResponseWriter
+	r    *http.Request
+	left int
+}
+
+func (w *resetWriter) Write(b []byte) (int, error) {
+	if w.left == 0 {
+		// The line logged once the request is answered never is.
+		ctxlogrus.Extract(w.r.Context()).Warn("fault: resetting the response")
+		panic(http.ErrAbortHandler)
+	}
+	w.left--
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *resetWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
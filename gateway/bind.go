@@ -0,0 +1,271 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errBodyTooLarge is the cause of the errors for bodies over the limit.
+var errBodyTooLarge = errors.New("request body too large")
+
+// bind decodes the request message from the body, path variables and query
+// parameters.
+func (rt *route) bind(r *http.Request, vars map[string]string) (proto.Message, error) {
+	raw, err := rt.readBody(r)
+	if err != nil {
+		return nil, err
+	}
+	return rt.message(raw, vars, r.URL.Query())
+}
+
+// bindStream decodes the messages of a client stream. The body is a JSON
+// array holding the body of each message in order, and the path variables
+// and query parameters are bound into every one of them. A route without a
+// body sends a single message.
+func (rt *route) bindStream(r *http.Request, vars map[string]string) ([]proto.Message, error) {
+	raw, err := rt.readBody(r)
+	if err != nil {
+		return nil, err
+	}
+	if rt.body == "" {
+		msg, err := rt.message(nil, vars, r.URL.Query())
+		if err != nil {
+			return nil, err
+		}
+		return []proto.Message{msg}, nil
+	}
+	var elems []json.RawMessage
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &elems); err != nil {
+			return nil, errors.Wrap(err, "request body is not a JSON array")
+		}
+	}
+	msgs := make([]proto.Message, 0, len(elems))
+	for i, elem := range elems {
+		msg, err := rt.message(elem, vars, r.URL.Query())
+		if err != nil {
+			return nil, errors.Wrapf(err, "message %d", i)
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs, nil
+}
+
+func (rt *route) readBody(r *http.Request) ([]byte, error) {
+	if rt.body == "" {
+		return nil, nil
+	}
+	raw, err := ioutil.ReadAll(r.Body)
+	if tooLarge, ok := err.(*http.MaxBytesError); ok {
+		return nil, errors.Wrapf(errBodyTooLarge, "over %d bytes", tooLarge.Limit)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read request body")
+	}
+	return bytes.TrimSpace(raw), nil
+}
+
+// writeBindError answers a request that couldn't be bound with
+// INVALID_ARGUMENT, as a 413 when its body was too large.
+func (g *Gateway) writeBindError(w http.ResponseWriter, err error) {
+	st := status.New(codes.InvalidArgument, err.Error())
+	if errors.Cause(err) == errBodyTooLarge {
+		writeError(w, st, http.StatusRequestEntityTooLarge)
+		return
+	}
+	g.writeStatus(w, st)
+}
+
+// message builds one request message. The body, path variables and query
+// parameters are assembled into one JSON object keyed by proto field name
+// and handed to jsonpb, so conversions follow the proto3 JSON mapping.
+func (rt *route) message(raw []byte, vars map[string]string, query url.Values) (proto.Message, error) {
+	obj := make(map[string]interface{})
+
+	if rt.body != "" && len(raw) > 0 {
+		if rt.body == "*" {
+			if err := json.Unmarshal(raw, &obj); err != nil {
+				return nil, errors.Wrap(err, "request body is not a JSON object")
+			}
+		} else {
+			var field json.RawMessage
+			if err := json.Unmarshal(raw, &field); err != nil {
+				return nil, errors.Wrap(err, "request body is not valid JSON")
+			}
+			if err := rt.set(obj, rt.body, field); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for name, value := range vars {
+		if err := rt.setString(obj, name, []string{value}); err != nil {
+			return nil, err
+		}
+	}
+
+	// With body "*" every field is already in the body, so the query string
+	// can't bind anything.
+	if rt.body != "*" {
+		for name, values := range query {
+			if _, ok := vars[name]; ok || name == rt.body {
+				continue
+			}
+			if err := rt.setString(obj, name, values); err != nil {
+				if errors.Cause(err) == errUnknownField {
+					continue
+				}
+				return nil, err
+			}
+		}
+	}
+
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to encode request")
+	}
+	msg := rt.rpc.newInput()
+	if err := jsonpb.Unmarshal(bytes.NewReader(raw), msg); err != nil {
+		return nil, errors.Wrap(err, "unable to decode request")
+	}
+	return msg, nil
+}
+
+var errUnknownField = errors.New("unknown field")
+
+// field resolves a dotted field path against the input message.
+func (rt *route) field(path string) ([]string, *descriptor.FieldDescriptorProto, error) {
+	names := strings.Split(path, ".")
+	msg := rt.rpc.input
+	var fd *descriptor.FieldDescriptorProto
+	for i, name := range names {
+		if msg == nil {
+			return nil, nil, errors.Wrapf(errUnknownField, "%s is not a message", strings.Join(names[:i], "."))
+		}
+		fd = findField(msg, name)
+		if fd == nil {
+			return nil, nil, errors.Wrapf(errUnknownField, "%s", path)
+		}
+		names[i] = fd.GetName()
+		msg = nil
+		if fd.GetType() == descriptor.FieldDescriptorProto_TYPE_MESSAGE {
+			msg = rt.rpc.messages.lookup(fd.GetTypeName())
+		}
+	}
+	return names, fd, nil
+}
+
+func findField(msg *descriptor.DescriptorProto, name string) *descriptor.FieldDescriptorProto {
+	for _, fd := range msg.Field {
+		if fd.GetName() == name || fd.GetJsonName() == name {
+			return fd
+		}
+	}
+	return nil
+}
+
+func (rt *route) set(obj map[string]interface{}, path string, value interface{}) error {
+	names, _, err := rt.field(path)
+	if err != nil {
+		return err
+	}
+	put(obj, names, value)
+	return nil
+}
+
+func (rt *route) setString(obj map[string]interface{}, path string, values []string) error {
+	names, fd, err := rt.field(path)
+	if err != nil {
+		return err
+	}
+	repeated := fd.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REPEATED
+	if !repeated && len(values) > 1 {
+		return errors.Errorf("%s does not accept multiple values", path)
+	}
+	converted := make([]interface{}, len(values))
+	for i, v := range values {
+		if converted[i], err = convert(fd, v); err != nil {
+			return errors.Wrapf(err, "bad value for %s", path)
+		}
+	}
+	if repeated {
+		put(obj, names, converted)
+	} else {
+		put(obj, names, converted[0])
+	}
+	return nil
+}
+
+func put(obj map[string]interface{}, names []string, value interface{}) {
+	for _, name := range names[:len(names)-1] {
+		child, ok := obj[name].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			obj[name] = child
+		}
+		obj = child
+	}
+	obj[names[len(names)-1]] = value
+}
+
+// convert turns a string from the path or query into the JSON value jsonpb
+// expects for the field type.
+func convert(fd *descriptor.FieldDescriptorProto, s string) (interface{}, error) {
+	switch fd.GetType() {
+	case descriptor.FieldDescriptorProto_TYPE_DOUBLE, descriptor.FieldDescriptorProto_TYPE_FLOAT:
+		switch s {
+		case "NaN", "Infinity", "-Infinity":
+			return s, nil
+		}
+		if _, err := strconv.ParseFloat(s, 64); err != nil {
+			return nil, err
+		}
+		return json.Number(s), nil
+	case descriptor.FieldDescriptorProto_TYPE_INT32, descriptor.FieldDescriptorProto_TYPE_SINT32,
+		descriptor.FieldDescriptorProto_TYPE_SFIXED32:
+		if _, err := strconv.ParseInt(s, 10, 32); err != nil {
+			return nil, err
+		}
+		return json.Number(s), nil
+	case descriptor.FieldDescriptorProto_TYPE_UINT32, descriptor.FieldDescriptorProto_TYPE_FIXED32:
+		if _, err := strconv.ParseUint(s, 10, 32); err != nil {
+			return nil, err
+		}
+		return json.Number(s), nil
+	case descriptor.FieldDescriptorProto_TYPE_INT64, descriptor.FieldDescriptorProto_TYPE_SINT64,
+		descriptor.FieldDescriptorProto_TYPE_SFIXED64:
+		if _, err := strconv.ParseInt(s, 10, 64); err != nil {
+			return nil, err
+		}
+		// 64-bit integers are strings in proto3 JSON
+		return s, nil
+	case descriptor.FieldDescriptorProto_TYPE_UINT64, descriptor.FieldDescriptorProto_TYPE_FIXED64:
+		if _, err := strconv.ParseUint(s, 10, 64); err != nil {
+			return nil, err
+		}
+		return s, nil
+	case descriptor.FieldDescriptorProto_TYPE_BOOL:
+		return strconv.ParseBool(s)
+	case descriptor.FieldDescriptorProto_TYPE_ENUM:
+		if _, err := strconv.ParseInt(s, 10, 32); err == nil {
+			return json.Number(s), nil
+		}
+		return s, nil
+	default:
+		// Strings, bytes (base64) and well-known message types such as
+		// Timestamp and Duration all take their JSON string form.
+		return s, nil
+	}
+}
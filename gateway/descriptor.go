@@ -0,0 +1,240 @@
+package gateway
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"github.com/pkg/errors"
+	"github.com/zenoss/zenkit"
+	"google.golang.org/genproto/googleapis/api/annotations"
+)
+
+// ReadDescriptorSet reads a FileDescriptorSet as written by
+// protoc --descriptor_set_out, like the one Envoy's transcoder loads.
+func ReadDescriptorSet(path string) (*descriptor.FileDescriptorSet, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to read descriptor set")
+	}
+	set := &descriptor.FileDescriptorSet{}
+	if err := proto.Unmarshal(b, set); err != nil {
+		return nil, errors.Wrap(err, "unable to parse descriptor set")
+	}
+	return set, nil
+}
+
+// EmbeddedDescriptorSet builds a FileDescriptorSet from the descriptors the
+// generated code registered with the proto package, including everything
+// the named files import.
+func EmbeddedDescriptorSet(filenames ...string) (*descriptor.FileDescriptorSet, error) {
+	set := &descriptor.FileDescriptorSet{}
+	seen := make(map[string]bool)
+	var add func(name string) error
+	add = func(name string) error {
+		if seen[name] {
+			return nil
+		}
+		seen[name] = true
+		gz := proto.FileDescriptor(name)
+		if gz == nil {
+			return errors.Errorf("no descriptor registered for %s", name)
+		}
+		r, err := gzip.NewReader(bytes.NewReader(gz))
+		if err != nil {
+			return errors.Wrapf(err, "unable to decompress descriptor for %s", name)
+		}
+		b, err := ioutil.ReadAll(r)
+		if err != nil {
+			return errors.Wrapf(err, "unable to decompress descriptor for %s", name)
+		}
+		fd := &descriptor.FileDescriptorProto{}
+		if err := proto.Unmarshal(b, fd); err != nil {
+			return errors.Wrapf(err, "unable to parse descriptor for %s", name)
+		}
+		for _, dep := range fd.Dependency {
+			if err := add(dep); err != nil {
+				return err
+			}
+		}
+		set.File = append(set.File, fd)
+		return nil
+	}
+	for _, name := range filenames {
+		if err := add(name); err != nil {
+			return nil, err
+		}
+	}
+	return set, nil
+}
+
+// messages indexes every message in a descriptor set by its fully qualified
+// name, without the leading dot.
+type messages map[string]*descriptor.DescriptorProto
+
+func indexMessages(set *descriptor.FileDescriptorSet) messages {
+	idx := make(messages)
+	var add func(prefix string, msgs []*descriptor.DescriptorProto)
+	add = func(prefix string, msgs []*descriptor.DescriptorProto) {
+		for _, m := range msgs {
+			name := prefix + m.GetName()
+			idx[name] = m
+			add(name+".", m.NestedType)
+		}
+	}
+	for _, f := range set.File {
+		prefix := ""
+		if f.GetPackage() != "" {
+			prefix = f.GetPackage() + "."
+		}
+		add(prefix, f.MessageType)
+	}
+	return idx
+}
+
+func (m messages) lookup(typeName string) *descriptor.DescriptorProto {
+	return m[strings.TrimPrefix(typeName, ".")]
+}
+
+// buildRoutes turns the HttpRule annotations on every method into routes.
+// Methods whose message types aren't compiled into the binary are skipped,
+// since there is nothing to decode them into.
+func buildRoutes(set *descriptor.FileDescriptorSet, cfg Config) ([]*route, []string, error) {
+	msgs := indexMessages(set)
+	var (
+		routes  []*route
+		skipped []string
+	)
+	for _, f := range set.File {
+		for _, svc := range f.Service {
+			svcName := svc.GetName()
+			if f.GetPackage() != "" {
+				svcName = f.GetPackage() + "." + svcName
+			}
+			if len(cfg.Services) > 0 && !zenkit.StringInSlice(svcName, cfg.Services) {
+				continue
+			}
+			for _, m := range svc.Method {
+				fullMethod := "/" + svcName + "/" + m.GetName()
+				rpc, err := newMethod(fullMethod, m, msgs)
+				if err != nil {
+					skipped = append(skipped, fullMethod)
+					continue
+				}
+
+				var rules []*annotations.HttpRule
+				if m.Options != nil && proto.HasExtension(m.Options, annotations.E_Http) {
+					ext, err := proto.GetExtension(m.Options, annotations.E_Http)
+					if err != nil {
+						return nil, nil, errors.Wrapf(err, "unable to read http rule for %s", fullMethod)
+					}
+					rule := ext.(*annotations.HttpRule)
+					rules = append(rules, rule)
+					rules = append(rules, rule.AdditionalBindings...)
+				}
+				if cfg.AutoMapping {
+					rules = append(rules, &annotations.HttpRule{
+						Pattern: &annotations.HttpRule_Post{Post: fullMethod},
+						Body:    "*",
+					})
+				}
+
+				for _, rule := range rules {
+					r, err := newRoute(rpc, rule)
+					if err != nil {
+						return nil, nil, errors.Wrapf(err, "bad http rule for %s", fullMethod)
+					}
+					routes = append(routes, r)
+				}
+			}
+		}
+	}
+	return routes, skipped, nil
+}
+
+// method is a gRPC method the gateway can call, with the Go types of its
+// messages.
+type method struct {
+	fullMethod      string
+	clientStreaming bool
+	serverStreaming bool
+	input           *descriptor.DescriptorProto
+	inputType       reflect.Type
+	outputType      reflect.Type
+	messages        messages
+}
+
+// newMethod describes the method m of fullMethod, failing when its
+// message types aren't registered.
+func newMethod(fullMethod string, m *descriptor.MethodDescriptorProto, msgs messages) (*method, error) {
+	in := msgs.lookup(m.GetInputType())
+	if in == nil {
+		return nil, errors.Errorf("no descriptor for %s", m.GetInputType())
+	}
+	inType := proto.MessageType(strings.TrimPrefix(m.GetInputType(), "."))
+	outType := proto.MessageType(strings.TrimPrefix(m.GetOutputType(), "."))
+	if inType == nil || outType == nil {
+		return nil, errors.Errorf("message types for %s are not registered", fullMethod)
+	}
+	return &method{
+		fullMethod:      fullMethod,
+		clientStreaming: m.GetClientStreaming(),
+		serverStreaming: m.GetServerStreaming(),
+		input:           in,
+		inputType:       inType,
+		outputType:      outType,
+		messages:        msgs,
+	}, nil
+}
+
+func (m *method) newInput() proto.Message {
+	return reflect.New(m.inputType.Elem()).Interface().(proto.Message)
+}
+
+func (m *method) newOutput() proto.Message {
+	return reflect.New(m.outputType.Elem()).Interface().(proto.Message)
+}
+
+type route struct {
+	httpMethod   string
+	tmpl         *template
+	body         string
+	responseBody string
+	rpc          *method
+}
+
+func newRoute(rpc *method, rule *annotations.HttpRule) (*route, error) {
+	var httpMethod, path string
+	switch p := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		httpMethod, path = http.MethodGet, p.Get
+	case *annotations.HttpRule_Put:
+		httpMethod, path = http.MethodPut, p.Put
+	case *annotations.HttpRule_Post:
+		httpMethod, path = http.MethodPost, p.Post
+	case *annotations.HttpRule_Delete:
+		httpMethod, path = http.MethodDelete, p.Delete
+	case *annotations.HttpRule_Patch:
+		httpMethod, path = http.MethodPatch, p.Patch
+	case *annotations.HttpRule_Custom:
+		httpMethod, path = strings.ToUpper(p.Custom.GetKind()), p.Custom.GetPath()
+	default:
+		return nil, errors.New("http rule has no pattern")
+	}
+	tmpl, err := parseTemplate(path)
+	if err != nil {
+		return nil, err
+	}
+	return &route{
+		httpMethod:   httpMethod,
+		tmpl:         tmpl,
+		body:         rule.GetBody(),
+		responseBody: rule.GetResponseBody(),
+		rpc:          rpc,
+	}, nil
+}
@@ -0,0 +1,290 @@
+// Package gateway transcodes REST calls into gRPC calls using the
+// google.api.http annotations in the service descriptors, the same mapping
+// Envoy's envoy.grpc_json_transcoder filter applies.
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/textproto"
+	"strings"
+	"sync"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"github.com/sirupsen/logrus"
+	"github.com/zenoss/grpctest/grpcutil"
+	"go.opencensus.io/plugin/ochttp"
+	// Registers the standard error details so errorBody can render them.
+	_ "google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// The prefixes of the gRPC response headers and trailers copied onto the
+// HTTP response, as grpc-gateway names them, so metadata a server sends in
+// both, such as x-request-id, isn't sent twice under one name.
+const (
+	MetadataHeaderPrefix  = "Grpc-Metadata-"
+	MetadataTrailerPrefix = "Grpc-Trailer-"
+)
+
+// Config configures which methods a Gateway exposes and how.
+type Config struct {
+	// Services limits transcoding to these fully qualified service names,
+	// like the services list of the Envoy filter. Empty exposes them all.
+	Services []string
+	// AutoMapping additionally maps every method to
+	// POST /<service>/<method> with the whole message as the body.
+	AutoMapping bool
+	// Marshaler renders responses. Nil uses the jsonpb defaults, which match
+	// the Envoy transcoder's defaults.
+	Marshaler *jsonpb.Marshaler
+	// MaxBodySize bounds request bodies, in bytes; 0 means
+	// DefaultMaxBodySize.
+	MaxBodySize int64
+}
+
+// DefaultMaxBodySize is the gRPC server's default maximum received message
+// size, which no body bound into one message can usefully exceed.
+const DefaultMaxBodySize = 4 << 20
+
+// Gateway serves the HTTP routes of a descriptor set by calling the gRPC
+// methods on conn.
+type Gateway struct {
+	conn *grpc.ClientConn
+	cfg  Config
+	log  *logrus.Entry
+
+	mu     sync.RWMutex
+	routes []*route
+}
+
+// New creates a Gateway with no routes; call Load to add them.
+func New(conn *grpc.ClientConn, cfg Config, log *logrus.Entry) *Gateway {
+	if cfg.Marshaler == nil {
+		cfg.Marshaler = &jsonpb.Marshaler{}
+	}
+	return &Gateway{conn: conn, cfg: cfg, log: log}
+}
+
+// Load replaces the gateway's routes with those described by set.
+func (g *Gateway) Load(set *descriptor.FileDescriptorSet) error {
+	routes, skipped, err := buildRoutes(set, g.cfg)
+	if err != nil {
+		return err
+	}
+	for _, m := range skipped {
+		g.log.WithField("method", m).Debug("method not transcoded")
+	}
+	for _, rt := range routes {
+		g.log.WithFields(logrus.Fields{
+			"route":  rt.httpMethod + " " + rt.tmpl.raw,
+			"method": rt.rpc.fullMethod,
+		}).Debug("transcoding route")
+	}
+	g.mu.Lock()
+	g.routes = routes
+	g.mu.Unlock()
+	g.log.WithField("routes", len(routes)).Info("loaded transcoding routes")
+	return nil
+}
+
+// Handler serves the transcoded routes, passing any other request to
+// fallback.
+func (g *Gateway) Handler(fallback http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rt, vars, allowed := g.match(r)
+		if rt == nil {
+			if len(allowed) > 0 {
+				w.Header().Set("Allow", strings.Join(allowed, ", "))
+				writeError(w, status.New(codes.Unimplemented, "method not allowed"), http.StatusMethodNotAllowed)
+				return
+			}
+			fallback.ServeHTTP(w, r)
+			return
+		}
+		if rt.rpc.clientStreaming || rt.rpc.serverStreaming {
+			ochttp.SetRoute(r.Context(), "transcode")
+		} else {
+			ochttp.SetRoute(r.Context(), "gateway")
+		}
+		limit := g.cfg.MaxBodySize
+		if limit <= 0 {
+			limit = DefaultMaxBodySize
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, limit)
+		g.serve(w, r, rt, vars)
+	})
+}
+
+// match finds the route for the request. When the path only matches routes
+// for other HTTP methods, those methods are returned as allowed.
+func (g *Gateway) match(r *http.Request) (*route, map[string]string, []string) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	path := r.URL.EscapedPath()
+	var allowed []string
+	for _, rt := range g.routes {
+		vars, ok := rt.tmpl.match(path)
+		if !ok {
+			continue
+		}
+		if rt.httpMethod == r.Method {
+			return rt, vars, nil
+		}
+		allowed = append(allowed, rt.httpMethod)
+	}
+	return nil, nil, allowed
+}
+
+func (g *Gateway) serve(w http.ResponseWriter, r *http.Request, rt *route, vars map[string]string) {
+	if rt.rpc.clientStreaming || rt.rpc.serverStreaming {
+		g.serveStream(w, r, rt, vars)
+		return
+	}
+
+	in, err := rt.bind(r, vars)
+	if err != nil {
+		g.writeBindError(w, err)
+		return
+	}
+
+	ctx := metadata.NewOutgoingContext(r.Context(), incomingMetadata(r))
+	var header, trailer metadata.MD
+	out := rt.rpc.newOutput()
+	err = g.conn.Invoke(ctx, rt.rpc.fullMethod, in, out, grpc.Header(&header), grpc.Trailer(&trailer))
+	copyMetadata(w.Header(), MetadataHeaderPrefix, header)
+	copyMetadata(w.Header(), MetadataTrailerPrefix, trailer)
+	if err != nil {
+		g.writeStatus(w, status.Convert(err))
+		return
+	}
+	g.writeMessage(w, rt, out)
+}
+
+func (g *Gateway) writeMessage(w http.ResponseWriter, rt *route, out proto.Message) {
+	body, err := g.render(rt, out)
+	if err != nil {
+		g.writeStatus(w, status.New(codes.Internal, err.Error()))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// render marshals a response message, or just its response_body field if
+// the rule names one.
+func (g *Gateway) render(rt *route, out proto.Message) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := g.cfg.Marshaler.Marshal(&buf, out); err != nil {
+		return nil, err
+	}
+	body := buf.Bytes()
+	if rt.responseBody == "" {
+		return body, nil
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(body, &obj); err != nil {
+		return nil, err
+	}
+	body = obj[jsonName(rt.responseBody)]
+	if body == nil {
+		body = obj[rt.responseBody]
+	}
+	if body == nil {
+		body = []byte("null")
+	}
+	return body, nil
+}
+
+func (g *Gateway) writeStatus(w http.ResponseWriter, st *status.Status) {
+	writeError(w, st, grpcutil.HTTPStatusFromCode(st.Code()))
+}
+
+// errorBody renders a status as its google.rpc.Status JSON, the code and
+// message plus any details such as a BadRequest's field violations:
+//
+//	{"code": 11, "message": "...", "details": [{"@type": "type.googleapis.com/google.rpc.BadRequest", "fieldViolations": [...]}]}
+//
+// Details whose types aren't linked into the binary are left out.
+func errorBody(st *status.Status) []byte {
+	var buf bytes.Buffer
+	if err := statusMarshaler.Marshal(&buf, st.Proto()); err == nil {
+		return buf.Bytes()
+	}
+	body, _ := json.Marshal(struct {
+		Code    int32  `json:"code"`
+		Message string `json:"message"`
+	}{int32(st.Code()), st.Message()})
+	return body
+}
+
+var statusMarshaler = &jsonpb.Marshaler{}
+
+func writeError(w http.ResponseWriter, st *status.Status, code int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(errorBody(st))
+}
+
+// hopHeaders are not forwarded to the gRPC server as metadata.
+var hopHeaders = map[string]bool{
+	"connection":        true,
+	"content-length":    true,
+	"content-type":      true,
+	"host":              true,
+	"keep-alive":        true,
+	"te":                true,
+	"trailer":           true,
+	"transfer-encoding": true,
+	"upgrade":           true,
+}
+
+func incomingMetadata(r *http.Request) metadata.MD {
+	md := metadata.MD{}
+	for key, values := range r.Header {
+		key = strings.ToLower(key)
+		if hopHeaders[key] || strings.HasPrefix(key, "grpc-") {
+			continue
+		}
+		md[key] = append(md[key], values...)
+	}
+	if r.Host != "" {
+		md.Set("x-forwarded-host", r.Host)
+	}
+	return md
+}
+
+func copyMetadata(h http.Header, prefix string, md metadata.MD) {
+	for key, values := range md {
+		name := textproto.CanonicalMIMEHeaderKey(prefix + key)
+		for _, v := range values {
+			h.Add(name, v)
+		}
+	}
+}
+
+// jsonName converts a proto field name to its lowerCamelCase JSON name.
+func jsonName(name string) string {
+	var b strings.Builder
+	upper := false
+	for _, c := range name {
+		if c == '_' {
+			upper = true
+			continue
+		}
+		if upper {
+			b.WriteString(strings.ToUpper(string(c)))
+			upper = false
+		} else {
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
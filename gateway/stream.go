@@ -0,0 +1,147 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/textproto"
+
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// serveStream transcodes a streaming method the way Envoy's transcoder
+// does. A client stream takes a JSON array with one body per message. A
+// server stream is written as a JSON array, flushed after each message;
+// once the array has started the status can't change, so an error ends it
+// with an {"error": ...} element holding the usual error body.
+func (g *Gateway) serveStream(w http.ResponseWriter, r *http.Request, rt *route, vars map[string]string) {
+	var in []proto.Message
+	if rt.rpc.clientStreaming {
+		msgs, err := rt.bindStream(r, vars)
+		if err != nil {
+			g.writeBindError(w, err)
+			return
+		}
+		in = msgs
+	} else {
+		msg, err := rt.bind(r, vars)
+		if err != nil {
+			g.writeBindError(w, err)
+			return
+		}
+		in = []proto.Message{msg}
+	}
+
+	// Cancelling the context when the handler returns, or when the HTTP
+	// client goes away, cancels the call.
+	ctx, cancel := context.WithCancel(metadata.NewOutgoingContext(r.Context(), incomingMetadata(r)))
+	defer cancel()
+	desc := &grpc.StreamDesc{
+		ServerStreams: rt.rpc.serverStreaming,
+		ClientStreams: rt.rpc.clientStreaming,
+	}
+	cs, err := g.conn.NewStream(ctx, desc, rt.rpc.fullMethod)
+	if err != nil {
+		g.writeStatus(w, status.Convert(err))
+		return
+	}
+
+	// Send from another goroutine, so a bidirectional method can answer
+	// before it has read everything without the two sides blocking on each
+	// other's flow control windows. A failed send shows up in RecvMsg.
+	go func() {
+		for _, msg := range in {
+			if err := cs.SendMsg(msg); err != nil {
+				return
+			}
+		}
+		cs.CloseSend()
+	}()
+
+	if !rt.rpc.serverStreaming {
+		out := rt.rpc.newOutput()
+		err := cs.RecvMsg(out)
+		if header, herr := cs.Header(); herr == nil {
+			copyMetadata(w.Header(), MetadataHeaderPrefix, header)
+		}
+		copyMetadata(w.Header(), MetadataTrailerPrefix, cs.Trailer())
+		if err != nil {
+			g.writeStatus(w, status.Convert(err))
+			return
+		}
+		g.writeMessage(w, rt, out)
+		return
+	}
+
+	flusher, _ := w.(http.Flusher)
+	started := false
+	start := func() {
+		if header, err := cs.Header(); err == nil {
+			copyMetadata(w.Header(), MetadataHeaderPrefix, header)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("["))
+		started = true
+	}
+	fail := func(st *status.Status) {
+		if !started {
+			copyMetadata(w.Header(), MetadataTrailerPrefix, cs.Trailer())
+			g.writeStatus(w, st)
+			return
+		}
+		body, _ := json.Marshal(struct {
+			Error json.RawMessage `json:"error"`
+		}{errorBody(st)})
+		w.Write([]byte(","))
+		w.Write(body)
+		g.finish(w, cs.Trailer())
+	}
+	for i := 0; ; i++ {
+		out := rt.rpc.newOutput()
+		err := cs.RecvMsg(out)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			fail(status.Convert(err))
+			return
+		}
+		body, err := g.render(rt, out)
+		if err != nil {
+			fail(status.New(codes.Internal, err.Error()))
+			return
+		}
+		if !started {
+			start()
+		}
+		if i > 0 {
+			w.Write([]byte(","))
+		}
+		w.Write(body)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	if !started {
+		start()
+	}
+	g.finish(w, cs.Trailer())
+}
+
+// finish closes a streamed array, sending the gRPC trailers as HTTP
+// trailers since the headers are long gone.
+func (g *Gateway) finish(w http.ResponseWriter, trailer metadata.MD) {
+	w.Write([]byte("]"))
+	for key, values := range trailer {
+		name := http.TrailerPrefix + textproto.CanonicalMIMEHeaderKey(MetadataTrailerPrefix+key)
+		for _, v := range values {
+			w.Header().Add(name, v)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package gateway
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// A path template as defined by google.api.HttpRule:
+//
+//	Template = "/" Segments [ Verb ] ;
+//	Segments = Segment { "/" Segment } ;
+//	Segment  = "*" | "**" | LITERAL | Variable ;
+//	Variable = "{" FieldPath [ "=" Segments ] "}" ;
+//	FieldPath = IDENT { "." IDENT } ;
+//	Verb     = ":" LITERAL ;
+type template struct {
+	raw  string
+	ops  []op
+	vars []string
+	verb string
+}
+
+type opKind int
+
+const (
+	opLiteral opKind = iota
+	opSingle
+	opMulti
+)
+
+type op struct {
+	kind    opKind
+	literal string
+	// variable is the index into template.vars that captures this segment,
+	// or -1.
+	variable int
+}
+
+var errTemplate = errors.New("invalid path template")
+
+func parseTemplate(raw string) (*template, error) {
+	if !strings.HasPrefix(raw, "/") {
+		return nil, errors.Wrapf(errTemplate, "%q must start with /", raw)
+	}
+	t := &template{raw: raw}
+	path := raw[1:]
+
+	// The verb follows the last colon that isn't inside a variable.
+	if i := strings.LastIndex(path, ":"); i >= 0 && !strings.Contains(path[i:], "}") {
+		t.verb = path[i+1:]
+		path = path[:i]
+	}
+
+	for len(path) > 0 {
+		var seg string
+		if path[0] == '{' {
+			end := strings.Index(path, "}")
+			if end < 0 {
+				return nil, errors.Wrapf(errTemplate, "%q has an unterminated variable", raw)
+			}
+			seg, path = path[:end+1], path[end+1:]
+			if err := t.addVariable(seg[1 : len(seg)-1]); err != nil {
+				return nil, errors.Wrapf(err, "%q", raw)
+			}
+		} else {
+			end := strings.Index(path, "/")
+			if end < 0 {
+				end = len(path)
+			}
+			seg, path = path[:end], path[end:]
+			if err := t.addSegment(seg, -1); err != nil {
+				return nil, errors.Wrapf(err, "%q", raw)
+			}
+		}
+		if strings.HasPrefix(path, "/") {
+			path = path[1:]
+			if path == "" {
+				return nil, errors.Wrapf(errTemplate, "%q has a trailing slash", raw)
+			}
+		} else if path != "" {
+			return nil, errors.Wrapf(errTemplate, "%q has a malformed segment", raw)
+		}
+	}
+
+	for i, o := range t.ops {
+		if o.kind == opMulti && i != len(t.ops)-1 {
+			return nil, errors.Wrapf(errTemplate, "%q may only use ** as the last segment", raw)
+		}
+	}
+	return t, nil
+}
+
+func (t *template) addVariable(body string) error {
+	field, pattern := body, "*"
+	if i := strings.Index(body, "="); i >= 0 {
+		field, pattern = body[:i], body[i+1:]
+	}
+	if field == "" || pattern == "" {
+		return errTemplate
+	}
+	idx := len(t.vars)
+	t.vars = append(t.vars, field)
+	for _, seg := range strings.Split(pattern, "/") {
+		if err := t.addSegment(seg, idx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *template) addSegment(seg string, variable int) error {
+	switch {
+	case seg == "":
+		return errors.Wrap(errTemplate, "empty segment")
+	case seg == "*":
+		t.ops = append(t.ops, op{kind: opSingle, variable: variable})
+	case seg == "**":
+		t.ops = append(t.ops, op{kind: opMulti, variable: variable})
+	case strings.ContainsAny(seg, "{}*="):
+		return errors.Wrapf(errTemplate, "bad segment %q", seg)
+	default:
+		t.ops = append(t.ops, op{kind: opLiteral, literal: seg, variable: variable})
+	}
+	return nil
+}
+
+// match reports whether the escaped request path matches the template, and
+// returns the captured value for each variable.
+func (t *template) match(escapedPath string) (map[string]string, bool) {
+	if !strings.HasPrefix(escapedPath, "/") {
+		return nil, false
+	}
+	path := escapedPath[1:]
+	if t.verb != "" {
+		if !strings.HasSuffix(path, ":"+t.verb) {
+			return nil, false
+		}
+		path = strings.TrimSuffix(path, ":"+t.verb)
+	}
+	var segs []string
+	if path != "" {
+		segs = strings.Split(path, "/")
+	}
+
+	captured := make([][]string, len(t.vars))
+	i := 0
+	for _, o := range t.ops {
+		switch o.kind {
+		case opLiteral:
+			if i >= len(segs) || unescape(segs[i]) != o.literal {
+				return nil, false
+			}
+			if o.variable >= 0 {
+				captured[o.variable] = append(captured[o.variable], o.literal)
+			}
+			i++
+		case opSingle:
+			if i >= len(segs) || segs[i] == "" {
+				return nil, false
+			}
+			if o.variable >= 0 {
+				captured[o.variable] = append(captured[o.variable], unescape(segs[i]))
+			}
+			i++
+		case opMulti:
+			if o.variable >= 0 {
+				// Multi-segment captures keep their slashes escaped, as
+				// they come from the wire.
+				captured[o.variable] = append(captured[o.variable], segs[i:]...)
+			}
+			i = len(segs)
+		}
+	}
+	if i != len(segs) {
+		return nil, false
+	}
+
+	vars := make(map[string]string, len(t.vars))
+	for idx, name := range t.vars {
+		vars[name] = strings.Join(captured[idx], "/")
+	}
+	return vars, true
+}
+
+func unescape(seg string) string {
+	if s, err := url.PathUnescape(seg); err == nil {
+		return s
+	}
+	return seg
+}
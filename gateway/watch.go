@@ -0,0 +1,61 @@
+package gateway
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+)
+
+// LoadFile loads the routes from a descriptor set file.
+func (g *Gateway) LoadFile(path string) error {
+	set, err := ReadDescriptorSet(path)
+	if err != nil {
+		return err
+	}
+	return g.Load(set)
+}
+
+// WatchFile reloads the routes whenever the descriptor set file changes,
+// until ctx is done. The directory is watched rather than the file, so
+// replacing the file or swapping a Kubernetes ConfigMap symlink is seen too.
+// A descriptor that fails to load leaves the current routes in place.
+func (g *Gateway) WatchFile(ctx context.Context, path string) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.Wrap(err, "unable to create descriptor watcher")
+	}
+	path = filepath.Clean(path)
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+		return errors.Wrap(err, "unable to watch descriptor directory")
+	}
+	realPath, _ := filepath.EvalSymlinks(path)
+
+	go func() {
+		defer watcher.Close()
+		log := g.log.WithField("descriptor", path)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case err := <-watcher.Errors:
+				log.WithError(err).Warn("descriptor watcher error")
+			case event := <-watcher.Events:
+				current, _ := filepath.EvalSymlinks(path)
+				changed := filepath.Clean(event.Name) == path && event.Op&(fsnotify.Write|fsnotify.Create) != 0
+				if !changed && (current == "" || current == realPath) {
+					continue
+				}
+				realPath = current
+				if err := g.LoadFile(path); err != nil {
+					log.WithError(err).Error("unable to reload descriptor; keeping previous routes")
+					continue
+				}
+				log.Info("reloaded descriptor")
+			}
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware"
+	"github.com/grpc-ecosystem/go-grpc-middleware/auth"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/logrus"
+	"github.com/grpc-ecosystem/go-grpc-middleware/recovery"
+	"github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"github.com/zenoss/grpctest/accesslog"
+	"github.com/zenoss/grpctest/certs"
+	"github.com/zenoss/grpctest/fault"
+	"github.com/zenoss/grpctest/healthcheck"
+	"github.com/zenoss/grpctest/profile"
+	"github.com/zenoss/grpctest/quota"
+	"github.com/zenoss/grpctest/tracing"
+	"github.com/zenoss/zenkit"
+	"go.opencensus.io/tag"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/reflection"
+)
+
+// runGRPCServer does what zenkit.RunGRPCServerWithHealth does, but with our
+// own server, since zenkit has no way to pass it transport credentials. It
+// serves until ctx is done or SIGTERM or SIGINT arrives, and then shuts
+// down gRPC and httpServer, which main serves, as shutdown describes.
+// grpc_health_v1 is served from monitor on the gRPC port, and mirrored on
+// the plaintext health port for the kubelet.
+func runGRPCServer(ctx context.Context, cfg *Config, creds credentials.TransportCredentials, limiter *quota.Limiter, httpServer *http.Server, monitor *healthcheck.Monitor, log *logrus.Entry, register zenkit.ServiceRegistrationFunc) error {
+	ctx, _ = tag.New(ctx,
+		tag.Upsert(zenkit.KeyServiceLabel, viper.GetString(zenkit.ServiceLabel)),
+	)
+	grpc_logrus.ReplaceGrpcLogger(log)
+	ctx = withTrapSignals(ctx, log)
+
+	health, err := zenkit.RegisterHealthServer(ctx, log)
+	if err != nil {
+		return err
+	}
+	defer health.Shutdown()
+
+	server := newGRPCServer(cfg, creds, limiter, log)
+	if err := register(server); err != nil {
+		return errors.Wrap(err, "unable to register service")
+	}
+	monitor.Register(server)
+	monitor.Watch(func(ready bool) {
+		if ready {
+			health.Serving()
+		} else {
+			health.NotServing()
+		}
+	})
+
+	lis, err := net.Listen("tcp", cfg.GRPC.ListenAddr)
+	if err != nil {
+		return errors.Wrap(err, "unable to start listener")
+	}
+	go server.Serve(lis)
+	log.WithFields(logrus.Fields{
+		"address": cfg.GRPC.ListenAddr,
+		"tls":     creds != nil,
+	}).Info("started server")
+
+	<-ctx.Done()
+	shutdown(currentConfig().Shutdown, server, httpServer, monitor, log)
+	return nil
+}
+
+// withTrapSignals is zenkit.WithTrapSIGINT, but for SIGTERM too, which is
+// what Kubernetes sends.
+func withTrapSignals(ctx context.Context, log *logrus.Entry) context.Context {
+	ctx, cancel := context.WithCancel(ctx)
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-c:
+			log.WithField("signal", sig).Info("signal received")
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(c)
+	}()
+	return ctx
+}
+
+// shutdown reports NOT_SERVING and waits out the drain delay, so Envoy takes
+// the endpoint out of rotation while it still answers, then gives the calls
+// in flight on both servers the timeout to finish before cutting them off.
+func shutdown(settings ShutdownSettings, server *grpc.Server, httpServer *http.Server, monitor *healthcheck.Monitor, log *logrus.Entry) {
+	monitor.Shutdown()
+	log.WithField("drain_delay", settings.DrainDelay).Info("health set to NOT_SERVING; draining")
+	time.Sleep(settings.DrainDelay)
+
+	log.WithField("timeout", settings.Timeout).Info("stopping servers")
+	ctx, cancel := context.WithTimeout(context.Background(), settings.Timeout)
+	defer cancel()
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		stopped := make(chan struct{})
+		go func() {
+			server.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+			log.Info("shut down gRPC server gracefully")
+		case <-ctx.Done():
+			server.Stop()
+			log.Warn("gRPC calls still in flight after the timeout; stopped server")
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		if httpServer == nil {
+			return
+		}
+		if err := httpServer.Shutdown(ctx); err != nil {
+			httpServer.Close()
+			log.WithError(err).Warn("HTTP requests still in flight after the timeout; closed server")
+			return
+		}
+		log.Info("shut down HTTP server gracefully")
+	}()
+	wg.Wait()
+}
+
+// newGRPCServer is zenkit.NewGRPCServer with creds, which may be nil, the
+// client certificate and request ID tagged on each call, the behavior
+// profile reported on every call and applied to MathService's, and the
+// faults MathService and Introspection calls ask for injected. When
+// limiter isn't nil, MathService calls are charged against its quotas once
+// their identity is known. grpctest turns zenkit's Stackdriver tracing and
+// metrics off, so they aren't set up here; ocgrpc records its stats for
+// Prometheus and traces calls instead, joining the traces of B3 and
+// traceparent metadata.
+func newGRPCServer(cfg *Config, creds credentials.TransportCredentials, limiter *quota.Limiter, log *logrus.Entry) *grpc.Server {
+	authFunc := zenkit.UnverifiedIdentity
+	if cfg.Auth.Disabled {
+		authFunc = zenkit.DevIdentity
+	}
+	maxRequests := viper.GetInt(zenkit.GRPCMaxConcurrentRequests)
+
+	stream := []grpc.StreamServerInterceptor{
+		zenkit.MetricTagsStreamServerInterceptor(),
+		grpc_ctxtags.StreamServerInterceptor(),
+		accesslog.StreamServerInterceptor(),
+		profile.StreamServerInterceptor(activeProfile, mathService),
+		peerTagsStreamServerInterceptor,
+		grpc_logrus.StreamServerInterceptor(log),
+		zenkit.ConcurrentRequestsStreamServerInterceptor(maxRequests),
+		grpc_auth.StreamServerInterceptor(authFunc),
+		zenkit.IdentityTagsStreamServerInterceptor(),
+	}
+	unary := []grpc.UnaryServerInterceptor{
+		zenkit.MetricTagsUnaryServerInterceptor(),
+		grpc_ctxtags.UnaryServerInterceptor(),
+		accesslog.UnaryServerInterceptor(),
+		profile.UnaryServerInterceptor(activeProfile, mathService),
+		peerTagsUnaryServerInterceptor,
+		grpc_logrus.UnaryServerInterceptor(log),
+		zenkit.ConcurrentRequestsUnaryServerInterceptor(maxRequests),
+		grpc_auth.UnaryServerInterceptor(authFunc),
+		zenkit.IdentityTagsUnaryServerInterceptor(),
+	}
+	if limiter != nil {
+		destination := viper.GetString(zenkit.ServiceLabel)
+		stream = append(stream, limiter.StreamServerInterceptor(destination, mathService))
+		unary = append(unary, limiter.UnaryServerInterceptor(destination, mathService))
+	}
+	stream = append(stream,
+		grpc_recovery.StreamServerInterceptor(),
+		fault.StreamServerInterceptor(faultConfig, mathService, introspectionService),
+	)
+	unary = append(unary,
+		grpc_recovery.UnaryServerInterceptor(),
+		fault.UnaryServerInterceptor(faultConfig, mathService, introspectionService),
+	)
+
+	opts := []grpc.ServerOption{
+		grpc.StreamInterceptor(grpc_middleware.ChainStreamServer(stream...)),
+		grpc.UnaryInterceptor(grpc_middleware.ChainUnaryServer(unary...)),
+	}
+	if creds != nil {
+		opts = append(opts, grpc.Creds(creds))
+	}
+	if instrumented(cfg) {
+		opts = append(opts, grpc.StatsHandler(&tracing.ServerHandler{}))
+	}
+	server := grpc.NewServer(opts...)
+	reflection.Register(server)
+	return server
+}
+
+// tagPeer adds the verified client certificate to the call's tags, so it is
+// logged with the call. Handlers can read it with certs.PeerFromContext.
+func tagPeer(ctx context.Context) {
+	p := certs.PeerFromContext(ctx)
+	if p == nil {
+		return
+	}
+	tags := grpc_ctxtags.Extract(ctx).Set("peer.subject", p.Subject)
+	if p.SPIFFEID != "" {
+		tags.Set("peer.spiffe_id", p.SPIFFEID)
+	}
+}
+
+func peerTagsUnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	tagPeer(ctx)
+	return handler(ctx, req)
+}
+
+func peerTagsStreamServerInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	tagPeer(ss.Context())
+	return handler(srv, ss)
+}
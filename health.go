@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/viper"
+	"github.com/zenoss/grpctest/auth"
+	"github.com/zenoss/grpctest/healthcheck"
+	"github.com/zenoss/zenkit"
+)
+
+// The gRPC services health checks, faults and profiles can be limited to.
+const (
+	mathService          = "MathService"
+	introspectionService = "Introspection"
+	authzService         = "envoy.service.auth.v2.Authorization"
+	rateLimitService     = "envoy.service.ratelimit.v2.RateLimitService"
+)
+
+// newHealthMonitor checks what the enabled features depend on: the Redis
+// behind quotas, rate limits and the API key cache, the JWKS signing keys
+// and, when configured, the key server.
+func newHealthMonitor(cfg *Config, verifier *auth.Verifier, log *logrus.Entry) *healthcheck.Monitor {
+	monitor := healthcheck.New(healthcheck.Config{
+		Interval: cfg.Health.Interval,
+		Timeout:  cfg.Health.Timeout,
+	}, log.WithField("component", "health"))
+
+	if cfg.Quota.Enabled && cfg.Quota.Backend == "redis" {
+		monitor.Add("quota-redis", redisChecker(cfg.Quota.Redis.DBID), mathService)
+	}
+	if cfg.RateLimit.Enabled && cfg.RateLimit.Backend == "redis" {
+		monitor.Add("ratelimit-redis", redisChecker(cfg.RateLimit.Redis.DBID), rateLimitService)
+	}
+	if cfg.ExtAuthz.Enabled && viper.GetBool(APIKeyEnabledConfig) && viper.GetBool(APIKeyRedisEnabledConfig) {
+		monitor.Add("apikey-redis", redisChecker(viper.GetInt(APIKeyRedisDBIDConfig)), authzService)
+	}
+
+	var verifying []string
+	if !cfg.Auth.Disabled {
+		verifying = append(verifying, mathService)
+	}
+	if cfg.ExtAuthz.Enabled {
+		verifying = append(verifying, authzService)
+	}
+	if len(verifying) > 0 {
+		monitor.Add("jwks", jwksChecker(verifier.Keys(), cfg.Health.JWKSMaxAge), verifying...)
+	}
+
+	if cfg.Health.KeyServerURL != "" {
+		client := &http.Client{Timeout: cfg.Health.Timeout}
+		monitor.Add("keyserver", healthcheck.HTTPChecker(client, cfg.Health.KeyServerURL))
+	}
+	return monitor
+}
+
+// redisChecker pings every shard of the ring the backends of dbid use.
+// Missing addresses are refused at startup, so the ring is never nil here.
+func redisChecker(dbid int) healthcheck.Checker {
+	ring := zenkit.NewRedisRingId(dbid)
+	return healthcheck.CheckerFunc(func(ctx context.Context) error {
+		if ring == nil {
+			return errors.Errorf("no %s", zenkit.GCMemstoreAddressConfig)
+		}
+		return ring.ForEachShard(func(client *redis.Client) error {
+			return client.Ping().Err()
+		})
+	})
+}
+
+// jwksChecker keeps the signing keys fresh, fetching them when they are due
+// rather than when the next token arrives. A failed fetch only fails the
+// check once the keys are older than maxAge, since tokens can still be
+// verified with the cached ones until then.
+func jwksChecker(keys *auth.KeySet, maxAge time.Duration) healthcheck.Checker {
+	return healthcheck.CheckerFunc(func(ctx context.Context) error {
+		fetched := keys.LastRefresh()
+		if time.Since(fetched) < keys.RefreshInterval {
+			return nil
+		}
+		err := keys.Refresh(ctx)
+		switch {
+		case err == nil:
+			return nil
+		case fetched.IsZero():
+			return errors.Wrap(err, "no signing keys")
+		case time.Since(fetched) > maxAge:
+			return errors.Wrapf(err, "signing keys are %s old", time.Since(fetched).Round(time.Minute))
+		}
+		return nil
+	})
+}
@@ -0,0 +1,290 @@
+// Package healthcheck tracks whether a service is live and ready, and
+// reports it over grpc_health_v1 and HTTP. Readiness follows a set of
+// pluggable dependency checks, run in the background, each of which can be
+// limited to the gRPC services that depend on it.
+package healthcheck
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const (
+	DefaultInterval = 10 * time.Second
+	DefaultTimeout  = 2 * time.Second
+)
+
+var (
+	ErrNotChecked   = errors.New("not checked yet")
+	ErrShuttingDown = errors.New("shutting down")
+)
+
+// Checker reports whether a dependency is usable.
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc is a function Checker.
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Config configures a Monitor.
+type Config struct {
+	// Interval is the time between rounds of checks.
+	Interval time.Duration
+	// Timeout bounds each check.
+	Timeout time.Duration
+}
+
+type check struct {
+	name     string
+	checker  Checker
+	services []string
+	err      error
+}
+
+// Monitor runs the checks and keeps the serving status of the overall
+// server, the empty service name, and of each registered service.
+type Monitor struct {
+	cfg    Config
+	server *health.Server
+	log    *logrus.Entry
+
+	mu       sync.RWMutex
+	checks   []*check
+	services []string
+	ready    bool
+	checked  time.Time
+	shutdown bool
+	watchers []func(ready bool)
+	now      func() time.Time
+}
+
+// New creates a Monitor. Until its first round of checks it is not ready.
+func New(cfg Config, log *logrus.Entry) *Monitor {
+	if cfg.Interval <= 0 {
+		cfg.Interval = DefaultInterval
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	m := &Monitor{
+		cfg:    cfg,
+		server: health.NewServer(),
+		log:    log,
+		now:    time.Now,
+	}
+	m.server.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	return m
+}
+
+// Add adds a dependency check. A failing check makes the services it names
+// NOT_SERVING, or every service when it names none, as well as the server
+// as a whole. Checks are added before Run.
+func (m *Monitor) Add(name string, checker Checker, services ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.checks = append(m.checks, &check{name: name, checker: checker, services: services, err: ErrNotChecked})
+}
+
+// Register serves grpc_health_v1 on s, with a status for each service
+// already registered on it.
+func (m *Monitor) Register(s *grpc.Server) {
+	var services []string
+	for name := range s.GetServiceInfo() {
+		if name != "grpc.reflection.v1alpha.ServerReflection" {
+			services = append(services, name)
+		}
+	}
+	sort.Strings(services)
+	healthpb.RegisterHealthServer(s, m.server)
+
+	m.mu.Lock()
+	m.services = services
+	m.updateLocked()
+	m.mu.Unlock()
+}
+
+// Watch calls f with the server's readiness now and whenever it changes.
+// f is called with the Monitor locked, so it must not call back into it.
+func (m *Monitor) Watch(f func(ready bool)) {
+	m.mu.Lock()
+	m.watchers = append(m.watchers, f)
+	ready := m.ready
+	m.mu.Unlock()
+	f(ready)
+}
+
+// Run checks now and every interval until ctx is done.
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		m.CheckNow(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckNow runs every check concurrently and updates the statuses.
+func (m *Monitor) CheckNow(ctx context.Context) {
+	m.mu.RLock()
+	checks := append([]*check(nil), m.checks...)
+	m.mu.RUnlock()
+
+	errs := make([]error, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c *check) {
+			defer wg.Done()
+			errs[i] = m.run(ctx, c.checker)
+		}(i, c)
+	}
+	wg.Wait()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, c := range checks {
+		if (errs[i] == nil) != (c.err == nil) {
+			log := m.log.WithField("check", c.name)
+			if errs[i] != nil {
+				log.WithError(errs[i]).Warn("health check failed")
+			} else {
+				log.Info("health check passed")
+			}
+		}
+		c.err = errs[i]
+	}
+	m.checked = m.now()
+	m.updateLocked()
+}
+
+// run runs a check with the timeout, giving up on checks that ignore ctx.
+func (m *Monitor) run(ctx context.Context, checker Checker) error {
+	ctx, cancel := context.WithTimeout(ctx, m.cfg.Timeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- checker.Check(ctx) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "check did not finish")
+	}
+}
+
+// Shutdown makes everything NOT_SERVING for good, so load balancers stop
+// sending calls while the server drains.
+func (m *Monitor) Shutdown() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.shutdown = true
+	m.updateLocked()
+	m.server.Shutdown()
+}
+
+// updateLocked sets each service's status from the checks.
+func (m *Monitor) updateLocked() {
+	if m.shutdown {
+		m.setReadyLocked(false)
+		return
+	}
+	ready := true
+	failing := map[string]bool{}
+	for _, c := range m.checks {
+		if c.err == nil {
+			continue
+		}
+		ready = false
+		if len(c.services) == 0 {
+			for _, s := range m.services {
+				failing[s] = true
+			}
+		}
+		for _, s := range c.services {
+			failing[s] = true
+		}
+	}
+	for _, s := range m.services {
+		m.server.SetServingStatus(s, servingStatus(!failing[s]))
+	}
+	m.server.SetServingStatus("", servingStatus(ready))
+	m.setReadyLocked(ready)
+}
+
+func (m *Monitor) setReadyLocked(ready bool) {
+	if ready == m.ready {
+		return
+	}
+	m.ready = ready
+	m.log.WithField("ready", ready).Info("readiness changed")
+	for _, f := range m.watchers {
+		f(ready)
+	}
+}
+
+func servingStatus(serving bool) healthpb.HealthCheckResponse_ServingStatus {
+	if serving {
+		return healthpb.HealthCheckResponse_SERVING
+	}
+	return healthpb.HealthCheckResponse_NOT_SERVING
+}
+
+// Ready is whether the server should be sent calls: every check passed in
+// the last round and it isn't shutting down.
+func (m *Monitor) Ready() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.ready
+}
+
+// Live is whether the server is working at all, which it is while rounds
+// of checks keep finishing, whatever their results. A server that is
+// shutting down is still live.
+func (m *Monitor) Live() error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.checked.IsZero() || m.shutdown {
+		return nil
+	}
+	if late := m.now().Sub(m.checked); late > 3*m.cfg.Interval+m.cfg.Timeout {
+		return errors.Errorf("no health checks for %s", late.Round(time.Second))
+	}
+	return nil
+}
+
+// Result is the last outcome of a check; a nil Err passed.
+type Result struct {
+	Name string
+	Err  error
+}
+
+// Results are the checks' last outcomes, in the order they were added, led
+// by shutting down when it is.
+func (m *Monitor) Results() []Result {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var results []Result
+	if m.shutdown {
+		results = append(results, Result{"shutdown", ErrShuttingDown})
+	}
+	for _, c := range m.checks {
+		results = append(results, Result{c.name, c.err})
+	}
+	return results
+}
@@ -0,0 +1,70 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// LiveHandler answers the liveness probe: 200 IMOK while the server works,
+// 503 when it should be restarted.
+func (m *Monitor) LiveHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := m.Live(); err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintln(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, "IMOK")
+	})
+}
+
+// ReadyHandler answers the readiness probe: 200 IMOK when ready and 503
+// otherwise, followed by the result of each check.
+func (m *Monitor) ReadyHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if m.Ready() {
+			w.WriteHeader(http.StatusOK)
+			fmt.Fprintln(w, "IMOK")
+		} else {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprintln(w, "NOT READY")
+		}
+		for _, result := range m.Results() {
+			if result.Err != nil {
+				fmt.Fprintf(w, "  %s: %s\n", result.Name, result.Err)
+			} else {
+				fmt.Fprintf(w, "  %s: ok\n", result.Name)
+			}
+		}
+	})
+}
+
+// HTTPChecker passes when a GET of url answers 2xx, e.g. another service's
+// health check. A nil client uses http.DefaultClient.
+func HTTPChecker(client *http.Client, url string) Checker {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return CheckerFunc(func(ctx context.Context) error {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			return errors.Wrap(err, "unable to build request")
+		}
+		resp, err := client.Do(req.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode/100 != 2 {
+			return errors.Errorf("%s answered %s", url, resp.Status)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/zenoss/grpctest/accesslog"
+	"github.com/zenoss/grpctest/auth"
+	"github.com/zenoss/grpctest/certs"
+	pb "github.com/zenoss/grpctest/pb"
+	"github.com/zenoss/grpctest/redact"
+	"github.com/zenoss/zenkit"
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// namespaceFile is where Kubernetes mounts the pod's namespace.
+const namespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// introspectionServer is the gRPC counterpart of the HTTP root's header
+// dump: it answers with what the server made of the call.
+type introspectionServer struct {
+	verifier *auth.Verifier
+	redactor *redact.Redactor
+}
+
+type identityErrorKey struct{}
+
+// AuthFuncOverride verifies the token like MathService does, but lets the
+// call through without a valid one, so callers can see why it failed.
+// Introspection calls aren't charged against quotas.
+func (s *introspectionServer) AuthFuncOverride(ctx context.Context, fullMethodName string) (context.Context, error) {
+	authFunc := s.verifier.AuthFunc
+	if currentConfig().Auth.Disabled {
+		authFunc = zenkit.DevIdentity
+	}
+	authCtx, err := authFunc(ctx)
+	if err != nil {
+		anonCtx, _ := auth.Anonymous(ctx)
+		return context.WithValue(anonCtx, identityErrorKey{}, status.Convert(err).Message()), nil
+	}
+	return authCtx, nil
+}
+
+func (s *introspectionServer) WhoAmI(ctx context.Context, in *pb.WhoAmIRequest) (*pb.CallInfo, error) {
+	return s.callInfo(ctx), nil
+}
+
+func (s *introspectionServer) Echo(ctx context.Context, in *pb.EchoRequest) (*pb.EchoResponse, error) {
+	return &pb.EchoResponse{Message: in.Message, Call: s.callInfo(ctx)}, nil
+}
+
+// callInfo describes the call, its credentials redacted.
+func (s *introspectionServer) callInfo(ctx context.Context) *pb.CallInfo {
+	info := &pb.CallInfo{
+		RequestId: accesslog.FromContext(ctx),
+		Identity:  identityInfo(ctx),
+		Server:    serverInfo(),
+	}
+	info.Method, _ = grpc.Method(ctx)
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		info.Metadata = make(map[string]*pb.CallInfo_Values, len(md))
+		for key, values := range s.redactor.Metadata(md) {
+			info.Metadata[key] = &pb.CallInfo_Values{Values: values}
+		}
+	}
+	if pr, ok := peer.FromContext(ctx); ok {
+		info.PeerAddress = pr.Addr.String()
+		if tlsInfo, ok := pr.AuthInfo.(credentials.TLSInfo); ok {
+			info.Tls = tlsConnInfo(&tlsInfo.State)
+		}
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		info.Deadline, _ = ptypes.TimestampProto(deadline)
+	}
+	return info
+}
+
+func tlsConnInfo(state *tls.ConnectionState) *pb.CallInfo_TLS {
+	info := &pb.CallInfo_TLS{
+		Version:     tls.VersionName(state.Version),
+		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+		ServerName:  state.ServerName,
+	}
+	if p := certs.PeerFromState(state); p != nil {
+		info.PeerCertificate = &pb.CallInfo_Certificate{
+			Subject:      p.Subject,
+			Issuer:       p.Issuer,
+			SerialNumber: p.SerialNumber,
+			DnsNames:     p.DNSNames,
+			Uris:         p.URIs,
+			SpiffeId:     p.SPIFFEID,
+		}
+		info.PeerCertificate.NotBefore, _ = ptypes.TimestampProto(p.NotBefore)
+		info.PeerCertificate.NotAfter, _ = ptypes.TimestampProto(p.NotAfter)
+	}
+	return info
+}
+
+func identityInfo(ctx context.Context) *pb.CallInfo_Identity {
+	if msg, ok := ctx.Value(identityErrorKey{}).(string); ok {
+		return &pb.CallInfo_Identity{Error: msg}
+	}
+	ident := zenkit.ContextTenantIdentity(ctx)
+	if ident == nil {
+		return &pb.CallInfo_Identity{Error: ErrIdentityMissing.Error()}
+	}
+	return identityMessage(ident)
+}
+
+// identityMessage is what an identity says about the caller.
+func identityMessage(ident zenkit.TenantIdentity) *pb.CallInfo_Identity {
+	info := &pb.CallInfo_Identity{
+		Id:         ident.ID(),
+		Email:      ident.Email(),
+		Tenant:     ident.Tenant(),
+		Connection: ident.Connection(),
+		ClientId:   ident.ClientID(),
+		Scopes:     ident.Scopes(),
+	}
+	if groups, ok := ident.(zenkit.IdentityGroups); ok {
+		info.Groups = groups.Groups()
+	}
+	if roles, ok := ident.(zenkit.IdentityRoles); ok {
+		info.Roles = roles.Roles()
+	}
+	return info
+}
+
+// serverInfo names the pod from the POD_NAME and POD_NAMESPACE the
+// downward API sets, falling back to the host name, which Kubernetes sets
+// to the pod's, and the service account's namespace.
+func serverInfo() *pb.CallInfo_Server {
+	info := &pb.CallInfo_Server{
+		Service:   serviceName,
+		Pod:       os.Getenv("POD_NAME"),
+		Namespace: os.Getenv("POD_NAMESPACE"),
+		Version:   currentConfig().Version,
+	}
+	if info.Pod == "" {
+		info.Pod, _ = os.Hostname()
+	}
+	if info.Namespace == "" {
+		if b, err := ioutil.ReadFile(namespaceFile); err == nil {
+			info.Namespace = strings.TrimSpace(string(b))
+		}
+	}
+	return info
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/zenoss/grpctest/apikey"
+	"github.com/zenoss/zenkit"
+)
+
+// The settings mirror the api-key-server deployment in
+// yaml/api-key-server.yaml; each is read from KEYSERVER_<KEY> with dots
+// replaced by underscores.
+const (
+	serviceName = "keyserver"
+
+	ListeningPortConfig     = "listening_port"
+	Auth0DomainConfig       = "auth0.domain"
+	Auth0ClientIDConfig     = "auth0.clientid"
+	Auth0ClientSecretConfig = "auth0.clientsecret"
+	Auth0AudienceConfig     = "auth0.audience"
+	Auth0TokenURLConfig     = "auth0.token_url"
+	CacheTimeoutConfig      = "cache.timeout"
+	LRUSizeLimitConfig      = "lru.sizelimit"
+	RedisEnabledConfig      = "redis.enabled"
+	RedisDBIDConfig         = "redis.dbid"
+
+	ExtAuthzPathPrefix = "/extauthz"
+)
+
+func initConfig() {
+	zenkit.InitConfig(serviceName)
+	viper.SetDefault(ListeningPortConfig, ":8080")
+	viper.SetDefault(Auth0DomainConfig, "zenoss-dev.auth0.com")
+	viper.SetDefault(Auth0AudienceConfig, "")
+	viper.SetDefault(Auth0TokenURLConfig, "")
+	// minutes
+	viper.SetDefault(CacheTimeoutConfig, 1440)
+	viper.SetDefault(LRUSizeLimitConfig, 2500)
+	viper.SetDefault(RedisEnabledConfig, false)
+	viper.SetDefault(RedisDBIDConfig, 0)
+}
+
+func main() {
+	initConfig()
+	log := zenkit.Logger(serviceName)
+
+	source := apikey.NewAuth0(apikey.Auth0Config{
+		Domain:       viper.GetString(Auth0DomainConfig),
+		ClientID:     viper.GetString(Auth0ClientIDConfig),
+		ClientSecret: viper.GetString(Auth0ClientSecretConfig),
+		Audience:     viper.GetString(Auth0AudienceConfig),
+		TokenURL:     viper.GetString(Auth0TokenURLConfig),
+	})
+	cacheCfg := apikey.CacheConfig{
+		Size: viper.GetInt(LRUSizeLimitConfig),
+		TTL:  time.Duration(viper.GetInt(CacheTimeoutConfig)) * time.Minute,
+	}
+	if viper.GetBool(RedisEnabledConfig) {
+		cacheCfg.Redis = zenkit.NewCacheId(viper.GetInt(RedisDBIDConfig))
+	}
+	cached, err := apikey.NewCachedSource(source, cacheCfg)
+	if err != nil {
+		log.WithError(err).Fatal("unable to create token cache")
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthcheck", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, "IMOK")
+	})
+	mux.Handle("/accessToken", apikey.AccessTokenHandler(cached, log))
+	// Envoy's ext_authz http_service appends the original request path to
+	// the path_prefix in yaml/apikeys_auth_ext.yaml
+	mux.Handle(ExtAuthzPathPrefix+"/", apikey.ExtAuthzHandler(cached, log))
+
+	addr := viper.GetString(ListeningPortConfig)
+	log.WithField("address", addr).Info("started server")
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		log.WithError(err).Fatal("server stopped")
+	}
+}
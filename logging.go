@@ -0,0 +1,32 @@
+package main
+
+import (
+	stackdriver "github.com/TV4/logrus-stackdriver-formatter"
+	"github.com/sirupsen/logrus"
+	"github.com/zenoss/zenkit"
+)
+
+// bootLogger logs until the config says how to.
+var bootLogger = logrus.WithField(zenkit.LogServiceField, serviceName)
+
+// newLogger is zenkit's logger in the configured format: stackdriver, for
+// Stackdriver's JSON, or text. Without log.format, log.stackdriver picks.
+func newLogger(cfg LogSettings) *logrus.Entry {
+	logger := zenkit.Logger(serviceName)
+	format := cfg.Format
+	if format == "" {
+		format = "text"
+		if cfg.Stackdriver {
+			format = "stackdriver"
+		}
+	}
+	switch format {
+	case "stackdriver":
+		logger.Logger.SetFormatter(stackdriver.NewFormatter(stackdriver.WithService(serviceName)))
+	case "text":
+		logger.Logger.SetFormatter(&logrus.TextFormatter{})
+	}
+	level, _ := logrus.ParseLevel(cfg.Level)
+	logger.Logger.SetLevel(level)
+	return logger
+}
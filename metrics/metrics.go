@@ -0,0 +1,194 @@
+// Package metrics defines grpctest's own measures and views, limits the
+// cardinality of their tenant and method labels, and serves registered
+// views in the Prometheus text format.
+package metrics
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/zenoss/zenkit"
+	"go.opencensus.io/plugin/ocgrpc"
+	"go.opencensus.io/plugin/ochttp"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	DefaultMaxTenants = 100
+	DefaultMaxMethods = 100
+
+	// Other replaces the tenants and methods over the limits.
+	Other = "other"
+	// Anonymous is the tenant of requests without an identity.
+	Anonymous = "anonymous"
+)
+
+var (
+	KeyTenant, _ = tag.NewKey("tenant")
+	KeyMethod, _ = tag.NewKey("method")
+	KeyCode, _   = tag.NewKey("code")
+	KeyCache, _  = tag.NewKey("cache")
+	KeyResult, _ = tag.NewKey("result")
+)
+
+var (
+	AuthFailures    = stats.Int64("grpctest/auth_failures", "Requests refused for their credentials or identity", stats.UnitDimensionless)
+	QuotaRejections = stats.Int64("grpctest/quota_rejections", "Requests refused because a quota was used up", stats.UnitDimensionless)
+	CacheLookups    = stats.Int64("grpctest/cache_lookups", "Cache lookups", stats.UnitDimensionless)
+)
+
+var (
+	AuthFailuresView = &view.View{
+		Name:        "grpctest/auth_failures",
+		Description: "Count of requests refused for their credentials or identity, by method and status code.",
+		Measure:     AuthFailures,
+		TagKeys:     []tag.Key{KeyMethod, KeyCode},
+		Aggregation: view.Count(),
+	}
+
+	QuotaRejectionsView = &view.View{
+		Name:        "grpctest/quota_rejections",
+		Description: "Count of requests refused because a quota was used up, by tenant and method.",
+		Measure:     QuotaRejections,
+		TagKeys:     []tag.Key{KeyTenant, KeyMethod},
+		Aggregation: view.Count(),
+	}
+
+	CacheLookupsView = &view.View{
+		Name:        "grpctest/cache_lookups",
+		Description: "Count of cache lookups, by cache and result.",
+		Measure:     CacheLookups,
+		TagKeys:     []tag.Key{KeyCache, KeyResult},
+		Aggregation: view.Count(),
+	}
+
+	// ochttp's default views aren't broken down by route.
+	HTTPServerRequestsByRouteView = &view.View{
+		Name:        "grpctest/http/server/requests_by_route",
+		Description: "Count of HTTP requests, by route, method and status code.",
+		Measure:     ochttp.ServerLatency,
+		TagKeys:     []tag.Key{ochttp.KeyServerRoute, ochttp.Method, ochttp.StatusCode},
+		Aggregation: view.Count(),
+	}
+
+	HTTPServerLatencyByRouteView = &view.View{
+		Name:        "grpctest/http/server/latency_by_route",
+		Description: "Distribution of HTTP latency in milliseconds, by route.",
+		Measure:     ochttp.ServerLatency,
+		TagKeys:     []tag.Key{ochttp.KeyServerRoute},
+		Aggregation: ochttp.DefaultLatencyDistribution,
+	}
+)
+
+// Views are the views grpctest serves: ocgrpc's and ochttp's defaults,
+// zenkit's concurrent requests and its own.
+func Views() []*view.View {
+	views := append([]*view.View{}, ocgrpc.DefaultServerViews...)
+	views = append(views, ochttp.DefaultServerViews...)
+	return append(views,
+		zenkit.ConcurrentRequestsView,
+		AuthFailuresView,
+		QuotaRejectionsView,
+		CacheLookupsView,
+		HTTPServerRequestsByRouteView,
+		HTTPServerLatencyByRouteView,
+	)
+}
+
+// limit lets through the first max distinct values of a label and reports
+// the rest as Other, so a flood of tenants or made up paths can't grow the
+// metrics without bound.
+type limit struct {
+	mu   sync.Mutex
+	max  int
+	seen map[string]struct{}
+}
+
+func (l *limit) value(v string) string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.seen[v]; ok {
+		return v
+	}
+	if len(l.seen) >= l.max {
+		return Other
+	}
+	l.seen[v] = struct{}{}
+	return v
+}
+
+func (l *limit) set(max int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.max = max
+}
+
+var (
+	tenants = &limit{max: DefaultMaxTenants, seen: map[string]struct{}{}}
+	methods = &limit{max: DefaultMaxMethods, seen: map[string]struct{}{}}
+)
+
+// SetLimits sets how many distinct tenants and methods are reported. Values
+// already reported stay when the limits are lowered.
+func SetLimits(maxTenants, maxMethods int) {
+	tenants.set(maxTenants)
+	methods.set(maxMethods)
+}
+
+// Tenant is the tenant label of an identity's tenant, empty for none.
+func Tenant(tenant string) string {
+	if tenant == "" {
+		return Anonymous
+	}
+	return tenants.value(tenant)
+}
+
+// Method is the method label of a gRPC method or HTTP path.
+func Method(method string) string {
+	return methods.value(method)
+}
+
+// RecordAuthFailure counts a request refused with code.
+func RecordAuthFailure(ctx context.Context, method string, code codes.Code) {
+	stats.RecordWithTags(ctx, []tag.Mutator{
+		tag.Upsert(KeyMethod, Method(method)),
+		tag.Upsert(KeyCode, codeName(code)),
+	}, AuthFailures.M(1))
+}
+
+// RecordQuotaRejection counts a request refused by a quota.
+func RecordQuotaRejection(ctx context.Context, tenant, method string) {
+	stats.RecordWithTags(ctx, []tag.Mutator{
+		tag.Upsert(KeyTenant, Tenant(tenant)),
+		tag.Upsert(KeyMethod, Method(method)),
+	}, QuotaRejections.M(1))
+}
+
+// RecordCacheLookup counts a lookup in cache, whose result is e.g. hit or
+// miss.
+func RecordCacheLookup(ctx context.Context, cache, result string) {
+	stats.RecordWithTags(ctx, []tag.Mutator{
+		tag.Upsert(KeyCache, cache),
+		tag.Upsert(KeyResult, result),
+	}, CacheLookups.M(1))
+}
+
+// codeName is the code as ocgrpc's grpc_server_status writes it, e.g.
+// PERMISSION_DENIED.
+func codeName(code codes.Code) string {
+	var b strings.Builder
+	lower := false
+	for _, r := range code.String() {
+		upper := r >= 'A' && r <= 'Z'
+		if upper && lower {
+			b.WriteByte('_')
+		}
+		lower = !upper
+		b.WriteRune(r)
+	}
+	return strings.ToUpper(b.String())
+}
@@ -0,0 +1,148 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.opencensus.io/stats/view"
+)
+
+// Handler serves the views in the Prometheus text exposition format, their
+// names prefixed with namespace unless they already are. The views are read
+// when scraped, so no reporting period applies; views that aren't registered
+// are left out.
+//
+// It stands in for the OpenCensus Prometheus exporter, which would bring
+// the Prometheus client and its dependencies along.
+func Handler(namespace string, views ...*view.View) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		out := bufio.NewWriter(w)
+		defer out.Flush()
+		for _, v := range views {
+			rows, err := view.RetrieveData(v.Name)
+			if err != nil {
+				continue
+			}
+			name := sanitize(v.Name)
+			if !strings.HasPrefix(name, namespace+"_") {
+				name = namespace + "_" + name
+			}
+			writeView(out, name, v, rows)
+		}
+	})
+}
+
+// promTypes are the Prometheus types of the aggregations, as the
+// OpenCensus exporter maps them.
+var promTypes = map[view.AggType]string{
+	view.AggTypeCount:        "counter",
+	view.AggTypeSum:          "untyped",
+	view.AggTypeLastValue:    "gauge",
+	view.AggTypeDistribution: "histogram",
+}
+
+func writeView(w *bufio.Writer, name string, v *view.View, rows []*view.Row) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, escape(v.Description, false))
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, promTypes[v.Aggregation.Type])
+
+	type sample struct {
+		labels string
+		row    *view.Row
+	}
+	samples := make([]sample, len(rows))
+	for i, row := range rows {
+		samples[i] = sample{labels(v, row), row}
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].labels < samples[j].labels })
+
+	for _, s := range samples {
+		switch data := s.row.Data.(type) {
+		case *view.CountData:
+			writeSample(w, name, s.labels, float64(data.Value))
+		case *view.SumData:
+			writeSample(w, name, s.labels, data.Value)
+		case *view.LastValueData:
+			writeSample(w, name, s.labels, data.Value)
+		case *view.DistributionData:
+			var cumulative int64
+			for i, bound := range v.Aggregation.Buckets {
+				cumulative += data.CountPerBucket[i]
+				writeSample(w, name+"_bucket", withLabel(s.labels, "le", formatFloat(bound)), float64(cumulative))
+			}
+			writeSample(w, name+"_bucket", withLabel(s.labels, "le", "+Inf"), float64(data.Count))
+			writeSample(w, name+"_sum", s.labels, data.Mean*float64(data.Count))
+			writeSample(w, name+"_count", s.labels, float64(data.Count))
+		}
+	}
+}
+
+// labels renders every tag key of the view, those missing from the row as
+// empty, so all the samples of a metric have the same labels.
+func labels(v *view.View, row *view.Row) string {
+	values := make(map[string]string, len(row.Tags))
+	for _, t := range row.Tags {
+		values[t.Key.Name()] = t.Value
+	}
+	parts := make([]string, len(v.TagKeys))
+	for i, k := range v.TagKeys {
+		parts[i] = sanitize(k.Name()) + `="` + escape(values[k.Name()], true) + `"`
+	}
+	return strings.Join(parts, ",")
+}
+
+func withLabel(labels, name, value string) string {
+	label := name + `="` + value + `"`
+	if labels == "" {
+		return label
+	}
+	return labels + "," + label
+}
+
+func writeSample(w *bufio.Writer, name, labels string, value float64) {
+	if labels != "" {
+		fmt.Fprintf(w, "%s{%s} %s\n", name, labels, formatFloat(value))
+		return
+	}
+	fmt.Fprintf(w, "%s %s\n", name, formatFloat(value))
+}
+
+func formatFloat(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// sanitize makes a valid metric or label name, replacing anything else with
+// underscores, e.g. grpc.io/server/completed_rpcs with
+// grpc_io_server_completed_rpcs.
+func sanitize(name string) string {
+	b := []byte(name)
+	for i, c := range b {
+		valid := c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 0 && c >= '0' && c <= '9'
+		if !valid {
+			b[i] = '_'
+		}
+	}
+	return string(b)
+}
+
+// escape escapes backslashes and newlines, and double quotes in label
+// values.
+func escape(s string, quotes bool) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, "\n", `\n`, -1)
+	if quotes {
+		s = strings.Replace(s, `"`, `\"`, -1)
+	}
+	return s
+}
@@ -0,0 +1,840 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: pb/envoy/auth/external_auth.proto
+
+// The subset of Envoy's envoy.service.auth.v2 external authorization API
+// that grpctest serves. Field numbers match envoy/service/auth/v2 and the
+// envoy.api.v2.core and envoy.type messages it uses, so Envoy's ext_authz
+// grpc_service can call it; fields we don't read are left out.
+
+package auth
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
+	wrappers "github.com/golang/protobuf/ptypes/wrappers"
+	status "google.golang.org/genproto/googleapis/rpc/status"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status1 "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type CheckRequest struct {
+	Attributes           *AttributeContext `protobuf:"bytes,1,opt,name=attributes,proto3" json:"attributes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *CheckRequest) Reset()         { *m = CheckRequest{} }
+func (m *CheckRequest) String() string { return proto.CompactTextString(m) }
+func (*CheckRequest) ProtoMessage()    {}
+func (*CheckRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_786141b20ecd13cc, []int{0}
+}
+
+func (m *CheckRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CheckRequest.Unmarshal(m, b)
+}
+func (m *CheckRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CheckRequest.Marshal(b, m, deterministic)
+}
+func (m *CheckRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CheckRequest.Merge(m, src)
+}
+func (m *CheckRequest) XXX_Size() int {
+	return xxx_messageInfo_CheckRequest.Size(m)
+}
+func (m *CheckRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CheckRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CheckRequest proto.InternalMessageInfo
+
+func (m *CheckRequest) GetAttributes() *AttributeContext {
+	if m != nil {
+		return m.Attributes
+	}
+	return nil
+}
+
+type AttributeContext struct {
+	Source               *AttributeContext_Peer    `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	Destination          *AttributeContext_Peer    `protobuf:"bytes,2,opt,name=destination,proto3" json:"destination,omitempty"`
+	Request              *AttributeContext_Request `protobuf:"bytes,4,opt,name=request,proto3" json:"request,omitempty"`
+	ContextExtensions    map[string]string         `protobuf:"bytes,10,rep,name=context_extensions,json=contextExtensions,proto3" json:"context_extensions,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}                  `json:"-"`
+	XXX_unrecognized     []byte                    `json:"-"`
+	XXX_sizecache        int32                     `json:"-"`
+}
+
+func (m *AttributeContext) Reset()         { *m = AttributeContext{} }
+func (m *AttributeContext) String() string { return proto.CompactTextString(m) }
+func (*AttributeContext) ProtoMessage()    {}
+func (*AttributeContext) Descriptor() ([]byte, []int) {
+	return fileDescriptor_786141b20ecd13cc, []int{1}
+}
+
+func (m *AttributeContext) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AttributeContext.Unmarshal(m, b)
+}
+func (m *AttributeContext) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AttributeContext.Marshal(b, m, deterministic)
+}
+func (m *AttributeContext) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AttributeContext.Merge(m, src)
+}
+func (m *AttributeContext) XXX_Size() int {
+	return xxx_messageInfo_AttributeContext.Size(m)
+}
+func (m *AttributeContext) XXX_DiscardUnknown() {
+	xxx_messageInfo_AttributeContext.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AttributeContext proto.InternalMessageInfo
+
+func (m *AttributeContext) GetSource() *AttributeContext_Peer {
+	if m != nil {
+		return m.Source
+	}
+	return nil
+}
+
+func (m *AttributeContext) GetDestination() *AttributeContext_Peer {
+	if m != nil {
+		return m.Destination
+	}
+	return nil
+}
+
+func (m *AttributeContext) GetRequest() *AttributeContext_Request {
+	if m != nil {
+		return m.Request
+	}
+	return nil
+}
+
+func (m *AttributeContext) GetContextExtensions() map[string]string {
+	if m != nil {
+		return m.ContextExtensions
+	}
+	return nil
+}
+
+type AttributeContext_Peer struct {
+	Service              string            `protobuf:"bytes,2,opt,name=service,proto3" json:"service,omitempty"`
+	Labels               map[string]string `protobuf:"bytes,6,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Principal            string            `protobuf:"bytes,4,opt,name=principal,proto3" json:"principal,omitempty"`
+	Certificate          string            `protobuf:"bytes,5,opt,name=certificate,proto3" json:"certificate,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *AttributeContext_Peer) Reset()         { *m = AttributeContext_Peer{} }
+func (m *AttributeContext_Peer) String() string { return proto.CompactTextString(m) }
+func (*AttributeContext_Peer) ProtoMessage()    {}
+func (*AttributeContext_Peer) Descriptor() ([]byte, []int) {
+	return fileDescriptor_786141b20ecd13cc, []int{1, 0}
+}
+
+func (m *AttributeContext_Peer) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AttributeContext_Peer.Unmarshal(m, b)
+}
+func (m *AttributeContext_Peer) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AttributeContext_Peer.Marshal(b, m, deterministic)
+}
+func (m *AttributeContext_Peer) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AttributeContext_Peer.Merge(m, src)
+}
+func (m *AttributeContext_Peer) XXX_Size() int {
+	return xxx_messageInfo_AttributeContext_Peer.Size(m)
+}
+func (m *AttributeContext_Peer) XXX_DiscardUnknown() {
+	xxx_messageInfo_AttributeContext_Peer.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AttributeContext_Peer proto.InternalMessageInfo
+
+func (m *AttributeContext_Peer) GetService() string {
+	if m != nil {
+		return m.Service
+	}
+	return ""
+}
+
+func (m *AttributeContext_Peer) GetLabels() map[string]string {
+	if m != nil {
+		return m.Labels
+	}
+	return nil
+}
+
+func (m *AttributeContext_Peer) GetPrincipal() string {
+	if m != nil {
+		return m.Principal
+	}
+	return ""
+}
+
+func (m *AttributeContext_Peer) GetCertificate() string {
+	if m != nil {
+		return m.Certificate
+	}
+	return ""
+}
+
+type AttributeContext_Request struct {
+	Time                 *timestamp.Timestamp          `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	Http                 *AttributeContext_HttpRequest `protobuf:"bytes,2,opt,name=http,proto3" json:"http,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                      `json:"-"`
+	XXX_unrecognized     []byte                        `json:"-"`
+	XXX_sizecache        int32                         `json:"-"`
+}
+
+func (m *AttributeContext_Request) Reset()         { *m = AttributeContext_Request{} }
+func (m *AttributeContext_Request) String() string { return proto.CompactTextString(m) }
+func (*AttributeContext_Request) ProtoMessage()    {}
+func (*AttributeContext_Request) Descriptor() ([]byte, []int) {
+	return fileDescriptor_786141b20ecd13cc, []int{1, 1}
+}
+
+func (m *AttributeContext_Request) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AttributeContext_Request.Unmarshal(m, b)
+}
+func (m *AttributeContext_Request) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AttributeContext_Request.Marshal(b, m, deterministic)
+}
+func (m *AttributeContext_Request) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AttributeContext_Request.Merge(m, src)
+}
+func (m *AttributeContext_Request) XXX_Size() int {
+	return xxx_messageInfo_AttributeContext_Request.Size(m)
+}
+func (m *AttributeContext_Request) XXX_DiscardUnknown() {
+	xxx_messageInfo_AttributeContext_Request.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AttributeContext_Request proto.InternalMessageInfo
+
+func (m *AttributeContext_Request) GetTime() *timestamp.Timestamp {
+	if m != nil {
+		return m.Time
+	}
+	return nil
+}
+
+func (m *AttributeContext_Request) GetHttp() *AttributeContext_HttpRequest {
+	if m != nil {
+		return m.Http
+	}
+	return nil
+}
+
+type AttributeContext_HttpRequest struct {
+	Id                   string            `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Method               string            `protobuf:"bytes,2,opt,name=method,proto3" json:"method,omitempty"`
+	Headers              map[string]string `protobuf:"bytes,3,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Path                 string            `protobuf:"bytes,4,opt,name=path,proto3" json:"path,omitempty"`
+	Host                 string            `protobuf:"bytes,5,opt,name=host,proto3" json:"host,omitempty"`
+	Scheme               string            `protobuf:"bytes,6,opt,name=scheme,proto3" json:"scheme,omitempty"`
+	Query                string            `protobuf:"bytes,7,opt,name=query,proto3" json:"query,omitempty"`
+	Fragment             string            `protobuf:"bytes,8,opt,name=fragment,proto3" json:"fragment,omitempty"`
+	Size                 int64             `protobuf:"varint,9,opt,name=size,proto3" json:"size,omitempty"`
+	Protocol             string            `protobuf:"bytes,10,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	Body                 string            `protobuf:"bytes,11,opt,name=body,proto3" json:"body,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *AttributeContext_HttpRequest) Reset()         { *m = AttributeContext_HttpRequest{} }
+func (m *AttributeContext_HttpRequest) String() string { return proto.CompactTextString(m) }
+func (*AttributeContext_HttpRequest) ProtoMessage()    {}
+func (*AttributeContext_HttpRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_786141b20ecd13cc, []int{1, 2}
+}
+
+func (m *AttributeContext_HttpRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AttributeContext_HttpRequest.Unmarshal(m, b)
+}
+func (m *AttributeContext_HttpRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AttributeContext_HttpRequest.Marshal(b, m, deterministic)
+}
+func (m *AttributeContext_HttpRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AttributeContext_HttpRequest.Merge(m, src)
+}
+func (m *AttributeContext_HttpRequest) XXX_Size() int {
+	return xxx_messageInfo_AttributeContext_HttpRequest.Size(m)
+}
+func (m *AttributeContext_HttpRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_AttributeContext_HttpRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AttributeContext_HttpRequest proto.InternalMessageInfo
+
+func (m *AttributeContext_HttpRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *AttributeContext_HttpRequest) GetMethod() string {
+	if m != nil {
+		return m.Method
+	}
+	return ""
+}
+
+func (m *AttributeContext_HttpRequest) GetHeaders() map[string]string {
+	if m != nil {
+		return m.Headers
+	}
+	return nil
+}
+
+func (m *AttributeContext_HttpRequest) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *AttributeContext_HttpRequest) GetHost() string {
+	if m != nil {
+		return m.Host
+	}
+	return ""
+}
+
+func (m *AttributeContext_HttpRequest) GetScheme() string {
+	if m != nil {
+		return m.Scheme
+	}
+	return ""
+}
+
+func (m *AttributeContext_HttpRequest) GetQuery() string {
+	if m != nil {
+		return m.Query
+	}
+	return ""
+}
+
+func (m *AttributeContext_HttpRequest) GetFragment() string {
+	if m != nil {
+		return m.Fragment
+	}
+	return ""
+}
+
+func (m *AttributeContext_HttpRequest) GetSize() int64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *AttributeContext_HttpRequest) GetProtocol() string {
+	if m != nil {
+		return m.Protocol
+	}
+	return ""
+}
+
+func (m *AttributeContext_HttpRequest) GetBody() string {
+	if m != nil {
+		return m.Body
+	}
+	return ""
+}
+
+// envoy.type.HttpStatus; code is an envoy.type.StatusCode.
+type HttpStatus struct {
+	Code                 int32    `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HttpStatus) Reset()         { *m = HttpStatus{} }
+func (m *HttpStatus) String() string { return proto.CompactTextString(m) }
+func (*HttpStatus) ProtoMessage()    {}
+func (*HttpStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_786141b20ecd13cc, []int{2}
+}
+
+func (m *HttpStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HttpStatus.Unmarshal(m, b)
+}
+func (m *HttpStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HttpStatus.Marshal(b, m, deterministic)
+}
+func (m *HttpStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HttpStatus.Merge(m, src)
+}
+func (m *HttpStatus) XXX_Size() int {
+	return xxx_messageInfo_HttpStatus.Size(m)
+}
+func (m *HttpStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_HttpStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HttpStatus proto.InternalMessageInfo
+
+func (m *HttpStatus) GetCode() int32 {
+	if m != nil {
+		return m.Code
+	}
+	return 0
+}
+
+// envoy.api.v2.core.HeaderValue
+type HeaderValue struct {
+	Key                  string   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value                string   `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HeaderValue) Reset()         { *m = HeaderValue{} }
+func (m *HeaderValue) String() string { return proto.CompactTextString(m) }
+func (*HeaderValue) ProtoMessage()    {}
+func (*HeaderValue) Descriptor() ([]byte, []int) {
+	return fileDescriptor_786141b20ecd13cc, []int{3}
+}
+
+func (m *HeaderValue) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HeaderValue.Unmarshal(m, b)
+}
+func (m *HeaderValue) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HeaderValue.Marshal(b, m, deterministic)
+}
+func (m *HeaderValue) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HeaderValue.Merge(m, src)
+}
+func (m *HeaderValue) XXX_Size() int {
+	return xxx_messageInfo_HeaderValue.Size(m)
+}
+func (m *HeaderValue) XXX_DiscardUnknown() {
+	xxx_messageInfo_HeaderValue.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HeaderValue proto.InternalMessageInfo
+
+func (m *HeaderValue) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *HeaderValue) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+// envoy.api.v2.core.HeaderValueOption
+type HeaderValueOption struct {
+	Header               *HeaderValue        `protobuf:"bytes,1,opt,name=header,proto3" json:"header,omitempty"`
+	Append               *wrappers.BoolValue `protobuf:"bytes,2,opt,name=append,proto3" json:"append,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}            `json:"-"`
+	XXX_unrecognized     []byte              `json:"-"`
+	XXX_sizecache        int32               `json:"-"`
+}
+
+func (m *HeaderValueOption) Reset()         { *m = HeaderValueOption{} }
+func (m *HeaderValueOption) String() string { return proto.CompactTextString(m) }
+func (*HeaderValueOption) ProtoMessage()    {}
+func (*HeaderValueOption) Descriptor() ([]byte, []int) {
+	return fileDescriptor_786141b20ecd13cc, []int{4}
+}
+
+func (m *HeaderValueOption) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HeaderValueOption.Unmarshal(m, b)
+}
+func (m *HeaderValueOption) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HeaderValueOption.Marshal(b, m, deterministic)
+}
+func (m *HeaderValueOption) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HeaderValueOption.Merge(m, src)
+}
+func (m *HeaderValueOption) XXX_Size() int {
+	return xxx_messageInfo_HeaderValueOption.Size(m)
+}
+func (m *HeaderValueOption) XXX_DiscardUnknown() {
+	xxx_messageInfo_HeaderValueOption.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HeaderValueOption proto.InternalMessageInfo
+
+func (m *HeaderValueOption) GetHeader() *HeaderValue {
+	if m != nil {
+		return m.Header
+	}
+	return nil
+}
+
+func (m *HeaderValueOption) GetAppend() *wrappers.BoolValue {
+	if m != nil {
+		return m.Append
+	}
+	return nil
+}
+
+type DeniedHttpResponse struct {
+	Status               *HttpStatus          `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Headers              []*HeaderValueOption `protobuf:"bytes,2,rep,name=headers,proto3" json:"headers,omitempty"`
+	Body                 string               `protobuf:"bytes,3,opt,name=body,proto3" json:"body,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *DeniedHttpResponse) Reset()         { *m = DeniedHttpResponse{} }
+func (m *DeniedHttpResponse) String() string { return proto.CompactTextString(m) }
+func (*DeniedHttpResponse) ProtoMessage()    {}
+func (*DeniedHttpResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_786141b20ecd13cc, []int{5}
+}
+
+func (m *DeniedHttpResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DeniedHttpResponse.Unmarshal(m, b)
+}
+func (m *DeniedHttpResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DeniedHttpResponse.Marshal(b, m, deterministic)
+}
+func (m *DeniedHttpResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DeniedHttpResponse.Merge(m, src)
+}
+func (m *DeniedHttpResponse) XXX_Size() int {
+	return xxx_messageInfo_DeniedHttpResponse.Size(m)
+}
+func (m *DeniedHttpResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_DeniedHttpResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DeniedHttpResponse proto.InternalMessageInfo
+
+func (m *DeniedHttpResponse) GetStatus() *HttpStatus {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+func (m *DeniedHttpResponse) GetHeaders() []*HeaderValueOption {
+	if m != nil {
+		return m.Headers
+	}
+	return nil
+}
+
+func (m *DeniedHttpResponse) GetBody() string {
+	if m != nil {
+		return m.Body
+	}
+	return ""
+}
+
+type OkHttpResponse struct {
+	Headers              []*HeaderValueOption `protobuf:"bytes,2,rep,name=headers,proto3" json:"headers,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *OkHttpResponse) Reset()         { *m = OkHttpResponse{} }
+func (m *OkHttpResponse) String() string { return proto.CompactTextString(m) }
+func (*OkHttpResponse) ProtoMessage()    {}
+func (*OkHttpResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_786141b20ecd13cc, []int{6}
+}
+
+func (m *OkHttpResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_OkHttpResponse.Unmarshal(m, b)
+}
+func (m *OkHttpResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_OkHttpResponse.Marshal(b, m, deterministic)
+}
+func (m *OkHttpResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_OkHttpResponse.Merge(m, src)
+}
+func (m *OkHttpResponse) XXX_Size() int {
+	return xxx_messageInfo_OkHttpResponse.Size(m)
+}
+func (m *OkHttpResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_OkHttpResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_OkHttpResponse proto.InternalMessageInfo
+
+func (m *OkHttpResponse) GetHeaders() []*HeaderValueOption {
+	if m != nil {
+		return m.Headers
+	}
+	return nil
+}
+
+type CheckResponse struct {
+	Status *status.Status `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// Types that are valid to be assigned to HttpResponse:
+	//	*CheckResponse_DeniedResponse
+	//	*CheckResponse_OkResponse
+	HttpResponse         isCheckResponse_HttpResponse `protobuf_oneof:"http_response"`
+	XXX_NoUnkeyedLiteral struct{}                     `json:"-"`
+	XXX_unrecognized     []byte                       `json:"-"`
+	XXX_sizecache        int32                        `json:"-"`
+}
+
+func (m *CheckResponse) Reset()         { *m = CheckResponse{} }
+func (m *CheckResponse) String() string { return proto.CompactTextString(m) }
+func (*CheckResponse) ProtoMessage()    {}
+func (*CheckResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_786141b20ecd13cc, []int{7}
+}
+
+func (m *CheckResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CheckResponse.Unmarshal(m, b)
+}
+func (m *CheckResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CheckResponse.Marshal(b, m, deterministic)
+}
+func (m *CheckResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CheckResponse.Merge(m, src)
+}
+func (m *CheckResponse) XXX_Size() int {
+	return xxx_messageInfo_CheckResponse.Size(m)
+}
+func (m *CheckResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CheckResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CheckResponse proto.InternalMessageInfo
+
+func (m *CheckResponse) GetStatus() *status.Status {
+	if m != nil {
+		return m.Status
+	}
+	return nil
+}
+
+type isCheckResponse_HttpResponse interface {
+	isCheckResponse_HttpResponse()
+}
+
+type CheckResponse_DeniedResponse struct {
+	DeniedResponse *DeniedHttpResponse `protobuf:"bytes,2,opt,name=denied_response,json=deniedResponse,proto3,oneof"`
+}
+
+type CheckResponse_OkResponse struct {
+	OkResponse *OkHttpResponse `protobuf:"bytes,3,opt,name=ok_response,json=okResponse,proto3,oneof"`
+}
+
+func (*CheckResponse_DeniedResponse) isCheckResponse_HttpResponse() {}
+
+func (*CheckResponse_OkResponse) isCheckResponse_HttpResponse() {}
+
+func (m *CheckResponse) GetHttpResponse() isCheckResponse_HttpResponse {
+	if m != nil {
+		return m.HttpResponse
+	}
+	return nil
+}
+
+func (m *CheckResponse) GetDeniedResponse() *DeniedHttpResponse {
+	if x, ok := m.GetHttpResponse().(*CheckResponse_DeniedResponse); ok {
+		return x.DeniedResponse
+	}
+	return nil
+}
+
+func (m *CheckResponse) GetOkResponse() *OkHttpResponse {
+	if x, ok := m.GetHttpResponse().(*CheckResponse_OkResponse); ok {
+		return x.OkResponse
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*CheckResponse) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*CheckResponse_DeniedResponse)(nil),
+		(*CheckResponse_OkResponse)(nil),
+	}
+}
+
+func init() {
+	proto.RegisterType((*CheckRequest)(nil), "envoy.service.auth.v2.CheckRequest")
+	proto.RegisterType((*AttributeContext)(nil), "envoy.service.auth.v2.AttributeContext")
+	proto.RegisterMapType((map[string]string)(nil), "envoy.service.auth.v2.AttributeContext.ContextExtensionsEntry")
+	proto.RegisterType((*AttributeContext_Peer)(nil), "envoy.service.auth.v2.AttributeContext.Peer")
+	proto.RegisterMapType((map[string]string)(nil), "envoy.service.auth.v2.AttributeContext.Peer.LabelsEntry")
+	proto.RegisterType((*AttributeContext_Request)(nil), "envoy.service.auth.v2.AttributeContext.Request")
+	proto.RegisterType((*AttributeContext_HttpRequest)(nil), "envoy.service.auth.v2.AttributeContext.HttpRequest")
+	proto.RegisterMapType((map[string]string)(nil), "envoy.service.auth.v2.AttributeContext.HttpRequest.HeadersEntry")
+	proto.RegisterType((*HttpStatus)(nil), "envoy.service.auth.v2.HttpStatus")
+	proto.RegisterType((*HeaderValue)(nil), "envoy.service.auth.v2.HeaderValue")
+	proto.RegisterType((*HeaderValueOption)(nil), "envoy.service.auth.v2.HeaderValueOption")
+	proto.RegisterType((*DeniedHttpResponse)(nil), "envoy.service.auth.v2.DeniedHttpResponse")
+	proto.RegisterType((*OkHttpResponse)(nil), "envoy.service.auth.v2.OkHttpResponse")
+	proto.RegisterType((*CheckResponse)(nil), "envoy.service.auth.v2.CheckResponse")
+}
+
+func init() { proto.RegisterFile("pb/envoy/auth/external_auth.proto", fileDescriptor_786141b20ecd13cc) }
+
+var fileDescriptor_786141b20ecd13cc = []byte{
+	// 866 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x56, 0x5d, 0x8f, 0xdb, 0x44,
+	0x14, 0xad, 0x93, 0xac, 0xd3, 0x5c, 0x77, 0xb7, 0xed, 0x08, 0x8a, 0x65, 0x21, 0x48, 0x0d, 0x88,
+	0x05, 0x81, 0x2d, 0xa5, 0x42, 0x6a, 0x17, 0x09, 0xd1, 0xed, 0x56, 0x0d, 0x12, 0xa2, 0x95, 0x59,
+	0x81, 0xd4, 0x97, 0x95, 0x63, 0xdf, 0x8d, 0x47, 0x9b, 0x78, 0xdc, 0x99, 0xf1, 0xd2, 0xec, 0x23,
+	0xfd, 0x29, 0x3c, 0xf0, 0xe7, 0xe0, 0x3f, 0xa0, 0xf9, 0x70, 0xd6, 0x09, 0x0d, 0x64, 0x57, 0x7d,
+	0xd9, 0x9d, 0x3b, 0xf7, 0xde, 0xe3, 0x73, 0xcf, 0x1c, 0x7b, 0x02, 0xf7, 0xab, 0x49, 0x8c, 0xe5,
+	0x39, 0x5b, 0xc4, 0x69, 0x2d, 0x8b, 0x18, 0x5f, 0x4b, 0xe4, 0x65, 0x3a, 0x3b, 0x51, 0x51, 0x54,
+	0x71, 0x26, 0x19, 0x79, 0x5f, 0xe7, 0x23, 0x81, 0xfc, 0x9c, 0x66, 0x18, 0xe9, 0xcc, 0xf9, 0x28,
+	0xf8, 0x78, 0xca, 0xd8, 0x74, 0x86, 0xb1, 0x2e, 0x9a, 0xd4, 0xa7, 0xb1, 0xa4, 0x73, 0x14, 0x32,
+	0x9d, 0x57, 0xa6, 0x2f, 0xf8, 0x68, 0xbd, 0xe0, 0x37, 0x9e, 0x56, 0x15, 0x72, 0x61, 0xf3, 0x1f,
+	0xd8, 0x3c, 0xaf, 0xb2, 0x58, 0xc8, 0x54, 0xd6, 0x36, 0x11, 0xfe, 0x0a, 0xb7, 0x9e, 0x14, 0x98,
+	0x9d, 0x25, 0xf8, 0xaa, 0x46, 0x21, 0xc9, 0x33, 0x80, 0x54, 0x4a, 0x4e, 0x27, 0xb5, 0x44, 0xe1,
+	0x3b, 0x43, 0x67, 0xdf, 0x1b, 0x7d, 0x1e, 0xbd, 0x95, 0x55, 0xf4, 0xb8, 0x29, 0x7c, 0xc2, 0x4a,
+	0x89, 0xaf, 0x65, 0xd2, 0x6a, 0x0d, 0xff, 0x18, 0xc0, 0x9d, 0xf5, 0x02, 0x72, 0x04, 0xae, 0x60,
+	0x35, 0xcf, 0xd0, 0x22, 0x7f, 0xb5, 0x25, 0x72, 0xf4, 0x02, 0x91, 0x27, 0xb6, 0x97, 0xfc, 0x04,
+	0x5e, 0x8e, 0x42, 0xd2, 0x32, 0x95, 0x94, 0x95, 0x7e, 0xe7, 0x1a, 0x50, 0x6d, 0x00, 0xf2, 0x03,
+	0xf4, 0xb9, 0x19, 0xdf, 0xef, 0x69, 0xac, 0x78, 0x5b, 0x2c, 0xab, 0x5a, 0xd2, 0xf4, 0x93, 0x39,
+	0x90, 0xcc, 0xe4, 0x4e, 0xd4, 0xf1, 0x96, 0x82, 0xb2, 0x52, 0xf8, 0x30, 0xec, 0xee, 0x7b, 0xa3,
+	0xef, 0xb6, 0x45, 0xb5, 0xff, 0x9f, 0x2e, 0x01, 0x9e, 0x96, 0x92, 0x2f, 0x92, 0xbb, 0xd9, 0xfa,
+	0x7e, 0xf0, 0xb7, 0x03, 0x3d, 0x35, 0x0f, 0xf1, 0xa1, 0x6f, 0x61, 0xb5, 0x1c, 0x83, 0xa4, 0x09,
+	0xc9, 0x0b, 0x70, 0x67, 0xe9, 0x04, 0x67, 0xc2, 0x77, 0x35, 0x8b, 0x87, 0x57, 0xd1, 0x29, 0xfa,
+	0x51, 0xb7, 0x9a, 0xe7, 0x5b, 0x1c, 0xf2, 0x21, 0x0c, 0x2a, 0x4e, 0xcb, 0x8c, 0x56, 0xe9, 0x4c,
+	0x0b, 0x36, 0x48, 0x2e, 0x37, 0xc8, 0x10, 0xbc, 0x0c, 0xb9, 0xa4, 0xa7, 0x34, 0x4b, 0x25, 0xfa,
+	0x3b, 0x3a, 0xdf, 0xde, 0x0a, 0x1e, 0x81, 0xd7, 0x82, 0x25, 0x77, 0xa0, 0x7b, 0x86, 0x0b, 0x6d,
+	0x88, 0x41, 0xa2, 0x96, 0xe4, 0x3d, 0xd8, 0x39, 0x4f, 0x67, 0x75, 0x33, 0x8a, 0x09, 0x0e, 0x3a,
+	0x0f, 0x9d, 0xe0, 0x77, 0x07, 0xfa, 0x8d, 0x53, 0x23, 0xe8, 0xa9, 0xb7, 0xc0, 0x3a, 0x29, 0x88,
+	0x8c, 0xc3, 0xa3, 0xe6, 0x0d, 0x88, 0x8e, 0x9b, 0x57, 0x24, 0xd1, 0x75, 0xe4, 0x19, 0xf4, 0x0a,
+	0x29, 0x2b, 0x6b, 0x97, 0x07, 0xdb, 0xca, 0x30, 0x96, 0xb2, 0x6a, 0x8e, 0x59, 0x03, 0x04, 0x6f,
+	0xba, 0xe0, 0xb5, 0x76, 0xc9, 0x1e, 0x74, 0x68, 0x6e, 0xf9, 0x77, 0x68, 0x4e, 0xee, 0x81, 0x3b,
+	0x47, 0x59, 0xb0, 0xdc, 0xf2, 0xb7, 0x11, 0x79, 0x09, 0xfd, 0x02, 0xd3, 0x1c, 0xb9, 0xf0, 0xbb,
+	0xfa, 0x28, 0xbe, 0xbf, 0x06, 0x87, 0x68, 0x6c, 0x20, 0xcc, 0x91, 0x34, 0x80, 0x84, 0x40, 0xaf,
+	0x4a, 0x65, 0x61, 0x8f, 0x43, 0xaf, 0xd5, 0x5e, 0xc1, 0x84, 0xb4, 0x47, 0xa0, 0xd7, 0x8a, 0x9b,
+	0xc8, 0x0a, 0x9c, 0xa3, 0xef, 0x1a, 0x6e, 0x26, 0x52, 0x92, 0xbf, 0xaa, 0x91, 0x2f, 0xfc, 0xbe,
+	0x91, 0x5c, 0x07, 0x24, 0x80, 0x9b, 0xa7, 0x3c, 0x9d, 0xce, 0xb1, 0x94, 0xfe, 0x4d, 0x9d, 0x58,
+	0xc6, 0x0a, 0x5d, 0xd0, 0x0b, 0xf4, 0x07, 0x43, 0x67, 0xbf, 0x9b, 0xe8, 0xb5, 0xaa, 0xd7, 0xf2,
+	0x67, 0x6c, 0xe6, 0x83, 0xa9, 0x6f, 0x62, 0x55, 0x3f, 0x61, 0xf9, 0xc2, 0xf7, 0x0c, 0x1b, 0xb5,
+	0x0e, 0x0e, 0xe0, 0x56, 0x7b, 0x9c, 0x2b, 0x59, 0xe1, 0x08, 0xee, 0xbd, 0xfd, 0x3d, 0xb9, 0x0a,
+	0x4a, 0x38, 0x04, 0x50, 0xe2, 0xfe, 0xac, 0x3f, 0x89, 0x8a, 0x63, 0xc6, 0x72, 0x63, 0xa9, 0x9d,
+	0x44, 0xaf, 0xc3, 0x6f, 0xc0, 0x33, 0x1c, 0x7f, 0x51, 0x4d, 0xdb, 0x82, 0x87, 0x6f, 0x1c, 0xb8,
+	0xdb, 0xea, 0x7b, 0x5e, 0xe9, 0x2f, 0xcd, 0x01, 0xb8, 0xe6, 0xc4, 0xac, 0x6b, 0xc3, 0x0d, 0x0e,
+	0x68, 0x75, 0x26, 0xb6, 0x83, 0x8c, 0xc0, 0x55, 0x9f, 0xf4, 0x32, 0xf7, 0x3b, 0x1b, 0x1c, 0x7f,
+	0xc8, 0xd8, 0xcc, 0xf6, 0x98, 0xca, 0xf0, 0x4f, 0x07, 0xc8, 0x11, 0x96, 0x14, 0x73, 0x63, 0x21,
+	0x51, 0xb1, 0x52, 0x20, 0x79, 0x04, 0xae, 0xb9, 0x04, 0x2c, 0x8d, 0xfb, 0x9b, 0x68, 0x2c, 0xa5,
+	0x49, 0x6c, 0x03, 0x39, 0xbc, 0x34, 0x71, 0x47, 0x9b, 0x78, 0xff, 0xff, 0x47, 0x30, 0xc3, 0xaf,
+	0x98, 0x55, 0x5b, 0xa1, 0x7b, 0x69, 0x85, 0xf0, 0x18, 0xf6, 0x9e, 0x9f, 0xad, 0x90, 0x7c, 0x07,
+	0x4f, 0x0a, 0xff, 0x72, 0x60, 0xd7, 0x5e, 0x6f, 0x16, 0xf5, 0xcb, 0xb5, 0xd1, 0x49, 0xa3, 0x22,
+	0xaf, 0xb2, 0x68, 0x6d, 0xd6, 0x63, 0xb8, 0x9d, 0x6b, 0xf1, 0x4e, 0xb8, 0x6d, 0xb7, 0xd2, 0x7f,
+	0xb1, 0x81, 0xc9, 0xbf, 0xa5, 0x1e, 0xdf, 0x48, 0xf6, 0x0c, 0xc6, 0x92, 0xc1, 0x18, 0x3c, 0x76,
+	0x76, 0x89, 0xd8, 0xd5, 0x88, 0x9f, 0x6d, 0x40, 0x5c, 0xd5, 0x64, 0x7c, 0x23, 0x01, 0xb6, 0x9c,
+	0xe5, 0xf0, 0x36, 0xec, 0xaa, 0x0f, 0xd2, 0x12, 0x6b, 0x94, 0xc1, 0xee, 0xe3, 0x5a, 0x16, 0x8c,
+	0xd3, 0x0b, 0x73, 0xb3, 0x25, 0xb0, 0xa3, 0xc7, 0x27, 0x9f, 0x6c, 0xc0, 0x6f, 0xdf, 0xfd, 0xc1,
+	0xa7, 0xff, 0x5d, 0x64, 0x9f, 0x1a, 0xbf, 0xfc, 0x7a, 0x4a, 0x65, 0x51, 0x4f, 0xa2, 0x8c, 0xcd,
+	0xe3, 0x0b, 0x2c, 0x99, 0x10, 0xf1, 0x94, 0x57, 0x99, 0x44, 0x21, 0xe3, 0x95, 0x9f, 0x38, 0xdf,
+	0xaa, 0x3f, 0x13, 0x57, 0x1b, 0xf4, 0xc1, 0x3f, 0x03, 0x00, 0x6e, 0x9e, 0xe5, 0xbe, 0xff, 0x08,
+	0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// AuthorizationClient is the client API for Authorization service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type AuthorizationClient interface {
+	Check(ctx context.Context, in *CheckRequest, opts ...grpc.CallOption) (*CheckResponse, error)
+}
+
+type authorizationClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewAuthorizationClient(cc *grpc.ClientConn) AuthorizationClient {
+	return &authorizationClient{cc}
+}
+
+func (c *authorizationClient) Check(ctx context.Context, in *CheckRequest, opts ...grpc.CallOption) (*CheckResponse, error) {
+	out := new(CheckResponse)
+	err := c.cc.Invoke(ctx, "/envoy.service.auth.v2.Authorization/Check", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AuthorizationServer is the server API for Authorization service.
+type AuthorizationServer interface {
+	Check(context.Context, *CheckRequest) (*CheckResponse, error)
+}
+
+// UnimplementedAuthorizationServer can be embedded to have forward compatible implementations.
+type UnimplementedAuthorizationServer struct {
+}
+
+func (*UnimplementedAuthorizationServer) Check(ctx context.Context, req *CheckRequest) (*CheckResponse, error) {
+	return nil, status1.Errorf(codes.Unimplemented, "method Check not implemented")
+}
+
+func RegisterAuthorizationServer(s *grpc.Server, srv AuthorizationServer) {
+	s.RegisterService(&_Authorization_serviceDesc, srv)
+}
+
+func _Authorization_Check_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AuthorizationServer).Check(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/envoy.service.auth.v2.Authorization/Check",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AuthorizationServer).Check(ctx, req.(*CheckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Authorization_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "envoy.service.auth.v2.Authorization",
+	HandlerType: (*AuthorizationServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Check",
+			Handler:    _Authorization_Check_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pb/envoy/auth/external_auth.proto",
+}
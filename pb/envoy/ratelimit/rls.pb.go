@@ -0,0 +1,642 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: pb/envoy/ratelimit/rls.proto
+
+// The subset of Envoy's envoy.service.ratelimit.v2 rate limit service API
+// that grpctest serves. Field numbers match envoy/service/ratelimit/v2 and
+// the envoy.api.v2.ratelimit and envoy.api.v2.core messages it uses, so
+// Envoy's envoy.rate_limit filter can call it.
+
+package ratelimit
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type RateLimitResponse_Code int32
+
+const (
+	RateLimitResponse_UNKNOWN    RateLimitResponse_Code = 0
+	RateLimitResponse_OK         RateLimitResponse_Code = 1
+	RateLimitResponse_OVER_LIMIT RateLimitResponse_Code = 2
+)
+
+var RateLimitResponse_Code_name = map[int32]string{
+	0: "UNKNOWN",
+	1: "OK",
+	2: "OVER_LIMIT",
+}
+
+var RateLimitResponse_Code_value = map[string]int32{
+	"UNKNOWN":    0,
+	"OK":         1,
+	"OVER_LIMIT": 2,
+}
+
+func (x RateLimitResponse_Code) String() string {
+	return proto.EnumName(RateLimitResponse_Code_name, int32(x))
+}
+
+func (RateLimitResponse_Code) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_4e9e45cc153805c9, []int{3, 0}
+}
+
+type RateLimitResponse_RateLimit_Unit int32
+
+const (
+	RateLimitResponse_RateLimit_UNKNOWN RateLimitResponse_RateLimit_Unit = 0
+	RateLimitResponse_RateLimit_SECOND  RateLimitResponse_RateLimit_Unit = 1
+	RateLimitResponse_RateLimit_MINUTE  RateLimitResponse_RateLimit_Unit = 2
+	RateLimitResponse_RateLimit_HOUR    RateLimitResponse_RateLimit_Unit = 3
+	RateLimitResponse_RateLimit_DAY     RateLimitResponse_RateLimit_Unit = 4
+)
+
+var RateLimitResponse_RateLimit_Unit_name = map[int32]string{
+	0: "UNKNOWN",
+	1: "SECOND",
+	2: "MINUTE",
+	3: "HOUR",
+	4: "DAY",
+}
+
+var RateLimitResponse_RateLimit_Unit_value = map[string]int32{
+	"UNKNOWN": 0,
+	"SECOND":  1,
+	"MINUTE":  2,
+	"HOUR":    3,
+	"DAY":     4,
+}
+
+func (x RateLimitResponse_RateLimit_Unit) String() string {
+	return proto.EnumName(RateLimitResponse_RateLimit_Unit_name, int32(x))
+}
+
+func (RateLimitResponse_RateLimit_Unit) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_4e9e45cc153805c9, []int{3, 0, 0}
+}
+
+type RateLimitRequest struct {
+	Domain               string                 `protobuf:"bytes,1,opt,name=domain,proto3" json:"domain,omitempty"`
+	Descriptors          []*RateLimitDescriptor `protobuf:"bytes,2,rep,name=descriptors,proto3" json:"descriptors,omitempty"`
+	HitsAddend           uint32                 `protobuf:"varint,3,opt,name=hits_addend,json=hitsAddend,proto3" json:"hits_addend,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}               `json:"-"`
+	XXX_unrecognized     []byte                 `json:"-"`
+	XXX_sizecache        int32                  `json:"-"`
+}
+
+func (m *RateLimitRequest) Reset()         { *m = RateLimitRequest{} }
+func (m *RateLimitRequest) String() string { return proto.CompactTextString(m) }
+func (*RateLimitRequest) ProtoMessage()    {}
+func (*RateLimitRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4e9e45cc153805c9, []int{0}
+}
+
+func (m *RateLimitRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RateLimitRequest.Unmarshal(m, b)
+}
+func (m *RateLimitRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RateLimitRequest.Marshal(b, m, deterministic)
+}
+func (m *RateLimitRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RateLimitRequest.Merge(m, src)
+}
+func (m *RateLimitRequest) XXX_Size() int {
+	return xxx_messageInfo_RateLimitRequest.Size(m)
+}
+func (m *RateLimitRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RateLimitRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RateLimitRequest proto.InternalMessageInfo
+
+func (m *RateLimitRequest) GetDomain() string {
+	if m != nil {
+		return m.Domain
+	}
+	return ""
+}
+
+func (m *RateLimitRequest) GetDescriptors() []*RateLimitDescriptor {
+	if m != nil {
+		return m.Descriptors
+	}
+	return nil
+}
+
+func (m *RateLimitRequest) GetHitsAddend() uint32 {
+	if m != nil {
+		return m.HitsAddend
+	}
+	return 0
+}
+
+// envoy.api.v2.ratelimit.RateLimitDescriptor
+type RateLimitDescriptor struct {
+	Entries              []*RateLimitDescriptor_Entry           `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	Limit                *RateLimitDescriptor_RateLimitOverride `protobuf:"bytes,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                               `json:"-"`
+	XXX_unrecognized     []byte                                 `json:"-"`
+	XXX_sizecache        int32                                  `json:"-"`
+}
+
+func (m *RateLimitDescriptor) Reset()         { *m = RateLimitDescriptor{} }
+func (m *RateLimitDescriptor) String() string { return proto.CompactTextString(m) }
+func (*RateLimitDescriptor) ProtoMessage()    {}
+func (*RateLimitDescriptor) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4e9e45cc153805c9, []int{1}
+}
+
+func (m *RateLimitDescriptor) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RateLimitDescriptor.Unmarshal(m, b)
+}
+func (m *RateLimitDescriptor) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RateLimitDescriptor.Marshal(b, m, deterministic)
+}
+func (m *RateLimitDescriptor) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RateLimitDescriptor.Merge(m, src)
+}
+func (m *RateLimitDescriptor) XXX_Size() int {
+	return xxx_messageInfo_RateLimitDescriptor.Size(m)
+}
+func (m *RateLimitDescriptor) XXX_DiscardUnknown() {
+	xxx_messageInfo_RateLimitDescriptor.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RateLimitDescriptor proto.InternalMessageInfo
+
+func (m *RateLimitDescriptor) GetEntries() []*RateLimitDescriptor_Entry {
+	if m != nil {
+		return m.Entries
+	}
+	return nil
+}
+
+func (m *RateLimitDescriptor) GetLimit() *RateLimitDescriptor_RateLimitOverride {
+	if m != nil {
+		return m.Limit
+	}
+	return nil
+}
+
+type RateLimitDescriptor_Entry struct {
+	Key                  string   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value                string   `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RateLimitDescriptor_Entry) Reset()         { *m = RateLimitDescriptor_Entry{} }
+func (m *RateLimitDescriptor_Entry) String() string { return proto.CompactTextString(m) }
+func (*RateLimitDescriptor_Entry) ProtoMessage()    {}
+func (*RateLimitDescriptor_Entry) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4e9e45cc153805c9, []int{1, 0}
+}
+
+func (m *RateLimitDescriptor_Entry) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RateLimitDescriptor_Entry.Unmarshal(m, b)
+}
+func (m *RateLimitDescriptor_Entry) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RateLimitDescriptor_Entry.Marshal(b, m, deterministic)
+}
+func (m *RateLimitDescriptor_Entry) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RateLimitDescriptor_Entry.Merge(m, src)
+}
+func (m *RateLimitDescriptor_Entry) XXX_Size() int {
+	return xxx_messageInfo_RateLimitDescriptor_Entry.Size(m)
+}
+func (m *RateLimitDescriptor_Entry) XXX_DiscardUnknown() {
+	xxx_messageInfo_RateLimitDescriptor_Entry.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RateLimitDescriptor_Entry proto.InternalMessageInfo
+
+func (m *RateLimitDescriptor_Entry) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *RateLimitDescriptor_Entry) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+type RateLimitDescriptor_RateLimitOverride struct {
+	RequestsPerUnit      uint32                           `protobuf:"varint,1,opt,name=requests_per_unit,json=requestsPerUnit,proto3" json:"requests_per_unit,omitempty"`
+	Unit                 RateLimitResponse_RateLimit_Unit `protobuf:"varint,2,opt,name=unit,proto3,enum=envoy.service.ratelimit.v2.RateLimitResponse_RateLimit_Unit" json:"unit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                         `json:"-"`
+	XXX_unrecognized     []byte                           `json:"-"`
+	XXX_sizecache        int32                            `json:"-"`
+}
+
+func (m *RateLimitDescriptor_RateLimitOverride) Reset()         { *m = RateLimitDescriptor_RateLimitOverride{} }
+func (m *RateLimitDescriptor_RateLimitOverride) String() string { return proto.CompactTextString(m) }
+func (*RateLimitDescriptor_RateLimitOverride) ProtoMessage()    {}
+func (*RateLimitDescriptor_RateLimitOverride) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4e9e45cc153805c9, []int{1, 1}
+}
+
+func (m *RateLimitDescriptor_RateLimitOverride) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RateLimitDescriptor_RateLimitOverride.Unmarshal(m, b)
+}
+func (m *RateLimitDescriptor_RateLimitOverride) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RateLimitDescriptor_RateLimitOverride.Marshal(b, m, deterministic)
+}
+func (m *RateLimitDescriptor_RateLimitOverride) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RateLimitDescriptor_RateLimitOverride.Merge(m, src)
+}
+func (m *RateLimitDescriptor_RateLimitOverride) XXX_Size() int {
+	return xxx_messageInfo_RateLimitDescriptor_RateLimitOverride.Size(m)
+}
+func (m *RateLimitDescriptor_RateLimitOverride) XXX_DiscardUnknown() {
+	xxx_messageInfo_RateLimitDescriptor_RateLimitOverride.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RateLimitDescriptor_RateLimitOverride proto.InternalMessageInfo
+
+func (m *RateLimitDescriptor_RateLimitOverride) GetRequestsPerUnit() uint32 {
+	if m != nil {
+		return m.RequestsPerUnit
+	}
+	return 0
+}
+
+func (m *RateLimitDescriptor_RateLimitOverride) GetUnit() RateLimitResponse_RateLimit_Unit {
+	if m != nil {
+		return m.Unit
+	}
+	return RateLimitResponse_RateLimit_UNKNOWN
+}
+
+// envoy.api.v2.core.HeaderValue
+type HeaderValue struct {
+	Key                  string   `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value                string   `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *HeaderValue) Reset()         { *m = HeaderValue{} }
+func (m *HeaderValue) String() string { return proto.CompactTextString(m) }
+func (*HeaderValue) ProtoMessage()    {}
+func (*HeaderValue) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4e9e45cc153805c9, []int{2}
+}
+
+func (m *HeaderValue) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HeaderValue.Unmarshal(m, b)
+}
+func (m *HeaderValue) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HeaderValue.Marshal(b, m, deterministic)
+}
+func (m *HeaderValue) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HeaderValue.Merge(m, src)
+}
+func (m *HeaderValue) XXX_Size() int {
+	return xxx_messageInfo_HeaderValue.Size(m)
+}
+func (m *HeaderValue) XXX_DiscardUnknown() {
+	xxx_messageInfo_HeaderValue.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HeaderValue proto.InternalMessageInfo
+
+func (m *HeaderValue) GetKey() string {
+	if m != nil {
+		return m.Key
+	}
+	return ""
+}
+
+func (m *HeaderValue) GetValue() string {
+	if m != nil {
+		return m.Value
+	}
+	return ""
+}
+
+type RateLimitResponse struct {
+	OverallCode          RateLimitResponse_Code                `protobuf:"varint,1,opt,name=overall_code,json=overallCode,proto3,enum=envoy.service.ratelimit.v2.RateLimitResponse_Code" json:"overall_code,omitempty"`
+	Statuses             []*RateLimitResponse_DescriptorStatus `protobuf:"bytes,2,rep,name=statuses,proto3" json:"statuses,omitempty"`
+	Headers              []*HeaderValue                        `protobuf:"bytes,3,rep,name=headers,proto3" json:"headers,omitempty"`
+	RequestHeadersToAdd  []*HeaderValue                        `protobuf:"bytes,4,rep,name=request_headers_to_add,json=requestHeadersToAdd,proto3" json:"request_headers_to_add,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                              `json:"-"`
+	XXX_unrecognized     []byte                                `json:"-"`
+	XXX_sizecache        int32                                 `json:"-"`
+}
+
+func (m *RateLimitResponse) Reset()         { *m = RateLimitResponse{} }
+func (m *RateLimitResponse) String() string { return proto.CompactTextString(m) }
+func (*RateLimitResponse) ProtoMessage()    {}
+func (*RateLimitResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4e9e45cc153805c9, []int{3}
+}
+
+func (m *RateLimitResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RateLimitResponse.Unmarshal(m, b)
+}
+func (m *RateLimitResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RateLimitResponse.Marshal(b, m, deterministic)
+}
+func (m *RateLimitResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RateLimitResponse.Merge(m, src)
+}
+func (m *RateLimitResponse) XXX_Size() int {
+	return xxx_messageInfo_RateLimitResponse.Size(m)
+}
+func (m *RateLimitResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_RateLimitResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RateLimitResponse proto.InternalMessageInfo
+
+func (m *RateLimitResponse) GetOverallCode() RateLimitResponse_Code {
+	if m != nil {
+		return m.OverallCode
+	}
+	return RateLimitResponse_UNKNOWN
+}
+
+func (m *RateLimitResponse) GetStatuses() []*RateLimitResponse_DescriptorStatus {
+	if m != nil {
+		return m.Statuses
+	}
+	return nil
+}
+
+func (m *RateLimitResponse) GetHeaders() []*HeaderValue {
+	if m != nil {
+		return m.Headers
+	}
+	return nil
+}
+
+func (m *RateLimitResponse) GetRequestHeadersToAdd() []*HeaderValue {
+	if m != nil {
+		return m.RequestHeadersToAdd
+	}
+	return nil
+}
+
+type RateLimitResponse_RateLimit struct {
+	RequestsPerUnit      uint32                           `protobuf:"varint,1,opt,name=requests_per_unit,json=requestsPerUnit,proto3" json:"requests_per_unit,omitempty"`
+	Unit                 RateLimitResponse_RateLimit_Unit `protobuf:"varint,2,opt,name=unit,proto3,enum=envoy.service.ratelimit.v2.RateLimitResponse_RateLimit_Unit" json:"unit,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                         `json:"-"`
+	XXX_unrecognized     []byte                           `json:"-"`
+	XXX_sizecache        int32                            `json:"-"`
+}
+
+func (m *RateLimitResponse_RateLimit) Reset()         { *m = RateLimitResponse_RateLimit{} }
+func (m *RateLimitResponse_RateLimit) String() string { return proto.CompactTextString(m) }
+func (*RateLimitResponse_RateLimit) ProtoMessage()    {}
+func (*RateLimitResponse_RateLimit) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4e9e45cc153805c9, []int{3, 0}
+}
+
+func (m *RateLimitResponse_RateLimit) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RateLimitResponse_RateLimit.Unmarshal(m, b)
+}
+func (m *RateLimitResponse_RateLimit) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RateLimitResponse_RateLimit.Marshal(b, m, deterministic)
+}
+func (m *RateLimitResponse_RateLimit) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RateLimitResponse_RateLimit.Merge(m, src)
+}
+func (m *RateLimitResponse_RateLimit) XXX_Size() int {
+	return xxx_messageInfo_RateLimitResponse_RateLimit.Size(m)
+}
+func (m *RateLimitResponse_RateLimit) XXX_DiscardUnknown() {
+	xxx_messageInfo_RateLimitResponse_RateLimit.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RateLimitResponse_RateLimit proto.InternalMessageInfo
+
+func (m *RateLimitResponse_RateLimit) GetRequestsPerUnit() uint32 {
+	if m != nil {
+		return m.RequestsPerUnit
+	}
+	return 0
+}
+
+func (m *RateLimitResponse_RateLimit) GetUnit() RateLimitResponse_RateLimit_Unit {
+	if m != nil {
+		return m.Unit
+	}
+	return RateLimitResponse_RateLimit_UNKNOWN
+}
+
+type RateLimitResponse_DescriptorStatus struct {
+	Code                 RateLimitResponse_Code       `protobuf:"varint,1,opt,name=code,proto3,enum=envoy.service.ratelimit.v2.RateLimitResponse_Code" json:"code,omitempty"`
+	CurrentLimit         *RateLimitResponse_RateLimit `protobuf:"bytes,2,opt,name=current_limit,json=currentLimit,proto3" json:"current_limit,omitempty"`
+	LimitRemaining       uint32                       `protobuf:"varint,3,opt,name=limit_remaining,json=limitRemaining,proto3" json:"limit_remaining,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                     `json:"-"`
+	XXX_unrecognized     []byte                       `json:"-"`
+	XXX_sizecache        int32                        `json:"-"`
+}
+
+func (m *RateLimitResponse_DescriptorStatus) Reset()         { *m = RateLimitResponse_DescriptorStatus{} }
+func (m *RateLimitResponse_DescriptorStatus) String() string { return proto.CompactTextString(m) }
+func (*RateLimitResponse_DescriptorStatus) ProtoMessage()    {}
+func (*RateLimitResponse_DescriptorStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_4e9e45cc153805c9, []int{3, 1}
+}
+
+func (m *RateLimitResponse_DescriptorStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RateLimitResponse_DescriptorStatus.Unmarshal(m, b)
+}
+func (m *RateLimitResponse_DescriptorStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RateLimitResponse_DescriptorStatus.Marshal(b, m, deterministic)
+}
+func (m *RateLimitResponse_DescriptorStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RateLimitResponse_DescriptorStatus.Merge(m, src)
+}
+func (m *RateLimitResponse_DescriptorStatus) XXX_Size() int {
+	return xxx_messageInfo_RateLimitResponse_DescriptorStatus.Size(m)
+}
+func (m *RateLimitResponse_DescriptorStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_RateLimitResponse_DescriptorStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RateLimitResponse_DescriptorStatus proto.InternalMessageInfo
+
+func (m *RateLimitResponse_DescriptorStatus) GetCode() RateLimitResponse_Code {
+	if m != nil {
+		return m.Code
+	}
+	return RateLimitResponse_UNKNOWN
+}
+
+func (m *RateLimitResponse_DescriptorStatus) GetCurrentLimit() *RateLimitResponse_RateLimit {
+	if m != nil {
+		return m.CurrentLimit
+	}
+	return nil
+}
+
+func (m *RateLimitResponse_DescriptorStatus) GetLimitRemaining() uint32 {
+	if m != nil {
+		return m.LimitRemaining
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterEnum("envoy.service.ratelimit.v2.RateLimitResponse_Code", RateLimitResponse_Code_name, RateLimitResponse_Code_value)
+	proto.RegisterEnum("envoy.service.ratelimit.v2.RateLimitResponse_RateLimit_Unit", RateLimitResponse_RateLimit_Unit_name, RateLimitResponse_RateLimit_Unit_value)
+	proto.RegisterType((*RateLimitRequest)(nil), "envoy.service.ratelimit.v2.RateLimitRequest")
+	proto.RegisterType((*RateLimitDescriptor)(nil), "envoy.service.ratelimit.v2.RateLimitDescriptor")
+	proto.RegisterType((*RateLimitDescriptor_Entry)(nil), "envoy.service.ratelimit.v2.RateLimitDescriptor.Entry")
+	proto.RegisterType((*RateLimitDescriptor_RateLimitOverride)(nil), "envoy.service.ratelimit.v2.RateLimitDescriptor.RateLimitOverride")
+	proto.RegisterType((*HeaderValue)(nil), "envoy.service.ratelimit.v2.HeaderValue")
+	proto.RegisterType((*RateLimitResponse)(nil), "envoy.service.ratelimit.v2.RateLimitResponse")
+	proto.RegisterType((*RateLimitResponse_RateLimit)(nil), "envoy.service.ratelimit.v2.RateLimitResponse.RateLimit")
+	proto.RegisterType((*RateLimitResponse_DescriptorStatus)(nil), "envoy.service.ratelimit.v2.RateLimitResponse.DescriptorStatus")
+}
+
+func init() { proto.RegisterFile("pb/envoy/ratelimit/rls.proto", fileDescriptor_4e9e45cc153805c9) }
+
+var fileDescriptor_4e9e45cc153805c9 = []byte{
+	// 654 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x95, 0xdf, 0x4e, 0xdb, 0x3e,
+	0x14, 0xc7, 0x7f, 0x69, 0x4b, 0x0b, 0xa7, 0x50, 0x82, 0xf9, 0x09, 0x45, 0xd5, 0xa4, 0x55, 0xbd,
+	0xa1, 0xda, 0x9f, 0x44, 0xca, 0x84, 0xd0, 0xb4, 0x09, 0xa9, 0x83, 0x4e, 0x20, 0xa0, 0x61, 0x2e,
+	0x05, 0x0d, 0x21, 0x45, 0xa1, 0x39, 0xa2, 0xd1, 0x42, 0xdc, 0xd9, 0x4e, 0x25, 0x76, 0x39, 0xed,
+	0x05, 0xf6, 0x02, 0x7b, 0x9a, 0xdd, 0xec, 0x31, 0xf6, 0x26, 0x53, 0x9c, 0x34, 0x74, 0x63, 0x9b,
+	0xe8, 0x76, 0xb1, 0x3b, 0xfb, 0xeb, 0x9c, 0x8f, 0xcf, 0xd7, 0xe7, 0xc4, 0x86, 0x7b, 0xa3, 0x0b,
+	0x0b, 0xa3, 0x31, 0xbb, 0xb6, 0xb8, 0x27, 0x31, 0x0c, 0xae, 0x02, 0x69, 0xf1, 0x50, 0x98, 0x23,
+	0xce, 0x24, 0x23, 0x75, 0xb5, 0x64, 0x0a, 0xe4, 0xe3, 0x60, 0x80, 0x66, 0xfe, 0x89, 0x39, 0xb6,
+	0x9b, 0x9f, 0x34, 0xd0, 0xa9, 0x27, 0xf1, 0x20, 0x11, 0x28, 0xbe, 0x8d, 0x51, 0x48, 0xb2, 0x06,
+	0x65, 0x9f, 0x5d, 0x79, 0x41, 0x64, 0x68, 0x0d, 0xad, 0xb5, 0x40, 0xb3, 0x19, 0x79, 0x05, 0x55,
+	0x1f, 0xc5, 0x80, 0x07, 0x23, 0xc9, 0xb8, 0x30, 0x0a, 0x8d, 0x62, 0xab, 0x6a, 0x5b, 0xe6, 0xaf,
+	0xf1, 0x66, 0x8e, 0xde, 0xc9, 0xe3, 0xe8, 0x34, 0x83, 0xdc, 0x87, 0xea, 0x30, 0x90, 0xc2, 0xf5,
+	0x7c, 0x1f, 0x23, 0xdf, 0x28, 0x36, 0xb4, 0xd6, 0x12, 0x85, 0x44, 0x6a, 0x2b, 0xa5, 0xf9, 0xa1,
+	0x08, 0xab, 0x3f, 0xa1, 0x10, 0x07, 0x2a, 0x18, 0x49, 0x1e, 0xa0, 0x30, 0x34, 0x95, 0xc7, 0xc6,
+	0x8c, 0x79, 0x98, 0x9d, 0x48, 0xf2, 0x6b, 0x3a, 0xa1, 0x90, 0x53, 0x98, 0x53, 0x9f, 0x1b, 0x85,
+	0x86, 0xd6, 0xaa, 0xda, 0xed, 0x59, 0x71, 0xb9, 0xe6, 0x8c, 0x91, 0xf3, 0xc0, 0x47, 0x9a, 0xf2,
+	0xea, 0x16, 0xcc, 0xa9, 0xad, 0x88, 0x0e, 0xc5, 0x37, 0x78, 0x9d, 0x9d, 0x69, 0x32, 0x24, 0xff,
+	0xc3, 0xdc, 0xd8, 0x0b, 0x63, 0x54, 0x7b, 0x2e, 0xd0, 0x74, 0x52, 0xff, 0xa8, 0xc1, 0xca, 0x2d,
+	0x1a, 0x79, 0x00, 0x2b, 0x3c, 0xad, 0x8f, 0x70, 0x47, 0xc8, 0xdd, 0x38, 0x0a, 0xa4, 0x62, 0x2d,
+	0xd1, 0xe5, 0xc9, 0xc2, 0x11, 0xf2, 0x7e, 0x14, 0x48, 0x72, 0x04, 0x25, 0xb5, 0x9c, 0x60, 0x6b,
+	0xf6, 0xf3, 0x3b, 0x59, 0xa1, 0x28, 0x46, 0x2c, 0x12, 0x78, 0xa3, 0x98, 0x09, 0x8b, 0x2a, 0x52,
+	0x73, 0x03, 0xaa, 0xbb, 0xe8, 0xf9, 0xc8, 0x4f, 0x92, 0x14, 0xef, 0x6a, 0xa5, 0xf9, 0xb9, 0x0c,
+	0x2b, 0xb7, 0x76, 0x20, 0x7d, 0x58, 0x64, 0x63, 0xe4, 0x5e, 0x18, 0xba, 0x03, 0xe6, 0xa3, 0xc2,
+	0xd4, 0x6c, 0x7b, 0xb6, 0x34, 0xb7, 0x99, 0x8f, 0xb4, 0x9a, 0x71, 0x92, 0x09, 0x39, 0x83, 0x79,
+	0x21, 0x3d, 0x19, 0x0b, 0x9c, 0xf4, 0xe6, 0xd6, 0x6c, 0xc8, 0x9b, 0x6a, 0xf6, 0x14, 0x87, 0xe6,
+	0x3c, 0xd2, 0x86, 0xca, 0x50, 0xf9, 0x17, 0x46, 0x51, 0xa1, 0xd7, 0x7f, 0x87, 0x9e, 0x3a, 0x2a,
+	0x3a, 0x89, 0x23, 0xe7, 0xb0, 0x96, 0xd5, 0xc9, 0xcd, 0x24, 0x57, 0xb2, 0xa4, 0xf1, 0x8d, 0xd2,
+	0x6c, 0xc4, 0xd5, 0x0c, 0x93, 0x6a, 0xe2, 0x98, 0xb5, 0x7d, 0xbf, 0xfe, 0x45, 0x83, 0x85, 0xdc,
+	0xd1, 0x3f, 0x6e, 0x96, 0x2d, 0x28, 0x29, 0x72, 0x15, 0x2a, 0xfd, 0xee, 0x7e, 0xd7, 0x39, 0xed,
+	0xea, 0xff, 0x11, 0x80, 0x72, 0xaf, 0xb3, 0xed, 0x74, 0x77, 0x74, 0x2d, 0x19, 0x1f, 0xee, 0x75,
+	0xfb, 0xc7, 0x1d, 0xbd, 0x40, 0xe6, 0xa1, 0xb4, 0xeb, 0xf4, 0xa9, 0x5e, 0x24, 0x15, 0x28, 0xee,
+	0xb4, 0x5f, 0xeb, 0xa5, 0xfa, 0x57, 0x0d, 0xf4, 0x1f, 0x6b, 0x41, 0x5e, 0x42, 0xe9, 0x2f, 0x9b,
+	0x45, 0xc5, 0x93, 0x73, 0x58, 0x1a, 0xc4, 0x9c, 0x63, 0x24, 0xdd, 0xe9, 0xff, 0x7d, 0xf3, 0x0f,
+	0x7d, 0xd3, 0xc5, 0x8c, 0x96, 0x1e, 0xfc, 0x3a, 0x2c, 0xab, 0x28, 0x97, 0x63, 0x72, 0x65, 0x06,
+	0xd1, 0x65, 0x76, 0xa7, 0xd5, 0xc2, 0x34, 0x3e, 0x53, 0x9b, 0x0f, 0xa1, 0xa4, 0x9a, 0xf6, 0xbb,
+	0x33, 0x2a, 0x43, 0xc1, 0xd9, 0xd7, 0x35, 0x52, 0x03, 0x70, 0x4e, 0x3a, 0xd4, 0x3d, 0xd8, 0x3b,
+	0xdc, 0x3b, 0xd6, 0x0b, 0xf6, 0xfb, 0xe9, 0x5b, 0xba, 0x97, 0x66, 0x48, 0x22, 0x58, 0xee, 0x0d,
+	0x59, 0x1c, 0xfa, 0x37, 0x65, 0x7f, 0x74, 0x47, 0x13, 0xaa, 0x01, 0xea, 0x8f, 0x67, 0xb2, 0xfc,
+	0xe2, 0xe9, 0xd9, 0xe6, 0x65, 0x20, 0x87, 0xf1, 0x85, 0x39, 0x60, 0x57, 0xd6, 0x3b, 0x8c, 0x98,
+	0x10, 0xd6, 0x25, 0x1f, 0x0d, 0x24, 0x0a, 0x69, 0xdd, 0x7e, 0x81, 0x9e, 0xe5, 0xa3, 0x8b, 0xb2,
+	0x7a, 0x88, 0x9e, 0x7c, 0x1b, 0x00, 0x12, 0x02, 0x8e, 0x39, 0xa8, 0x06, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// RateLimitServiceClient is the client API for RateLimitService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type RateLimitServiceClient interface {
+	ShouldRateLimit(ctx context.Context, in *RateLimitRequest, opts ...grpc.CallOption) (*RateLimitResponse, error)
+}
+
+type rateLimitServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewRateLimitServiceClient(cc *grpc.ClientConn) RateLimitServiceClient {
+	return &rateLimitServiceClient{cc}
+}
+
+func (c *rateLimitServiceClient) ShouldRateLimit(ctx context.Context, in *RateLimitRequest, opts ...grpc.CallOption) (*RateLimitResponse, error) {
+	out := new(RateLimitResponse)
+	err := c.cc.Invoke(ctx, "/envoy.service.ratelimit.v2.RateLimitService/ShouldRateLimit", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RateLimitServiceServer is the server API for RateLimitService service.
+type RateLimitServiceServer interface {
+	ShouldRateLimit(context.Context, *RateLimitRequest) (*RateLimitResponse, error)
+}
+
+// UnimplementedRateLimitServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedRateLimitServiceServer struct {
+}
+
+func (*UnimplementedRateLimitServiceServer) ShouldRateLimit(ctx context.Context, req *RateLimitRequest) (*RateLimitResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ShouldRateLimit not implemented")
+}
+
+func RegisterRateLimitServiceServer(s *grpc.Server, srv RateLimitServiceServer) {
+	s.RegisterService(&_RateLimitService_serviceDesc, srv)
+}
+
+func _RateLimitService_ShouldRateLimit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RateLimitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateLimitServiceServer).ShouldRateLimit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/envoy.service.ratelimit.v2.RateLimitService/ShouldRateLimit",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateLimitServiceServer).ShouldRateLimit(ctx, req.(*RateLimitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _RateLimitService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "envoy.service.ratelimit.v2.RateLimitService",
+	HandlerType: (*RateLimitServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ShouldRateLimit",
+			Handler:    _RateLimitService_ShouldRateLimit_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pb/envoy/ratelimit/rls.proto",
+}
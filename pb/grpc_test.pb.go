@@ -7,6 +7,8 @@ import (
 	context "context"
 	fmt "fmt"
 	proto "github.com/golang/protobuf/proto"
+	timestamp "github.com/golang/protobuf/ptypes/timestamp"
+	wrappers "github.com/golang/protobuf/ptypes/wrappers"
 	_ "google.golang.org/genproto/googleapis/api/annotations"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
@@ -25,6 +27,92 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
+type RandomRequest_Distribution int32
+
+const (
+	RandomRequest_UNIFORM RandomRequest_Distribution = 0
+	// Around mean, with standard deviation stddev.
+	RandomRequest_NORMAL RandomRequest_Distribution = 1
+	// min plus an exponentially distributed offset averaging mean - min.
+	RandomRequest_EXPONENTIAL RandomRequest_Distribution = 2
+)
+
+var RandomRequest_Distribution_name = map[int32]string{
+	0: "UNIFORM",
+	1: "NORMAL",
+	2: "EXPONENTIAL",
+}
+
+var RandomRequest_Distribution_value = map[string]int32{
+	"UNIFORM":     0,
+	"NORMAL":      1,
+	"EXPONENTIAL": 2,
+}
+
+func (x RandomRequest_Distribution) String() string {
+	return proto.EnumName(RandomRequest_Distribution_name, int32(x))
+}
+
+func (RandomRequest_Distribution) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_d6989e57c97e783e, []int{6, 0}
+}
+
+type RandomRequest_Source int32
+
+const (
+	// math/rand, seeded with seed, or else a fresh seed that is returned.
+	RandomRequest_MATH RandomRequest_Source = 0
+	// crypto/rand, which can't be seeded.
+	RandomRequest_CRYPTO RandomRequest_Source = 1
+)
+
+var RandomRequest_Source_name = map[int32]string{
+	0: "MATH",
+	1: "CRYPTO",
+}
+
+var RandomRequest_Source_value = map[string]int32{
+	"MATH":   0,
+	"CRYPTO": 1,
+}
+
+func (x RandomRequest_Source) String() string {
+	return proto.EnumName(RandomRequest_Source_name, int32(x))
+}
+
+func (RandomRequest_Source) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_d6989e57c97e783e, []int{6, 1}
+}
+
+type RandomRequest_Parity int32
+
+const (
+	// Whatever the server's random.parity setting says.
+	RandomRequest_ANY  RandomRequest_Parity = 0
+	RandomRequest_EVEN RandomRequest_Parity = 1
+	RandomRequest_ODD  RandomRequest_Parity = 2
+)
+
+var RandomRequest_Parity_name = map[int32]string{
+	0: "ANY",
+	1: "EVEN",
+	2: "ODD",
+}
+
+var RandomRequest_Parity_value = map[string]int32{
+	"ANY":  0,
+	"EVEN": 1,
+	"ODD":  2,
+}
+
+func (x RandomRequest_Parity) String() string {
+	return proto.EnumName(RandomRequest_Parity_name, int32(x))
+}
+
+func (RandomRequest_Parity) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_d6989e57c97e783e, []int{6, 2}
+}
+
 type Request struct {
 	Value                int32    `protobuf:"varint,1,opt,name=value,proto3" json:"value,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -65,7 +153,10 @@ func (m *Request) GetValue() int32 {
 }
 
 type Result struct {
-	Value                int32    `protobuf:"varint,1,opt,name=value,proto3" json:"value,omitempty"`
+	Value int32 `protobuf:"varint,1,opt,name=value,proto3" json:"value,omitempty"`
+	// The value that was squared. Only servers running the different-schema-field
+	// profile set it, standing in for a field a new version adds.
+	Input                int32    `protobuf:"varint,2,opt,name=input,proto3" json:"input,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -103,6 +194,13 @@ func (m *Result) GetValue() int32 {
 	return 0
 }
 
+func (m *Result) GetInput() int32 {
+	if m != nil {
+		return m.Input
+	}
+	return 0
+}
+
 type Empty struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -134,141 +232,1553 @@ func (m *Empty) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_Empty proto.InternalMessageInfo
 
-func init() {
-	proto.RegisterType((*Request)(nil), "Request")
-	proto.RegisterType((*Result)(nil), "Result")
-	proto.RegisterType((*Empty)(nil), "Empty")
+type Int64Request struct {
+	Value                int64    `protobuf:"varint,1,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-func init() { proto.RegisterFile("pb/grpc_test.proto", fileDescriptor_d6989e57c97e783e) }
+func (m *Int64Request) Reset()         { *m = Int64Request{} }
+func (m *Int64Request) String() string { return proto.CompactTextString(m) }
+func (*Int64Request) ProtoMessage()    {}
+func (*Int64Request) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d6989e57c97e783e, []int{3}
+}
 
-var fileDescriptor_d6989e57c97e783e = []byte{
-	// 208 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x12, 0x2a, 0x48, 0xd2, 0x4f,
-	0x2f, 0x2a, 0x48, 0x8e, 0x2f, 0x49, 0x2d, 0x2e, 0xd1, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x97, 0x92,
-	0x49, 0xcf, 0xcf, 0x4f, 0xcf, 0x49, 0xd5, 0x4f, 0x2c, 0xc8, 0xd4, 0x4f, 0xcc, 0xcb, 0xcb, 0x2f,
-	0x49, 0x2c, 0xc9, 0xcc, 0xcf, 0x2b, 0x86, 0xc8, 0x2a, 0xc9, 0x73, 0xb1, 0x07, 0xa5, 0x16, 0x96,
-	0xa6, 0x16, 0x97, 0x08, 0x89, 0x70, 0xb1, 0x96, 0x25, 0xe6, 0x94, 0xa6, 0x4a, 0x30, 0x2a, 0x30,
-	0x6a, 0xb0, 0x06, 0x41, 0x38, 0x4a, 0x72, 0x5c, 0x6c, 0x41, 0xa9, 0xc5, 0xa5, 0x39, 0xb8, 0xe4,
-	0xd9, 0xb9, 0x58, 0x5d, 0x73, 0x0b, 0x4a, 0x2a, 0x8d, 0x2a, 0xb8, 0xb8, 0x7d, 0x13, 0x4b, 0x32,
-	0x82, 0x53, 0x8b, 0xca, 0x32, 0x93, 0x53, 0x85, 0x2c, 0xb8, 0xd8, 0x82, 0x0b, 0x4b, 0x13, 0x8b,
-	0x52, 0x85, 0x38, 0xf4, 0xa0, 0x36, 0x48, 0xb1, 0xeb, 0x41, 0x8c, 0x52, 0x92, 0x6e, 0xba, 0xfc,
-	0x64, 0x32, 0x93, 0xa8, 0x12, 0x8f, 0x7e, 0x6e, 0x62, 0x49, 0x86, 0x7e, 0x31, 0x58, 0xa1, 0x15,
-	0xc4, 0x44, 0x21, 0x7d, 0x2e, 0xb6, 0xa0, 0xc4, 0xbc, 0x94, 0xfc, 0x5c, 0x21, 0x36, 0x3d, 0xb0,
-	0xd1, 0x08, 0x7d, 0x22, 0x60, 0x7d, 0x7c, 0x42, 0x50, 0x7d, 0x45, 0x60, 0x65, 0x49, 0x6c, 0x60,
-	0xaf, 0x18, 0x03, 0x02, 0x00, 0x00, 0xff, 0xff, 0xb8, 0x4d, 0x82, 0x08, 0xfe, 0x00, 0x00, 0x00,
+func (m *Int64Request) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Int64Request.Unmarshal(m, b)
+}
+func (m *Int64Request) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Int64Request.Marshal(b, m, deterministic)
+}
+func (m *Int64Request) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Int64Request.Merge(m, src)
+}
+func (m *Int64Request) XXX_Size() int {
+	return xxx_messageInfo_Int64Request.Size(m)
+}
+func (m *Int64Request) XXX_DiscardUnknown() {
+	xxx_messageInfo_Int64Request.DiscardUnknown(m)
 }
 
-// Reference imports to suppress errors if they are not otherwise used.
-var _ context.Context
-var _ grpc.ClientConn
+var xxx_messageInfo_Int64Request proto.InternalMessageInfo
 
-// This is a compile-time assertion to ensure that this generated file
-// is compatible with the grpc package it is being compiled against.
-const _ = grpc.SupportPackageIsVersion4
+func (m *Int64Request) GetValue() int64 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
 
-// MathServiceClient is the client API for MathService service.
-//
-// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
-type MathServiceClient interface {
-	Square(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Result, error)
-	Random(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Result, error)
+type Int64Result struct {
+	Value                int64    `protobuf:"varint,1,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
-type mathServiceClient struct {
-	cc *grpc.ClientConn
+func (m *Int64Result) Reset()         { *m = Int64Result{} }
+func (m *Int64Result) String() string { return proto.CompactTextString(m) }
+func (*Int64Result) ProtoMessage()    {}
+func (*Int64Result) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d6989e57c97e783e, []int{4}
 }
 
-func NewMathServiceClient(cc *grpc.ClientConn) MathServiceClient {
-	return &mathServiceClient{cc}
+func (m *Int64Result) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Int64Result.Unmarshal(m, b)
+}
+func (m *Int64Result) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Int64Result.Marshal(b, m, deterministic)
+}
+func (m *Int64Result) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Int64Result.Merge(m, src)
+}
+func (m *Int64Result) XXX_Size() int {
+	return xxx_messageInfo_Int64Result.Size(m)
+}
+func (m *Int64Result) XXX_DiscardUnknown() {
+	xxx_messageInfo_Int64Result.DiscardUnknown(m)
 }
 
-func (c *mathServiceClient) Square(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Result, error) {
-	out := new(Result)
-	err := c.cc.Invoke(ctx, "/MathService/Square", in, out, opts...)
-	if err != nil {
-		return nil, err
+var xxx_messageInfo_Int64Result proto.InternalMessageInfo
+
+func (m *Int64Result) GetValue() int64 {
+	if m != nil {
+		return m.Value
 	}
-	return out, nil
+	return 0
 }
 
-func (c *mathServiceClient) Random(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Result, error) {
-	out := new(Result)
-	err := c.cc.Invoke(ctx, "/MathService/Random", in, out, opts...)
-	if err != nil {
-		return nil, err
+// An integer of any size, as a decimal string.
+type BigInteger struct {
+	Value                string   `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BigInteger) Reset()         { *m = BigInteger{} }
+func (m *BigInteger) String() string { return proto.CompactTextString(m) }
+func (*BigInteger) ProtoMessage()    {}
+func (*BigInteger) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d6989e57c97e783e, []int{5}
+}
+
+func (m *BigInteger) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BigInteger.Unmarshal(m, b)
+}
+func (m *BigInteger) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BigInteger.Marshal(b, m, deterministic)
+}
+func (m *BigInteger) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BigInteger.Merge(m, src)
+}
+func (m *BigInteger) XXX_Size() int {
+	return xxx_messageInfo_BigInteger.Size(m)
+}
+func (m *BigInteger) XXX_DiscardUnknown() {
+	xxx_messageInfo_BigInteger.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BigInteger proto.InternalMessageInfo
+
+func (m *BigInteger) GetValue() string {
+	if m != nil {
+		return m.Value
 	}
-	return out, nil
+	return ""
 }
 
-// MathServiceServer is the server API for MathService service.
-type MathServiceServer interface {
-	Square(context.Context, *Request) (*Result, error)
-	Random(context.Context, *Empty) (*Result, error)
+type RandomRequest struct {
+	// Inclusive bounds, 0 and 2147483647 when unset.
+	Min          *wrappers.Int32Value       `protobuf:"bytes,1,opt,name=min,proto3" json:"min,omitempty"`
+	Max          *wrappers.Int32Value       `protobuf:"bytes,2,opt,name=max,proto3" json:"max,omitempty"`
+	Distribution RandomRequest_Distribution `protobuf:"varint,3,opt,name=distribution,proto3,enum=RandomRequest_Distribution" json:"distribution,omitempty"`
+	// NORMAL and EXPONENTIAL default to a mean in the middle of the range, and
+	// NORMAL to a standard deviation of a sixth of it. Values drawn outside
+	// the bounds are drawn again.
+	Mean   *wrappers.DoubleValue `protobuf:"bytes,4,opt,name=mean,proto3" json:"mean,omitempty"`
+	Stddev *wrappers.DoubleValue `protobuf:"bytes,5,opt,name=stddev,proto3" json:"stddev,omitempty"`
+	// Number of values, 1 when unset; at most 10000.
+	Count                int32                `protobuf:"varint,6,opt,name=count,proto3" json:"count,omitempty"`
+	Seed                 *wrappers.Int64Value `protobuf:"bytes,7,opt,name=seed,proto3" json:"seed,omitempty"`
+	Source               RandomRequest_Source `protobuf:"varint,8,opt,name=source,proto3,enum=RandomRequest_Source" json:"source,omitempty"`
+	Parity               RandomRequest_Parity `protobuf:"varint,9,opt,name=parity,proto3,enum=RandomRequest_Parity" json:"parity,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
 }
 
-// UnimplementedMathServiceServer can be embedded to have forward compatible implementations.
-type UnimplementedMathServiceServer struct {
+func (m *RandomRequest) Reset()         { *m = RandomRequest{} }
+func (m *RandomRequest) String() string { return proto.CompactTextString(m) }
+func (*RandomRequest) ProtoMessage()    {}
+func (*RandomRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d6989e57c97e783e, []int{6}
 }
 
-func (*UnimplementedMathServiceServer) Square(ctx context.Context, req *Request) (*Result, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Square not implemented")
+func (m *RandomRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RandomRequest.Unmarshal(m, b)
 }
-func (*UnimplementedMathServiceServer) Random(ctx context.Context, req *Empty) (*Result, error) {
-	return nil, status.Errorf(codes.Unimplemented, "method Random not implemented")
+func (m *RandomRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RandomRequest.Marshal(b, m, deterministic)
+}
+func (m *RandomRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RandomRequest.Merge(m, src)
+}
+func (m *RandomRequest) XXX_Size() int {
+	return xxx_messageInfo_RandomRequest.Size(m)
+}
+func (m *RandomRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RandomRequest.DiscardUnknown(m)
 }
 
-func RegisterMathServiceServer(s *grpc.Server, srv MathServiceServer) {
-	s.RegisterService(&_MathService_serviceDesc, srv)
+var xxx_messageInfo_RandomRequest proto.InternalMessageInfo
+
+func (m *RandomRequest) GetMin() *wrappers.Int32Value {
+	if m != nil {
+		return m.Min
+	}
+	return nil
 }
 
-func _MathService_Square_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Request)
-	if err := dec(in); err != nil {
-		return nil, err
+func (m *RandomRequest) GetMax() *wrappers.Int32Value {
+	if m != nil {
+		return m.Max
 	}
-	if interceptor == nil {
-		return srv.(MathServiceServer).Square(ctx, in)
+	return nil
+}
+
+func (m *RandomRequest) GetDistribution() RandomRequest_Distribution {
+	if m != nil {
+		return m.Distribution
 	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/MathService/Square",
+	return RandomRequest_UNIFORM
+}
+
+func (m *RandomRequest) GetMean() *wrappers.DoubleValue {
+	if m != nil {
+		return m.Mean
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(MathServiceServer).Square(ctx, req.(*Request))
+	return nil
+}
+
+func (m *RandomRequest) GetStddev() *wrappers.DoubleValue {
+	if m != nil {
+		return m.Stddev
 	}
-	return interceptor(ctx, in, info, handler)
+	return nil
 }
 
-func _MathService_Random_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(Empty)
-	if err := dec(in); err != nil {
-		return nil, err
+func (m *RandomRequest) GetCount() int32 {
+	if m != nil {
+		return m.Count
 	}
-	if interceptor == nil {
-		return srv.(MathServiceServer).Random(ctx, in)
+	return 0
+}
+
+func (m *RandomRequest) GetSeed() *wrappers.Int64Value {
+	if m != nil {
+		return m.Seed
 	}
-	info := &grpc.UnaryServerInfo{
-		Server:     srv,
-		FullMethod: "/MathService/Random",
+	return nil
+}
+
+func (m *RandomRequest) GetSource() RandomRequest_Source {
+	if m != nil {
+		return m.Source
 	}
-	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(MathServiceServer).Random(ctx, req.(*Empty))
+	return RandomRequest_MATH
+}
+
+func (m *RandomRequest) GetParity() RandomRequest_Parity {
+	if m != nil {
+		return m.Parity
 	}
-	return interceptor(ctx, in, info, handler)
+	return RandomRequest_ANY
 }
 
-var _MathService_serviceDesc = grpc.ServiceDesc{
-	ServiceName: "MathService",
-	HandlerType: (*MathServiceServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "Square",
-			Handler:    _MathService_Square_Handler,
-		},
-		{
-			MethodName: "Random",
-			Handler:    _MathService_Random_Handler,
+type RandomResult struct {
+	// The first of values.
+	Value  int32   `protobuf:"varint,1,opt,name=value,proto3" json:"value,omitempty"`
+	Values []int32 `protobuf:"varint,2,rep,packed,name=values,proto3" json:"values,omitempty"`
+	// Passing this back as the seed draws the same values again. Unset for
+	// the CRYPTO source.
+	Seed                 *wrappers.Int64Value `protobuf:"bytes,3,opt,name=seed,proto3" json:"seed,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *RandomResult) Reset()         { *m = RandomResult{} }
+func (m *RandomResult) String() string { return proto.CompactTextString(m) }
+func (*RandomResult) ProtoMessage()    {}
+func (*RandomResult) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d6989e57c97e783e, []int{7}
+}
+
+func (m *RandomResult) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RandomResult.Unmarshal(m, b)
+}
+func (m *RandomResult) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RandomResult.Marshal(b, m, deterministic)
+}
+func (m *RandomResult) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RandomResult.Merge(m, src)
+}
+func (m *RandomResult) XXX_Size() int {
+	return xxx_messageInfo_RandomResult.Size(m)
+}
+func (m *RandomResult) XXX_DiscardUnknown() {
+	xxx_messageInfo_RandomResult.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RandomResult proto.InternalMessageInfo
+
+func (m *RandomResult) GetValue() int32 {
+	if m != nil {
+		return m.Value
+	}
+	return 0
+}
+
+func (m *RandomResult) GetValues() []int32 {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+func (m *RandomResult) GetSeed() *wrappers.Int64Value {
+	if m != nil {
+		return m.Seed
+	}
+	return nil
+}
+
+type RandomStreamRequest struct {
+	// Number of values to send; 0 streams until the call is cancelled.
+	Count int32 `protobuf:"varint,1,opt,name=count,proto3" json:"count,omitempty"`
+	// Values per second; 0 sends as fast as the client reads them.
+	Rate float64 `protobuf:"fixed64,2,opt,name=rate,proto3" json:"rate,omitempty"`
+	// How to draw each value; its count is ignored.
+	Random               *RandomRequest `protobuf:"bytes,3,opt,name=random,proto3" json:"random,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *RandomStreamRequest) Reset()         { *m = RandomStreamRequest{} }
+func (m *RandomStreamRequest) String() string { return proto.CompactTextString(m) }
+func (*RandomStreamRequest) ProtoMessage()    {}
+func (*RandomStreamRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d6989e57c97e783e, []int{8}
+}
+
+func (m *RandomStreamRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RandomStreamRequest.Unmarshal(m, b)
+}
+func (m *RandomStreamRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RandomStreamRequest.Marshal(b, m, deterministic)
+}
+func (m *RandomStreamRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RandomStreamRequest.Merge(m, src)
+}
+func (m *RandomStreamRequest) XXX_Size() int {
+	return xxx_messageInfo_RandomStreamRequest.Size(m)
+}
+func (m *RandomStreamRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RandomStreamRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RandomStreamRequest proto.InternalMessageInfo
+
+func (m *RandomStreamRequest) GetCount() int32 {
+	if m != nil {
+		return m.Count
+	}
+	return 0
+}
+
+func (m *RandomStreamRequest) GetRate() float64 {
+	if m != nil {
+		return m.Rate
+	}
+	return 0
+}
+
+func (m *RandomStreamRequest) GetRandom() *RandomRequest {
+	if m != nil {
+		return m.Random
+	}
+	return nil
+}
+
+type WhoAmIRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *WhoAmIRequest) Reset()         { *m = WhoAmIRequest{} }
+func (m *WhoAmIRequest) String() string { return proto.CompactTextString(m) }
+func (*WhoAmIRequest) ProtoMessage()    {}
+func (*WhoAmIRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d6989e57c97e783e, []int{9}
+}
+
+func (m *WhoAmIRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_WhoAmIRequest.Unmarshal(m, b)
+}
+func (m *WhoAmIRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_WhoAmIRequest.Marshal(b, m, deterministic)
+}
+func (m *WhoAmIRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_WhoAmIRequest.Merge(m, src)
+}
+func (m *WhoAmIRequest) XXX_Size() int {
+	return xxx_messageInfo_WhoAmIRequest.Size(m)
+}
+func (m *WhoAmIRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_WhoAmIRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_WhoAmIRequest proto.InternalMessageInfo
+
+type EchoRequest struct {
+	Message              string   `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *EchoRequest) Reset()         { *m = EchoRequest{} }
+func (m *EchoRequest) String() string { return proto.CompactTextString(m) }
+func (*EchoRequest) ProtoMessage()    {}
+func (*EchoRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d6989e57c97e783e, []int{10}
+}
+
+func (m *EchoRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EchoRequest.Unmarshal(m, b)
+}
+func (m *EchoRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EchoRequest.Marshal(b, m, deterministic)
+}
+func (m *EchoRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EchoRequest.Merge(m, src)
+}
+func (m *EchoRequest) XXX_Size() int {
+	return xxx_messageInfo_EchoRequest.Size(m)
+}
+func (m *EchoRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_EchoRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EchoRequest proto.InternalMessageInfo
+
+func (m *EchoRequest) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+// What the server made of a call.
+type CallInfo struct {
+	Method string `protobuf:"bytes,1,opt,name=method,proto3" json:"method,omitempty"`
+	// The incoming metadata as the interceptors saw it, keys lower case.
+	Metadata map[string]*CallInfo_Values `protobuf:"bytes,2,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// host:port of the connection's other end, an Envoy sidecar in Istio.
+	PeerAddress string `protobuf:"bytes,3,opt,name=peer_address,json=peerAddress,proto3" json:"peer_address,omitempty"`
+	// Unset for plaintext connections.
+	Tls *CallInfo_TLS `protobuf:"bytes,4,opt,name=tls,proto3" json:"tls,omitempty"`
+	// Unset when the caller set none.
+	Deadline             *timestamp.Timestamp `protobuf:"bytes,5,opt,name=deadline,proto3" json:"deadline,omitempty"`
+	RequestId            string               `protobuf:"bytes,6,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Identity             *CallInfo_Identity   `protobuf:"bytes,7,opt,name=identity,proto3" json:"identity,omitempty"`
+	Server               *CallInfo_Server     `protobuf:"bytes,8,opt,name=server,proto3" json:"server,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *CallInfo) Reset()         { *m = CallInfo{} }
+func (m *CallInfo) String() string { return proto.CompactTextString(m) }
+func (*CallInfo) ProtoMessage()    {}
+func (*CallInfo) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d6989e57c97e783e, []int{11}
+}
+
+func (m *CallInfo) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CallInfo.Unmarshal(m, b)
+}
+func (m *CallInfo) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CallInfo.Marshal(b, m, deterministic)
+}
+func (m *CallInfo) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CallInfo.Merge(m, src)
+}
+func (m *CallInfo) XXX_Size() int {
+	return xxx_messageInfo_CallInfo.Size(m)
+}
+func (m *CallInfo) XXX_DiscardUnknown() {
+	xxx_messageInfo_CallInfo.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CallInfo proto.InternalMessageInfo
+
+func (m *CallInfo) GetMethod() string {
+	if m != nil {
+		return m.Method
+	}
+	return ""
+}
+
+func (m *CallInfo) GetMetadata() map[string]*CallInfo_Values {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
+func (m *CallInfo) GetPeerAddress() string {
+	if m != nil {
+		return m.PeerAddress
+	}
+	return ""
+}
+
+func (m *CallInfo) GetTls() *CallInfo_TLS {
+	if m != nil {
+		return m.Tls
+	}
+	return nil
+}
+
+func (m *CallInfo) GetDeadline() *timestamp.Timestamp {
+	if m != nil {
+		return m.Deadline
+	}
+	return nil
+}
+
+func (m *CallInfo) GetRequestId() string {
+	if m != nil {
+		return m.RequestId
+	}
+	return ""
+}
+
+func (m *CallInfo) GetIdentity() *CallInfo_Identity {
+	if m != nil {
+		return m.Identity
+	}
+	return nil
+}
+
+func (m *CallInfo) GetServer() *CallInfo_Server {
+	if m != nil {
+		return m.Server
+	}
+	return nil
+}
+
+type CallInfo_Values struct {
+	Values               []string `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CallInfo_Values) Reset()         { *m = CallInfo_Values{} }
+func (m *CallInfo_Values) String() string { return proto.CompactTextString(m) }
+func (*CallInfo_Values) ProtoMessage()    {}
+func (*CallInfo_Values) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d6989e57c97e783e, []int{11, 0}
+}
+
+func (m *CallInfo_Values) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CallInfo_Values.Unmarshal(m, b)
+}
+func (m *CallInfo_Values) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CallInfo_Values.Marshal(b, m, deterministic)
+}
+func (m *CallInfo_Values) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CallInfo_Values.Merge(m, src)
+}
+func (m *CallInfo_Values) XXX_Size() int {
+	return xxx_messageInfo_CallInfo_Values.Size(m)
+}
+func (m *CallInfo_Values) XXX_DiscardUnknown() {
+	xxx_messageInfo_CallInfo_Values.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CallInfo_Values proto.InternalMessageInfo
+
+func (m *CallInfo_Values) GetValues() []string {
+	if m != nil {
+		return m.Values
+	}
+	return nil
+}
+
+type CallInfo_TLS struct {
+	// e.g. TLS 1.3
+	Version     string `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	CipherSuite string `protobuf:"bytes,2,opt,name=cipher_suite,json=cipherSuite,proto3" json:"cipher_suite,omitempty"`
+	// The SNI the client asked for.
+	ServerName string `protobuf:"bytes,3,opt,name=server_name,json=serverName,proto3" json:"server_name,omitempty"`
+	// The verified client certificate; unset without mTLS.
+	PeerCertificate      *CallInfo_Certificate `protobuf:"bytes,4,opt,name=peer_certificate,json=peerCertificate,proto3" json:"peer_certificate,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *CallInfo_TLS) Reset()         { *m = CallInfo_TLS{} }
+func (m *CallInfo_TLS) String() string { return proto.CompactTextString(m) }
+func (*CallInfo_TLS) ProtoMessage()    {}
+func (*CallInfo_TLS) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d6989e57c97e783e, []int{11, 1}
+}
+
+func (m *CallInfo_TLS) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CallInfo_TLS.Unmarshal(m, b)
+}
+func (m *CallInfo_TLS) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CallInfo_TLS.Marshal(b, m, deterministic)
+}
+func (m *CallInfo_TLS) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CallInfo_TLS.Merge(m, src)
+}
+func (m *CallInfo_TLS) XXX_Size() int {
+	return xxx_messageInfo_CallInfo_TLS.Size(m)
+}
+func (m *CallInfo_TLS) XXX_DiscardUnknown() {
+	xxx_messageInfo_CallInfo_TLS.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CallInfo_TLS proto.InternalMessageInfo
+
+func (m *CallInfo_TLS) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *CallInfo_TLS) GetCipherSuite() string {
+	if m != nil {
+		return m.CipherSuite
+	}
+	return ""
+}
+
+func (m *CallInfo_TLS) GetServerName() string {
+	if m != nil {
+		return m.ServerName
+	}
+	return ""
+}
+
+func (m *CallInfo_TLS) GetPeerCertificate() *CallInfo_Certificate {
+	if m != nil {
+		return m.PeerCertificate
+	}
+	return nil
+}
+
+type CallInfo_Certificate struct {
+	Subject      string               `protobuf:"bytes,1,opt,name=subject,proto3" json:"subject,omitempty"`
+	Issuer       string               `protobuf:"bytes,2,opt,name=issuer,proto3" json:"issuer,omitempty"`
+	SerialNumber string               `protobuf:"bytes,3,opt,name=serial_number,json=serialNumber,proto3" json:"serial_number,omitempty"`
+	NotBefore    *timestamp.Timestamp `protobuf:"bytes,4,opt,name=not_before,json=notBefore,proto3" json:"not_before,omitempty"`
+	NotAfter     *timestamp.Timestamp `protobuf:"bytes,5,opt,name=not_after,json=notAfter,proto3" json:"not_after,omitempty"`
+	DnsNames     []string             `protobuf:"bytes,6,rep,name=dns_names,json=dnsNames,proto3" json:"dns_names,omitempty"`
+	Uris         []string             `protobuf:"bytes,7,rep,name=uris,proto3" json:"uris,omitempty"`
+	// The spiffe:// URI SAN of Istio's workload certificates.
+	SpiffeId             string   `protobuf:"bytes,8,opt,name=spiffe_id,json=spiffeId,proto3" json:"spiffe_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CallInfo_Certificate) Reset()         { *m = CallInfo_Certificate{} }
+func (m *CallInfo_Certificate) String() string { return proto.CompactTextString(m) }
+func (*CallInfo_Certificate) ProtoMessage()    {}
+func (*CallInfo_Certificate) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d6989e57c97e783e, []int{11, 2}
+}
+
+func (m *CallInfo_Certificate) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CallInfo_Certificate.Unmarshal(m, b)
+}
+func (m *CallInfo_Certificate) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CallInfo_Certificate.Marshal(b, m, deterministic)
+}
+func (m *CallInfo_Certificate) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CallInfo_Certificate.Merge(m, src)
+}
+func (m *CallInfo_Certificate) XXX_Size() int {
+	return xxx_messageInfo_CallInfo_Certificate.Size(m)
+}
+func (m *CallInfo_Certificate) XXX_DiscardUnknown() {
+	xxx_messageInfo_CallInfo_Certificate.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CallInfo_Certificate proto.InternalMessageInfo
+
+func (m *CallInfo_Certificate) GetSubject() string {
+	if m != nil {
+		return m.Subject
+	}
+	return ""
+}
+
+func (m *CallInfo_Certificate) GetIssuer() string {
+	if m != nil {
+		return m.Issuer
+	}
+	return ""
+}
+
+func (m *CallInfo_Certificate) GetSerialNumber() string {
+	if m != nil {
+		return m.SerialNumber
+	}
+	return ""
+}
+
+func (m *CallInfo_Certificate) GetNotBefore() *timestamp.Timestamp {
+	if m != nil {
+		return m.NotBefore
+	}
+	return nil
+}
+
+func (m *CallInfo_Certificate) GetNotAfter() *timestamp.Timestamp {
+	if m != nil {
+		return m.NotAfter
+	}
+	return nil
+}
+
+func (m *CallInfo_Certificate) GetDnsNames() []string {
+	if m != nil {
+		return m.DnsNames
+	}
+	return nil
+}
+
+func (m *CallInfo_Certificate) GetUris() []string {
+	if m != nil {
+		return m.Uris
+	}
+	return nil
+}
+
+func (m *CallInfo_Certificate) GetSpiffeId() string {
+	if m != nil {
+		return m.SpiffeId
+	}
+	return ""
+}
+
+type CallInfo_Identity struct {
+	Id         string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Email      string   `protobuf:"bytes,2,opt,name=email,proto3" json:"email,omitempty"`
+	Tenant     string   `protobuf:"bytes,3,opt,name=tenant,proto3" json:"tenant,omitempty"`
+	Connection string   `protobuf:"bytes,4,opt,name=connection,proto3" json:"connection,omitempty"`
+	ClientId   string   `protobuf:"bytes,5,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	Scopes     []string `protobuf:"bytes,6,rep,name=scopes,proto3" json:"scopes,omitempty"`
+	Groups     []string `protobuf:"bytes,7,rep,name=groups,proto3" json:"groups,omitempty"`
+	Roles      []string `protobuf:"bytes,8,rep,name=roles,proto3" json:"roles,omitempty"`
+	// Why there is no identity, e.g. a missing or expired token; the
+	// other fields are empty then.
+	Error                string   `protobuf:"bytes,9,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CallInfo_Identity) Reset()         { *m = CallInfo_Identity{} }
+func (m *CallInfo_Identity) String() string { return proto.CompactTextString(m) }
+func (*CallInfo_Identity) ProtoMessage()    {}
+func (*CallInfo_Identity) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d6989e57c97e783e, []int{11, 3}
+}
+
+func (m *CallInfo_Identity) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CallInfo_Identity.Unmarshal(m, b)
+}
+func (m *CallInfo_Identity) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CallInfo_Identity.Marshal(b, m, deterministic)
+}
+func (m *CallInfo_Identity) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CallInfo_Identity.Merge(m, src)
+}
+func (m *CallInfo_Identity) XXX_Size() int {
+	return xxx_messageInfo_CallInfo_Identity.Size(m)
+}
+func (m *CallInfo_Identity) XXX_DiscardUnknown() {
+	xxx_messageInfo_CallInfo_Identity.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CallInfo_Identity proto.InternalMessageInfo
+
+func (m *CallInfo_Identity) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *CallInfo_Identity) GetEmail() string {
+	if m != nil {
+		return m.Email
+	}
+	return ""
+}
+
+func (m *CallInfo_Identity) GetTenant() string {
+	if m != nil {
+		return m.Tenant
+	}
+	return ""
+}
+
+func (m *CallInfo_Identity) GetConnection() string {
+	if m != nil {
+		return m.Connection
+	}
+	return ""
+}
+
+func (m *CallInfo_Identity) GetClientId() string {
+	if m != nil {
+		return m.ClientId
+	}
+	return ""
+}
+
+func (m *CallInfo_Identity) GetScopes() []string {
+	if m != nil {
+		return m.Scopes
+	}
+	return nil
+}
+
+func (m *CallInfo_Identity) GetGroups() []string {
+	if m != nil {
+		return m.Groups
+	}
+	return nil
+}
+
+func (m *CallInfo_Identity) GetRoles() []string {
+	if m != nil {
+		return m.Roles
+	}
+	return nil
+}
+
+func (m *CallInfo_Identity) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type CallInfo_Server struct {
+	Service string `protobuf:"bytes,1,opt,name=service,proto3" json:"service,omitempty"`
+	// The pod's name, or the host's outside Kubernetes.
+	Pod                  string   `protobuf:"bytes,2,opt,name=pod,proto3" json:"pod,omitempty"`
+	Namespace            string   `protobuf:"bytes,3,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Version              string   `protobuf:"bytes,4,opt,name=version,proto3" json:"version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CallInfo_Server) Reset()         { *m = CallInfo_Server{} }
+func (m *CallInfo_Server) String() string { return proto.CompactTextString(m) }
+func (*CallInfo_Server) ProtoMessage()    {}
+func (*CallInfo_Server) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d6989e57c97e783e, []int{11, 4}
+}
+
+func (m *CallInfo_Server) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CallInfo_Server.Unmarshal(m, b)
+}
+func (m *CallInfo_Server) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CallInfo_Server.Marshal(b, m, deterministic)
+}
+func (m *CallInfo_Server) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CallInfo_Server.Merge(m, src)
+}
+func (m *CallInfo_Server) XXX_Size() int {
+	return xxx_messageInfo_CallInfo_Server.Size(m)
+}
+func (m *CallInfo_Server) XXX_DiscardUnknown() {
+	xxx_messageInfo_CallInfo_Server.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CallInfo_Server proto.InternalMessageInfo
+
+func (m *CallInfo_Server) GetService() string {
+	if m != nil {
+		return m.Service
+	}
+	return ""
+}
+
+func (m *CallInfo_Server) GetPod() string {
+	if m != nil {
+		return m.Pod
+	}
+	return ""
+}
+
+func (m *CallInfo_Server) GetNamespace() string {
+	if m != nil {
+		return m.Namespace
+	}
+	return ""
+}
+
+func (m *CallInfo_Server) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+type EchoResponse struct {
+	Message              string    `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	Call                 *CallInfo `protobuf:"bytes,2,opt,name=call,proto3" json:"call,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
+	XXX_unrecognized     []byte    `json:"-"`
+	XXX_sizecache        int32     `json:"-"`
+}
+
+func (m *EchoResponse) Reset()         { *m = EchoResponse{} }
+func (m *EchoResponse) String() string { return proto.CompactTextString(m) }
+func (*EchoResponse) ProtoMessage()    {}
+func (*EchoResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d6989e57c97e783e, []int{12}
+}
+
+func (m *EchoResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_EchoResponse.Unmarshal(m, b)
+}
+func (m *EchoResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_EchoResponse.Marshal(b, m, deterministic)
+}
+func (m *EchoResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_EchoResponse.Merge(m, src)
+}
+func (m *EchoResponse) XXX_Size() int {
+	return xxx_messageInfo_EchoResponse.Size(m)
+}
+func (m *EchoResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_EchoResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_EchoResponse proto.InternalMessageInfo
+
+func (m *EchoResponse) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *EchoResponse) GetCall() *CallInfo {
+	if m != nil {
+		return m.Call
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterEnum("RandomRequest_Distribution", RandomRequest_Distribution_name, RandomRequest_Distribution_value)
+	proto.RegisterEnum("RandomRequest_Source", RandomRequest_Source_name, RandomRequest_Source_value)
+	proto.RegisterEnum("RandomRequest_Parity", RandomRequest_Parity_name, RandomRequest_Parity_value)
+	proto.RegisterType((*Request)(nil), "Request")
+	proto.RegisterType((*Result)(nil), "Result")
+	proto.RegisterType((*Empty)(nil), "Empty")
+	proto.RegisterType((*Int64Request)(nil), "Int64Request")
+	proto.RegisterType((*Int64Result)(nil), "Int64Result")
+	proto.RegisterType((*BigInteger)(nil), "BigInteger")
+	proto.RegisterType((*RandomRequest)(nil), "RandomRequest")
+	proto.RegisterType((*RandomResult)(nil), "RandomResult")
+	proto.RegisterType((*RandomStreamRequest)(nil), "RandomStreamRequest")
+	proto.RegisterType((*WhoAmIRequest)(nil), "WhoAmIRequest")
+	proto.RegisterType((*EchoRequest)(nil), "EchoRequest")
+	proto.RegisterType((*CallInfo)(nil), "CallInfo")
+	proto.RegisterMapType((map[string]*CallInfo_Values)(nil), "CallInfo.MetadataEntry")
+	proto.RegisterType((*CallInfo_Values)(nil), "CallInfo.Values")
+	proto.RegisterType((*CallInfo_TLS)(nil), "CallInfo.TLS")
+	proto.RegisterType((*CallInfo_Certificate)(nil), "CallInfo.Certificate")
+	proto.RegisterType((*CallInfo_Identity)(nil), "CallInfo.Identity")
+	proto.RegisterType((*CallInfo_Server)(nil), "CallInfo.Server")
+	proto.RegisterType((*EchoResponse)(nil), "EchoResponse")
+}
+
+func init() { proto.RegisterFile("pb/grpc_test.proto", fileDescriptor_d6989e57c97e783e) }
+
+var fileDescriptor_d6989e57c97e783e = []byte{
+	// 1419 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x56, 0xdd, 0x6e, 0xdb, 0x46,
+	0x16, 0x0e, 0x25, 0x99, 0x92, 0x8e, 0x24, 0x47, 0x18, 0xc7, 0x1b, 0xae, 0xec, 0xd8, 0x0e, 0x13,
+	0x64, 0x8d, 0x05, 0x42, 0x05, 0x8e, 0x91, 0xcd, 0x06, 0x58, 0x6c, 0xfd, 0x97, 0x56, 0x80, 0x2d,
+	0x07, 0x94, 0x9b, 0x36, 0x57, 0xc2, 0x88, 0x1c, 0x4b, 0xd3, 0x8a, 0x3f, 0x99, 0x19, 0x3a, 0xf1,
+	0x6d, 0x5f, 0xa1, 0x37, 0x7d, 0x81, 0xf6, 0x25, 0xfa, 0x18, 0x7d, 0x81, 0x16, 0xe8, 0x65, 0x1f,
+	0xa2, 0x98, 0x1f, 0x4a, 0x94, 0xed, 0x04, 0xbe, 0xe3, 0x39, 0xf3, 0x9d, 0xbf, 0x6f, 0xce, 0x99,
+	0x43, 0x40, 0xe9, 0xa8, 0x3b, 0x66, 0x69, 0x30, 0x14, 0x84, 0x0b, 0x2f, 0x65, 0x89, 0x48, 0x3a,
+	0xeb, 0xe3, 0x24, 0x19, 0x4f, 0x49, 0x17, 0xa7, 0xb4, 0x8b, 0xe3, 0x38, 0x11, 0x58, 0xd0, 0x24,
+	0xe6, 0xe6, 0x74, 0xd3, 0x9c, 0x2a, 0x69, 0x94, 0x9d, 0x77, 0x05, 0x8d, 0x08, 0x17, 0x38, 0x4a,
+	0x0d, 0x60, 0xe3, 0x2a, 0xe0, 0x03, 0xc3, 0x69, 0x4a, 0x98, 0x71, 0xe0, 0x6e, 0x42, 0xd5, 0x27,
+	0xef, 0x33, 0xc2, 0x05, 0xba, 0x07, 0x4b, 0x17, 0x78, 0x9a, 0x11, 0xc7, 0xda, 0xb2, 0xb6, 0x97,
+	0x7c, 0x2d, 0xb8, 0xbb, 0x60, 0xfb, 0x84, 0x67, 0xd3, 0x4f, 0x9c, 0x4b, 0x2d, 0x8d, 0xd3, 0x4c,
+	0x38, 0x25, 0xad, 0x55, 0x82, 0x5b, 0x85, 0xa5, 0xa3, 0x28, 0x15, 0x97, 0xee, 0x63, 0x68, 0xf6,
+	0x62, 0xf1, 0x62, 0xf7, 0xc6, 0x20, 0xe5, 0x3c, 0xc8, 0x23, 0x68, 0x18, 0xd4, 0xf5, 0x48, 0x33,
+	0x90, 0x0b, 0xb0, 0x4f, 0xc7, 0xbd, 0x58, 0x90, 0x31, 0x61, 0x8b, 0x98, 0x7a, 0x8e, 0xf9, 0xb5,
+	0x02, 0x2d, 0x1f, 0xc7, 0x61, 0x12, 0xe5, 0x01, 0x9f, 0x42, 0x39, 0xa2, 0xb1, 0x42, 0x35, 0x76,
+	0xd6, 0x3c, 0x4d, 0x87, 0x97, 0xd3, 0xe1, 0xf5, 0x62, 0xf1, 0x7c, 0xe7, 0xad, 0xb4, 0xf5, 0x25,
+	0x4e, 0xc1, 0xf1, 0x47, 0xa7, 0x74, 0x1b, 0x38, 0xfe, 0x88, 0xfe, 0x0f, 0xcd, 0x90, 0x72, 0xc1,
+	0xe8, 0x28, 0x93, 0xd7, 0xe2, 0x94, 0xb7, 0xac, 0xed, 0xe5, 0x9d, 0x35, 0x6f, 0x21, 0x07, 0xef,
+	0xb0, 0x00, 0xf1, 0x17, 0x0c, 0xd0, 0x33, 0xa8, 0x44, 0x04, 0xc7, 0x4e, 0x45, 0x05, 0x5c, 0xbf,
+	0x16, 0xf0, 0x30, 0xc9, 0x46, 0x53, 0xa2, 0x23, 0x2a, 0x24, 0xda, 0x05, 0x9b, 0x8b, 0x30, 0x24,
+	0x17, 0xce, 0xd2, 0x2d, 0x6c, 0x0c, 0x56, 0xd2, 0x15, 0x24, 0x59, 0x2c, 0x1c, 0x5b, 0x5f, 0x93,
+	0x12, 0x50, 0x17, 0x2a, 0x9c, 0x90, 0xd0, 0xa9, 0x7e, 0xba, 0xdc, 0x17, 0xbb, 0x26, 0xb8, 0x04,
+	0xa2, 0xa7, 0x60, 0xf3, 0x24, 0x63, 0x01, 0x71, 0x6a, 0xaa, 0xd2, 0xd5, 0x2b, 0x95, 0x0e, 0xd4,
+	0xa1, 0x6f, 0x40, 0x12, 0x9e, 0x62, 0x46, 0xc5, 0xa5, 0x53, 0xbf, 0x11, 0xfe, 0x46, 0x1d, 0xfa,
+	0x06, 0xe4, 0xbe, 0x84, 0x66, 0x91, 0x2a, 0xd4, 0x80, 0xea, 0xd7, 0xfd, 0xde, 0xeb, 0x53, 0xff,
+	0xa4, 0x7d, 0x07, 0x01, 0xd8, 0xfd, 0x53, 0xff, 0x64, 0xef, 0xb8, 0x6d, 0xa1, 0xbb, 0xd0, 0x38,
+	0xfa, 0xf6, 0xcd, 0x69, 0xff, 0xa8, 0x7f, 0xd6, 0xdb, 0x3b, 0x6e, 0x97, 0xdc, 0x0d, 0xb0, 0x75,
+	0x68, 0x54, 0x83, 0xca, 0xc9, 0xde, 0xd9, 0x57, 0xda, 0xe0, 0xc0, 0x7f, 0xf7, 0xe6, 0xec, 0xb4,
+	0x6d, 0xb9, 0x8f, 0xc1, 0xd6, 0xb1, 0x50, 0x15, 0xca, 0x7b, 0xfd, 0x77, 0xed, 0x3b, 0x12, 0x78,
+	0xf4, 0xf6, 0xa8, 0xdf, 0xb6, 0xa4, 0xea, 0xf4, 0xf0, 0xb0, 0x5d, 0x72, 0x23, 0x68, 0xe6, 0xf9,
+	0x7d, 0xa6, 0xe3, 0xff, 0x01, 0xb6, 0xfa, 0xe0, 0x4e, 0x69, 0xab, 0xbc, 0xbd, 0xe4, 0x1b, 0x69,
+	0x46, 0x66, 0xf9, 0x96, 0x64, 0xba, 0x63, 0x58, 0xd1, 0xe1, 0x06, 0x82, 0x11, 0x1c, 0x15, 0x46,
+	0x44, 0x5f, 0x95, 0x55, 0xbc, 0x2a, 0x04, 0x15, 0x86, 0x05, 0x51, 0x9d, 0x69, 0xf9, 0xea, 0x1b,
+	0x3d, 0x01, 0x9b, 0x29, 0x07, 0x26, 0xe6, 0xf2, 0x22, 0xbd, 0xbe, 0x39, 0x75, 0xef, 0x42, 0xeb,
+	0x9b, 0x49, 0xb2, 0x17, 0xf5, 0xcc, 0x81, 0xfb, 0x2f, 0x68, 0x1c, 0x05, 0x93, 0x24, 0x8f, 0xe8,
+	0x40, 0x35, 0x22, 0x9c, 0xe3, 0x71, 0x3e, 0x4d, 0xb9, 0xe8, 0xfe, 0x5e, 0x87, 0xda, 0x01, 0x9e,
+	0x4e, 0x7b, 0xf1, 0x79, 0x22, 0x0b, 0x8f, 0x88, 0x98, 0x24, 0xa1, 0x41, 0x19, 0x09, 0x3d, 0x87,
+	0x5a, 0x44, 0x04, 0x0e, 0xb1, 0xc0, 0x8a, 0x92, 0xc6, 0xce, 0x7d, 0x2f, 0x37, 0xf2, 0x4e, 0xcc,
+	0xc9, 0x51, 0x2c, 0xd8, 0xa5, 0x3f, 0x03, 0xa2, 0x87, 0xd0, 0x4c, 0x09, 0x61, 0x43, 0x1c, 0x86,
+	0x8c, 0x70, 0xae, 0x2a, 0xa8, 0xfb, 0x0d, 0xa9, 0xdb, 0xd3, 0x2a, 0xb4, 0x09, 0x65, 0x31, 0xe5,
+	0x66, 0x34, 0x5a, 0x73, 0x97, 0x67, 0xc7, 0x03, 0x5f, 0x9e, 0xa0, 0x17, 0x50, 0x0b, 0x09, 0x0e,
+	0xa7, 0x34, 0x26, 0x66, 0x18, 0x3a, 0xd7, 0x58, 0x3f, 0xcb, 0x1f, 0x44, 0x7f, 0x86, 0x45, 0x0f,
+	0x00, 0x98, 0x2e, 0x7d, 0x48, 0x43, 0x35, 0x11, 0x75, 0xbf, 0x6e, 0x34, 0xbd, 0x10, 0x79, 0x50,
+	0xa3, 0x21, 0x89, 0x85, 0xec, 0x5b, 0x3d, 0x19, 0x68, 0x1e, 0xbc, 0x67, 0x4e, 0xfc, 0x19, 0x06,
+	0x6d, 0x83, 0xcd, 0x09, 0xbb, 0x20, 0x4c, 0x0d, 0x45, 0x63, 0xa7, 0x3d, 0x47, 0x0f, 0x94, 0xde,
+	0x37, 0xe7, 0x9d, 0x2d, 0xb0, 0xdf, 0xea, 0x66, 0x99, 0x37, 0x91, 0xb5, 0x55, 0x96, 0x5c, 0x6a,
+	0xa9, 0xf3, 0xb3, 0x05, 0xe5, 0xb3, 0xe3, 0x81, 0xbc, 0x92, 0x0b, 0xc2, 0xb8, 0x7c, 0x53, 0xcc,
+	0x95, 0x18, 0x51, 0x12, 0x17, 0xd0, 0x74, 0x42, 0xd8, 0x90, 0x67, 0xd4, 0x34, 0x44, 0xdd, 0x6f,
+	0x68, 0xdd, 0x40, 0xaa, 0xd0, 0x26, 0x34, 0x74, 0xc0, 0x61, 0x8c, 0x23, 0x62, 0xa8, 0x05, 0xad,
+	0xea, 0xe3, 0x88, 0xa0, 0x2f, 0xa0, 0xad, 0xc8, 0x0f, 0x08, 0x13, 0xf4, 0x9c, 0x06, 0xb2, 0xb1,
+	0x34, 0xcd, 0xab, 0xf3, 0xdc, 0x0f, 0xe6, 0x87, 0xfe, 0x5d, 0x09, 0x2f, 0x28, 0x3a, 0xbf, 0x94,
+	0xa0, 0x51, 0x90, 0x65, 0xbe, 0x3c, 0x1b, 0x7d, 0x47, 0x02, 0x91, 0xe7, 0x6b, 0x44, 0x59, 0x29,
+	0xe5, 0x3c, 0x23, 0xcc, 0x64, 0x6a, 0x24, 0xf4, 0x08, 0x5a, 0x9c, 0x30, 0x8a, 0xa7, 0xc3, 0x38,
+	0x8b, 0x46, 0x84, 0x99, 0x34, 0x9b, 0x5a, 0xd9, 0x57, 0x3a, 0xf4, 0x5f, 0x80, 0x38, 0x11, 0xc3,
+	0x11, 0x39, 0x4f, 0x58, 0x9e, 0xe2, 0xe7, 0xee, 0xb8, 0x1e, 0x27, 0x62, 0x5f, 0x81, 0xd1, 0x7f,
+	0x40, 0x0a, 0x43, 0x7c, 0x2e, 0x08, 0xbb, 0x4d, 0x77, 0xc4, 0x89, 0xd8, 0x93, 0x58, 0xb4, 0x06,
+	0xf5, 0x30, 0xe6, 0x8a, 0x3a, 0xee, 0xd8, 0xea, 0x76, 0x6a, 0x61, 0xcc, 0x25, 0x71, 0x5c, 0x8e,
+	0x61, 0xc6, 0x28, 0x77, 0xaa, 0x4a, 0xaf, 0xbe, 0xa5, 0x01, 0x4f, 0xe9, 0xf9, 0x39, 0x91, 0xdd,
+	0x54, 0x53, 0x55, 0xd4, 0xb4, 0xa2, 0x17, 0x76, 0xfe, 0xb0, 0xa0, 0x96, 0xf7, 0x0c, 0x5a, 0x86,
+	0x12, 0xcd, 0xa7, 0xa7, 0x44, 0x43, 0x39, 0xea, 0x24, 0xc2, 0x74, 0x6a, 0xa8, 0xd1, 0x82, 0x64,
+	0x4c, 0x90, 0x18, 0xc7, 0xc2, 0x50, 0x62, 0x24, 0xb4, 0x01, 0x10, 0x24, 0x71, 0x4c, 0x02, 0xb5,
+	0x6a, 0x2a, 0xfa, 0x56, 0xe7, 0x1a, 0x99, 0x47, 0x30, 0xa5, 0x24, 0x56, 0x5d, 0xbd, 0xa4, 0xf3,
+	0xd0, 0x8a, 0x5e, 0x28, 0x9d, 0xf2, 0x20, 0x49, 0x67, 0x25, 0x19, 0x49, 0xea, 0xc7, 0x2c, 0xc9,
+	0xd2, 0xbc, 0x24, 0x23, 0xc9, 0xd4, 0x58, 0x32, 0x25, 0xdc, 0xa9, 0x29, 0xb5, 0x16, 0x54, 0xc2,
+	0x8c, 0x25, 0xcc, 0xa9, 0x9b, 0x84, 0xa5, 0xd0, 0x99, 0x82, 0xad, 0x1b, 0x5d, 0xb5, 0x01, 0x61,
+	0x17, 0x34, 0x98, 0xbd, 0x24, 0x46, 0x44, 0x6d, 0x28, 0xa7, 0x49, 0x68, 0x0a, 0x95, 0x9f, 0x68,
+	0x1d, 0xea, 0x8a, 0xe3, 0x14, 0x07, 0x79, 0x8f, 0xce, 0x15, 0xc5, 0x01, 0xa8, 0x2c, 0x0c, 0x40,
+	0xe7, 0x04, 0x5a, 0x0b, 0x8f, 0x8a, 0x74, 0xfd, 0x3d, 0xb9, 0x34, 0x01, 0xe5, 0x27, 0x7a, 0x92,
+	0x3f, 0xdc, 0xa5, 0xab, 0x03, 0xa9, 0xc7, 0xcf, 0x3c, 0xe5, 0xaf, 0x4a, 0x2f, 0x2d, 0xf7, 0x4b,
+	0x68, 0xea, 0xb7, 0x90, 0xa7, 0x49, 0xcc, 0xc9, 0xa7, 0x1f, 0x43, 0xf4, 0x00, 0x2a, 0x01, 0x9e,
+	0x4e, 0x8d, 0xd3, 0xfa, 0xcc, 0xa9, 0xaf, 0xd4, 0x3b, 0x7f, 0x95, 0xa1, 0x71, 0x82, 0xc5, 0x64,
+	0x60, 0x2a, 0x7e, 0x09, 0xf6, 0xe0, 0x7d, 0x86, 0x19, 0x41, 0x35, 0xcf, 0xbc, 0xb4, 0x9d, 0xaa,
+	0xa7, 0x57, 0x8b, 0xbb, 0xf6, 0xc3, 0x6f, 0x7f, 0xfe, 0x58, 0x5a, 0x7d, 0x65, 0xfe, 0x5b, 0x9a,
+	0xdd, 0x08, 0x8b, 0x49, 0x97, 0x6b, 0xfc, 0x09, 0x34, 0xb4, 0xa5, 0x5a, 0x19, 0xa8, 0xe5, 0x15,
+	0x7f, 0xa1, 0x3a, 0x4d, 0xaf, 0xf0, 0xaf, 0xe4, 0x3e, 0x54, 0x8e, 0xd6, 0x72, 0x47, 0xa8, 0xe8,
+	0xa8, 0x4b, 0x95, 0xfd, 0x6b, 0xa8, 0x6b, 0x77, 0xfb, 0x74, 0x8c, 0x1a, 0xde, 0xfc, 0x27, 0xaa,
+	0x53, 0x14, 0xdc, 0x4d, 0xe5, 0xe9, 0x9f, 0x6e, 0x7b, 0xc1, 0xc5, 0x88, 0x8e, 0x8d, 0x6f, 0xf4,
+	0x3f, 0xb0, 0xf5, 0x7e, 0x41, 0x57, 0x16, 0x4d, 0xa7, 0xe5, 0x15, 0xf7, 0xa6, 0x7b, 0x4f, 0x79,
+	0x5a, 0x46, 0xa6, 0x2a, 0xbd, 0x85, 0xd0, 0x31, 0x34, 0x8b, 0xeb, 0x0e, 0xdd, 0xf3, 0x6e, 0xd8,
+	0x7e, 0xd7, 0x18, 0x42, 0x2b, 0x45, 0x27, 0x5d, 0xae, 0xc0, 0xcf, 0x2c, 0xb4, 0x0b, 0xe5, 0x41,
+	0x16, 0xdd, 0x44, 0xad, 0xa3, 0x0c, 0x51, 0xce, 0x48, 0xdd, 0x94, 0x93, 0x45, 0xdb, 0x16, 0xea,
+	0x41, 0x53, 0x53, 0x61, 0x72, 0xb8, 0xc1, 0xdc, 0x55, 0xe6, 0xeb, 0xee, 0xca, 0x02, 0x0d, 0x3a,
+	0xae, 0xf1, 0xb9, 0x6d, 0x3d, 0xb3, 0x76, 0x7e, 0xb2, 0xa0, 0xd5, 0x8b, 0x05, 0x4b, 0x78, 0x6a,
+	0xe6, 0x6f, 0x1f, 0x6c, 0xbd, 0x66, 0xd1, 0xb2, 0xb7, 0xb0, 0x6f, 0x3b, 0xf3, 0x5e, 0x71, 0x1f,
+	0x28, 0xf7, 0xf7, 0xd1, 0x6a, 0x97, 0x16, 0x8d, 0xbb, 0x1f, 0x26, 0x09, 0x8e, 0x28, 0x3a, 0x80,
+	0x8a, 0xec, 0x46, 0xd4, 0xf4, 0x0a, 0x0b, 0xba, 0xd3, 0xf2, 0x8a, 0x2d, 0xea, 0x6e, 0x28, 0x1f,
+	0xce, 0x2b, 0xeb, 0xdf, 0xee, 0xca, 0x15, 0x37, 0x24, 0x98, 0x24, 0x23, 0x5b, 0xbd, 0x6f, 0xcf,
+	0xff, 0x1e, 0x00, 0x15, 0xe2, 0x8e, 0xf2, 0x50, 0x0c, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// MathServiceClient is the client API for MathService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type MathServiceClient interface {
+	Square(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Result, error)
+	// Square for values up to 3037000499 in magnitude.
+	SquareInt64(ctx context.Context, in *Int64Request, opts ...grpc.CallOption) (*Int64Result, error)
+	// Square of a value of up to 10,000 digits.
+	SquareBig(ctx context.Context, in *BigInteger, opts ...grpc.CallOption) (*BigInteger, error)
+	Random(ctx context.Context, in *RandomRequest, opts ...grpc.CallOption) (*RandomResult, error)
+	RandomStream(ctx context.Context, in *RandomStreamRequest, opts ...grpc.CallOption) (MathService_RandomStreamClient, error)
+	Sum(ctx context.Context, opts ...grpc.CallOption) (MathService_SumClient, error)
+	SquareStream(ctx context.Context, opts ...grpc.CallOption) (MathService_SquareStreamClient, error)
+}
+
+type mathServiceClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewMathServiceClient(cc *grpc.ClientConn) MathServiceClient {
+	return &mathServiceClient{cc}
+}
+
+func (c *mathServiceClient) Square(ctx context.Context, in *Request, opts ...grpc.CallOption) (*Result, error) {
+	out := new(Result)
+	err := c.cc.Invoke(ctx, "/MathService/Square", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mathServiceClient) SquareInt64(ctx context.Context, in *Int64Request, opts ...grpc.CallOption) (*Int64Result, error) {
+	out := new(Int64Result)
+	err := c.cc.Invoke(ctx, "/MathService/SquareInt64", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mathServiceClient) SquareBig(ctx context.Context, in *BigInteger, opts ...grpc.CallOption) (*BigInteger, error) {
+	out := new(BigInteger)
+	err := c.cc.Invoke(ctx, "/MathService/SquareBig", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mathServiceClient) Random(ctx context.Context, in *RandomRequest, opts ...grpc.CallOption) (*RandomResult, error) {
+	out := new(RandomResult)
+	err := c.cc.Invoke(ctx, "/MathService/Random", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mathServiceClient) RandomStream(ctx context.Context, in *RandomStreamRequest, opts ...grpc.CallOption) (MathService_RandomStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_MathService_serviceDesc.Streams[0], "/MathService/RandomStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &mathServiceRandomStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type MathService_RandomStreamClient interface {
+	Recv() (*Result, error)
+	grpc.ClientStream
+}
+
+type mathServiceRandomStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *mathServiceRandomStreamClient) Recv() (*Result, error) {
+	m := new(Result)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *mathServiceClient) Sum(ctx context.Context, opts ...grpc.CallOption) (MathService_SumClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_MathService_serviceDesc.Streams[1], "/MathService/Sum", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &mathServiceSumClient{stream}
+	return x, nil
+}
+
+type MathService_SumClient interface {
+	Send(*Request) error
+	CloseAndRecv() (*Result, error)
+	grpc.ClientStream
+}
+
+type mathServiceSumClient struct {
+	grpc.ClientStream
+}
+
+func (x *mathServiceSumClient) Send(m *Request) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *mathServiceSumClient) CloseAndRecv() (*Result, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(Result)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *mathServiceClient) SquareStream(ctx context.Context, opts ...grpc.CallOption) (MathService_SquareStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_MathService_serviceDesc.Streams[2], "/MathService/SquareStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &mathServiceSquareStreamClient{stream}
+	return x, nil
+}
+
+type MathService_SquareStreamClient interface {
+	Send(*Request) error
+	Recv() (*Result, error)
+	grpc.ClientStream
+}
+
+type mathServiceSquareStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *mathServiceSquareStreamClient) Send(m *Request) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *mathServiceSquareStreamClient) Recv() (*Result, error) {
+	m := new(Result)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// MathServiceServer is the server API for MathService service.
+type MathServiceServer interface {
+	Square(context.Context, *Request) (*Result, error)
+	// Square for values up to 3037000499 in magnitude.
+	SquareInt64(context.Context, *Int64Request) (*Int64Result, error)
+	// Square of a value of up to 10,000 digits.
+	SquareBig(context.Context, *BigInteger) (*BigInteger, error)
+	Random(context.Context, *RandomRequest) (*RandomResult, error)
+	RandomStream(*RandomStreamRequest, MathService_RandomStreamServer) error
+	Sum(MathService_SumServer) error
+	SquareStream(MathService_SquareStreamServer) error
+}
+
+// UnimplementedMathServiceServer can be embedded to have forward compatible implementations.
+type UnimplementedMathServiceServer struct {
+}
+
+func (*UnimplementedMathServiceServer) Square(ctx context.Context, req *Request) (*Result, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Square not implemented")
+}
+func (*UnimplementedMathServiceServer) SquareInt64(ctx context.Context, req *Int64Request) (*Int64Result, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SquareInt64 not implemented")
+}
+func (*UnimplementedMathServiceServer) SquareBig(ctx context.Context, req *BigInteger) (*BigInteger, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SquareBig not implemented")
+}
+func (*UnimplementedMathServiceServer) Random(ctx context.Context, req *RandomRequest) (*RandomResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Random not implemented")
+}
+func (*UnimplementedMathServiceServer) RandomStream(req *RandomStreamRequest, srv MathService_RandomStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method RandomStream not implemented")
+}
+func (*UnimplementedMathServiceServer) Sum(srv MathService_SumServer) error {
+	return status.Errorf(codes.Unimplemented, "method Sum not implemented")
+}
+func (*UnimplementedMathServiceServer) SquareStream(srv MathService_SquareStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method SquareStream not implemented")
+}
+
+func RegisterMathServiceServer(s *grpc.Server, srv MathServiceServer) {
+	s.RegisterService(&_MathService_serviceDesc, srv)
+}
+
+func _MathService_Square_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MathServiceServer).Square(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/MathService/Square",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MathServiceServer).Square(ctx, req.(*Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MathService_SquareInt64_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Int64Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MathServiceServer).SquareInt64(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/MathService/SquareInt64",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MathServiceServer).SquareInt64(ctx, req.(*Int64Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MathService_SquareBig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BigInteger)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MathServiceServer).SquareBig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/MathService/SquareBig",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MathServiceServer).SquareBig(ctx, req.(*BigInteger))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MathService_Random_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RandomRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MathServiceServer).Random(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/MathService/Random",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MathServiceServer).Random(ctx, req.(*RandomRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MathService_RandomStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RandomStreamRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MathServiceServer).RandomStream(m, &mathServiceRandomStreamServer{stream})
+}
+
+type MathService_RandomStreamServer interface {
+	Send(*Result) error
+	grpc.ServerStream
+}
+
+type mathServiceRandomStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *mathServiceRandomStreamServer) Send(m *Result) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _MathService_Sum_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(MathServiceServer).Sum(&mathServiceSumServer{stream})
+}
+
+type MathService_SumServer interface {
+	SendAndClose(*Result) error
+	Recv() (*Request, error)
+	grpc.ServerStream
+}
+
+type mathServiceSumServer struct {
+	grpc.ServerStream
+}
+
+func (x *mathServiceSumServer) SendAndClose(m *Result) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *mathServiceSumServer) Recv() (*Request, error) {
+	m := new(Request)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _MathService_SquareStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(MathServiceServer).SquareStream(&mathServiceSquareStreamServer{stream})
+}
+
+type MathService_SquareStreamServer interface {
+	Send(*Result) error
+	Recv() (*Request, error)
+	grpc.ServerStream
+}
+
+type mathServiceSquareStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *mathServiceSquareStreamServer) Send(m *Result) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *mathServiceSquareStreamServer) Recv() (*Request, error) {
+	m := new(Request)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _MathService_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "MathService",
+	HandlerType: (*MathServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Square",
+			Handler:    _MathService_Square_Handler,
+		},
+		{
+			MethodName: "SquareInt64",
+			Handler:    _MathService_SquareInt64_Handler,
+		},
+		{
+			MethodName: "SquareBig",
+			Handler:    _MathService_SquareBig_Handler,
+		},
+		{
+			MethodName: "Random",
+			Handler:    _MathService_Random_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "RandomStream",
+			Handler:       _MathService_RandomStream_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Sum",
+			Handler:       _MathService_Sum_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "SquareStream",
+			Handler:       _MathService_SquareStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "pb/grpc_test.proto",
+}
+
+// IntrospectionClient is the client API for Introspection service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type IntrospectionClient interface {
+	WhoAmI(ctx context.Context, in *WhoAmIRequest, opts ...grpc.CallOption) (*CallInfo, error)
+	// WhoAmI, sending the message back too.
+	Echo(ctx context.Context, in *EchoRequest, opts ...grpc.CallOption) (*EchoResponse, error)
+}
+
+type introspectionClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewIntrospectionClient(cc *grpc.ClientConn) IntrospectionClient {
+	return &introspectionClient{cc}
+}
+
+func (c *introspectionClient) WhoAmI(ctx context.Context, in *WhoAmIRequest, opts ...grpc.CallOption) (*CallInfo, error) {
+	out := new(CallInfo)
+	err := c.cc.Invoke(ctx, "/Introspection/WhoAmI", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *introspectionClient) Echo(ctx context.Context, in *EchoRequest, opts ...grpc.CallOption) (*EchoResponse, error) {
+	out := new(EchoResponse)
+	err := c.cc.Invoke(ctx, "/Introspection/Echo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// IntrospectionServer is the server API for Introspection service.
+type IntrospectionServer interface {
+	WhoAmI(context.Context, *WhoAmIRequest) (*CallInfo, error)
+	// WhoAmI, sending the message back too.
+	Echo(context.Context, *EchoRequest) (*EchoResponse, error)
+}
+
+// UnimplementedIntrospectionServer can be embedded to have forward compatible implementations.
+type UnimplementedIntrospectionServer struct {
+}
+
+func (*UnimplementedIntrospectionServer) WhoAmI(ctx context.Context, req *WhoAmIRequest) (*CallInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WhoAmI not implemented")
+}
+func (*UnimplementedIntrospectionServer) Echo(ctx context.Context, req *EchoRequest) (*EchoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Echo not implemented")
+}
+
+func RegisterIntrospectionServer(s *grpc.Server, srv IntrospectionServer) {
+	s.RegisterService(&_Introspection_serviceDesc, srv)
+}
+
+func _Introspection_WhoAmI_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WhoAmIRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IntrospectionServer).WhoAmI(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Introspection/WhoAmI",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IntrospectionServer).WhoAmI(ctx, req.(*WhoAmIRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Introspection_Echo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EchoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IntrospectionServer).Echo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/Introspection/Echo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IntrospectionServer).Echo(ctx, req.(*EchoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Introspection_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "Introspection",
+	HandlerType: (*IntrospectionServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "WhoAmI",
+			Handler:    _Introspection_WhoAmI_Handler,
+		},
+		{
+			MethodName: "Echo",
+			Handler:    _Introspection_Echo_Handler,
 		},
 	},
 	Streams:  []grpc.StreamDesc{},
@@ -0,0 +1,88 @@
+package profile
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	"github.com/zenoss/grpctest/fault"
+	"github.com/zenoss/grpctest/grpcutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// pairs names the profile and version in headers and trailers.
+func (a Active) pairs() metadata.MD {
+	md := metadata.Pairs(HeaderProfile, a.Name)
+	if a.Version != "" {
+		md.Set(HeaderVersion, a.Version)
+	}
+	return md
+}
+
+func draw(percent float64) bool {
+	return percent >= 100 || rand.Float64()*100 < percent
+}
+
+// misbehave delays or fails the call as the profile says, drawing each
+// separately.
+func (a Active) misbehave(ctx context.Context) error {
+	if a.Delay > 0 && draw(a.DelayPercent) {
+		grpc_ctxtags.Extract(ctx).Set("profile.delay", a.Delay.String())
+		t := time.NewTimer(a.Delay)
+		defer t.Stop()
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+	if a.ErrorCode != "" && draw(a.ErrorPercent) {
+		// Validate checked the code.
+		code, _, _ := fault.ParseCode(a.ErrorCode)
+		grpc_ctxtags.Extract(ctx).Set("profile.code", code.String())
+		return status.Errorf(code, "profile %s: injected %s", a.Name, code)
+	}
+	return nil
+}
+
+// UnaryServerInterceptor names the active profile and version in every
+// call's headers and trailers, and applies the profile's delays and errors
+// to the calls of services. It calls active on every call, so a new
+// profile applies at once. Chain it before auth, so rejected calls are
+// named too.
+func UnaryServerInterceptor(active func() Active, services ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		a := active()
+		md := a.pairs()
+		grpc.SetHeader(ctx, md)
+		grpc.SetTrailer(ctx, md)
+		grpc_ctxtags.Extract(ctx).Set("profile", a.Name)
+		if grpcutil.InServices(info.FullMethod, services) {
+			if err := a.misbehave(ctx); err != nil {
+				return nil, err
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is UnaryServerInterceptor for streams, which are
+// delayed or failed before the handler runs.
+func StreamServerInterceptor(active func() Active, services ...string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		a := active()
+		md := a.pairs()
+		ss.SetHeader(md)
+		ss.SetTrailer(md)
+		grpc_ctxtags.Extract(ss.Context()).Set("profile", a.Name)
+		if grpcutil.InServices(info.FullMethod, services) {
+			if err := a.misbehave(ss.Context()); err != nil {
+				return err
+			}
+		}
+		return handler(srv, ss)
+	}
+}
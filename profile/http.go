@@ -0,0 +1,27 @@
+package profile
+
+import (
+	"net/http"
+	"net/textproto"
+)
+
+// Middleware names the active profile and version in the headers and
+// trailers of every HTTP response. The profile's delays and errors aren't
+// applied here: transcoded calls get them from the gRPC server.
+func Middleware(active func() Active, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a := active()
+		set := func(name, value string) {
+			// Declared trailers are sent again, with the headers' values,
+			// once the response is written, and make HTTP/1.1 responses
+			// chunked so they can be.
+			w.Header().Set(textproto.CanonicalMIMEHeaderKey(name), value)
+			w.Header().Add("Trailer", name)
+		}
+		set(HeaderProfile, a.Name)
+		if a.Version != "" {
+			set(HeaderVersion, a.Version)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
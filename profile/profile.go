@@ -0,0 +1,111 @@
+// Package profile makes a deployment behave like a different version of
+// the service, so canary routing can be tested with subsets that actually
+// differ: a named Profile may restrict the parity of random values, slow
+// down or fail a share of calls, or answer with a field the others don't
+// send. Every response names the active profile and version in its
+// headers and trailers, so a routing experiment can tell which subset
+// served each call.
+package profile
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/zenoss/grpctest/fault"
+)
+
+// The response headers, and gRPC metadata keys, naming what served a call.
+const (
+	HeaderProfile = "x-grpctest-profile"
+	HeaderVersion = "x-grpctest-version"
+)
+
+// Default is the profile that behaves normally.
+const Default = "default"
+
+// Profile is how a version behaves differently.
+type Profile struct {
+	// Parity is any, even or odd, and overrides random.parity when set.
+	Parity string `mapstructure:"parity"`
+	// Delay slows down DelayPercent of the calls, 0 to 100.
+	Delay        time.Duration `mapstructure:"delay"`
+	DelayPercent float64       `mapstructure:"delay_percent"`
+	// ErrorCode, a gRPC code or HTTP status as fault reads them, fails
+	// ErrorPercent of the calls.
+	ErrorCode    string  `mapstructure:"error_code"`
+	ErrorPercent float64 `mapstructure:"error_percent"`
+	// SchemaField sets fields only this version's responses have.
+	SchemaField bool `mapstructure:"schema_field"`
+}
+
+// Builtin are the profiles every server has. Profiles of the same name in
+// the config replace them.
+var Builtin = map[string]Profile{
+	Default:                  {},
+	"even-only":              {Parity: "even"},
+	"odd-only":               {Parity: "odd"},
+	"slow-10pct":             {Delay: time.Second, DelayPercent: 10},
+	"error-5pct":             {ErrorCode: "UNAVAILABLE", ErrorPercent: 5},
+	"different-schema-field": {SchemaField: true},
+}
+
+// Validate checks the parity, code and percentages.
+func (p Profile) Validate() error {
+	switch strings.ToLower(p.Parity) {
+	case "", "any", "even", "odd":
+	default:
+		return errors.Errorf("unknown parity %q", p.Parity)
+	}
+	if p.Delay < 0 {
+		return errors.New("delay must not be negative")
+	}
+	if p.DelayPercent < 0 || p.DelayPercent > 100 || p.ErrorPercent < 0 || p.ErrorPercent > 100 {
+		return errors.New("delay_percent and error_percent must be between 0 and 100")
+	}
+	if p.ErrorCode != "" {
+		if _, _, err := fault.ParseCode(p.ErrorCode); err != nil {
+			return errors.Wrap(err, "error_code")
+		}
+	}
+	return nil
+}
+
+// Lookup finds the named profile among custom ones and then the Builtin
+// ones; no name is the Default.
+func Lookup(custom map[string]Profile, name string) (Profile, error) {
+	if name == "" {
+		name = Default
+	}
+	if p, ok := custom[name]; ok {
+		return p, nil
+	}
+	if p, ok := Builtin[name]; ok {
+		return p, nil
+	}
+	return Profile{}, errors.Errorf("unknown profile %q; have %s", name, strings.Join(Names(custom), ", "))
+}
+
+// Names lists the custom and Builtin profiles' names, sorted.
+func Names(custom map[string]Profile) []string {
+	var names []string
+	for name := range Builtin {
+		if _, ok := custom[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	for name := range custom {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Active is the profile a server runs, with its name and the server's
+// version, which may be empty.
+type Active struct {
+	Name    string
+	Version string
+	Profile
+}
@@ -0,0 +1,77 @@
+package quota
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/zenoss/zenkit"
+)
+
+// Attributes describe a request for the dimension expressions.
+type Attributes struct {
+	// Destination is the name of the service, the app label in Istio.
+	Destination string
+	// Method is the full gRPC method name or the HTTP path.
+	Method   string
+	SourceIP string
+	// Identity is nil for anonymous requests.
+	Identity zenkit.TenantIdentity
+}
+
+const claimsPrefix = "https://dev.zing.ninja/"
+
+// attribute returns the value of a named attribute. The Mixer names used in
+// yaml/rate_redis.yaml are accepted alongside the short ones.
+func (a *Attributes) attribute(name string) string {
+	switch name {
+	case "destination", "destination.service", `destination.labels["app"]`:
+		return a.Destination
+	case "method", "request.path", "api.operation":
+		return a.Method
+	case "source.ip":
+		return a.SourceIP
+	}
+	if a.Identity == nil {
+		return ""
+	}
+	switch name {
+	case "tenant", `request.auth.claims["` + claimsPrefix + `tenant"]`:
+		return a.Identity.Tenant()
+	case "email", `request.auth.claims["` + claimsPrefix + `email"]`:
+		return a.Identity.Email()
+	case "user", `request.auth.claims["sub"]`:
+		return a.Identity.ID()
+	case "client_id", `request.auth.claims["` + claimsPrefix + `clientid"]`:
+		return a.Identity.ClientID()
+	case "connection", `request.auth.claims["` + claimsPrefix + `connection"]`:
+		return a.Identity.Connection()
+	}
+	return ""
+}
+
+// eval evaluates an expression of attributes and quoted literals separated
+// by |, returning the first that isn't empty.
+func (a *Attributes) eval(expr string) string {
+	for _, term := range strings.Split(expr, "|") {
+		term = strings.TrimSpace(term)
+		if s, err := strconv.Unquote(term); err == nil {
+			if s != "" {
+				return s
+			}
+			continue
+		}
+		if v := a.attribute(term); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// dimensions evaluates every dimension of cfg.
+func (a *Attributes) dimensions(cfg *Config) map[string]string {
+	values := make(map[string]string, len(cfg.Dimensions))
+	for name, expr := range cfg.Dimensions {
+		values[name] = a.eval(expr)
+	}
+	return values
+}
@@ -0,0 +1,197 @@
+// Package quota enforces request quotas the way Istio's memquota and
+// redisquota adapters did for the Mixer configs in yaml/: requests are
+// described by dimensions, each quota has a limit per combination of
+// dimension values, and the first matching override replaces the limit.
+package quota
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Algorithm is a rate limit algorithm, named as in the redisquota spec.
+type Algorithm string
+
+const (
+	// FixedWindow allows MaxAmount per ValidDuration, counted from the
+	// start of each window.
+	FixedWindow Algorithm = "FIXED_WINDOW"
+	// RollingWindow allows MaxAmount in any ValidDuration, counted in
+	// buckets of BucketDuration.
+	RollingWindow Algorithm = "ROLLING_WINDOW"
+)
+
+// Override replaces a quota's limit for requests whose dimensions match.
+// Durations left unset are taken from the quota.
+type Override struct {
+	Dimensions     map[string]string `mapstructure:"dimensions"`
+	MaxAmount      int64             `mapstructure:"maxAmount"`
+	ValidDuration  time.Duration     `mapstructure:"validDuration"`
+	BucketDuration time.Duration     `mapstructure:"bucketDuration"`
+}
+
+// Quota is one entry of the adapter's quotas list.
+type Quota struct {
+	Name               string        `mapstructure:"name"`
+	MaxAmount          int64         `mapstructure:"maxAmount"`
+	ValidDuration      time.Duration `mapstructure:"validDuration"`
+	BucketDuration     time.Duration `mapstructure:"bucketDuration"`
+	RateLimitAlgorithm Algorithm     `mapstructure:"rateLimitAlgorithm"`
+	// The first matching override is applied.
+	Overrides []Override `mapstructure:"overrides"`
+}
+
+// Charge is how much one request takes from a quota.
+type Charge struct {
+	Quota  string `mapstructure:"quota"`
+	Charge int64  `mapstructure:"charge"`
+}
+
+// Rule charges quotas for the methods it matches, like a QuotaSpec rule.
+// Every matching rule applies.
+type Rule struct {
+	// Match lists full method names or HTTP paths; a trailing * matches
+	// any suffix. An empty list matches every request.
+	Match  []string `mapstructure:"match"`
+	Quotas []Charge `mapstructure:"quotas"`
+}
+
+func (r *Rule) matches(method string) bool {
+	if len(r.Match) == 0 {
+		return true
+	}
+	for _, m := range r.Match {
+		if strings.HasSuffix(m, "*") && strings.HasPrefix(method, strings.TrimSuffix(m, "*")) {
+			return true
+		}
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// Config is the quota instance, adapter and spec rolled into one.
+type Config struct {
+	// Dimensions maps each dimension name to an attribute expression, as in
+	// the quota instance, e.g.
+	//
+	//	tenant: request.auth.claims["https://dev.zing.ninja/tenant"] | "unknown"
+	Dimensions map[string]string `mapstructure:"dimensions"`
+	Quotas     []Quota           `mapstructure:"quotas"`
+	Rules      []Rule            `mapstructure:"rules"`
+}
+
+// Validate checks that the quotas are usable and the rules refer to them.
+func (c *Config) Validate() error {
+	names := map[string]bool{}
+	for i := range c.Quotas {
+		q := &c.Quotas[i]
+		if q.Name == "" {
+			return errors.Errorf("quota %d has no name", i)
+		}
+		if names[q.Name] {
+			return errors.Errorf("quota %s is defined twice", q.Name)
+		}
+		names[q.Name] = true
+		if q.RateLimitAlgorithm == "" {
+			q.RateLimitAlgorithm = FixedWindow
+		}
+		if err := q.limit().Validate(); err != nil {
+			return errors.Wrapf(err, "quota %s", q.Name)
+		}
+		for j := range q.Overrides {
+			for dim := range q.Overrides[j].Dimensions {
+				if _, ok := c.Dimensions[dim]; !ok {
+					return errors.Errorf("quota %s override %d uses unknown dimension %s", q.Name, j, dim)
+				}
+			}
+			if err := q.overrideLimit(&q.Overrides[j]).Validate(); err != nil {
+				return errors.Wrapf(err, "quota %s override %d", q.Name, j)
+			}
+		}
+	}
+	for i, r := range c.Rules {
+		for _, ch := range r.Quotas {
+			if !names[ch.Quota] {
+				return errors.Errorf("rule %d charges unknown quota %s", i, ch.Quota)
+			}
+		}
+	}
+	return nil
+}
+
+// Limit is the effective limit of a quota for one set of dimension values.
+type Limit struct {
+	Max       int64
+	Window    time.Duration
+	Bucket    time.Duration
+	Algorithm Algorithm
+}
+
+// Validate checks that the limit describes a usable window.
+func (l Limit) Validate() error {
+	if l.Max < 0 {
+		return errors.New("maxAmount must not be negative")
+	}
+	if l.Window <= 0 {
+		return errors.New("validDuration must be positive")
+	}
+	switch l.Algorithm {
+	case FixedWindow:
+	case RollingWindow:
+		if l.Bucket <= 0 || l.Bucket > l.Window || l.Window%l.Bucket != 0 {
+			return errors.New("bucketDuration must divide validDuration")
+		}
+	default:
+		return errors.Errorf("unknown rateLimitAlgorithm %s", l.Algorithm)
+	}
+	return nil
+}
+
+// buckets is the number of buckets in a rolling window.
+func (l Limit) buckets() int64 {
+	return int64(l.Window / l.Bucket)
+}
+
+func (q *Quota) limit() Limit {
+	return Limit{
+		Max:       q.MaxAmount,
+		Window:    q.ValidDuration,
+		Bucket:    q.BucketDuration,
+		Algorithm: q.RateLimitAlgorithm,
+	}
+}
+
+func (q *Quota) overrideLimit(o *Override) Limit {
+	l := q.limit()
+	l.Max = o.MaxAmount
+	if o.ValidDuration > 0 {
+		l.Window = o.ValidDuration
+	}
+	if o.BucketDuration > 0 {
+		l.Bucket = o.BucketDuration
+	}
+	return l
+}
+
+// match returns the limit for the dimension values: that of the first
+// override whose dimensions all have the same values, or the quota's own.
+func (q *Quota) match(values map[string]string) Limit {
+	for i := range q.Overrides {
+		o := &q.Overrides[i]
+		matched := true
+		for dim, want := range o.Dimensions {
+			if values[dim] != want {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return q.overrideLimit(o)
+		}
+	}
+	return q.limit()
+}
@@ -0,0 +1,56 @@
+package quota
+
+import (
+	"context"
+	"net"
+
+	"github.com/zenoss/grpctest/grpcutil"
+	"github.com/zenoss/zenkit"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+)
+
+// CheckContext charges the quotas of a gRPC call. The identity is taken
+// from ctx, so it has to run after authentication.
+func (l *Limiter) CheckContext(ctx context.Context, destination, fullMethod string) error {
+	attrs := &Attributes{
+		Destination: destination,
+		Method:      fullMethod,
+		Identity:    zenkit.ContextTenantIdentity(ctx),
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			attrs.SourceIP = host
+		}
+	}
+	return l.Check(ctx, attrs)
+}
+
+// UnaryServerInterceptor charges the quotas of each unary call to one of
+// services, or to any service if none are given. Chain it after the auth
+// interceptor.
+func (l *Limiter) UnaryServerInterceptor(destination string, services ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !grpcutil.InServices(info.FullMethod, services) {
+			return handler(ctx, req)
+		}
+		if err := l.CheckContext(ctx, destination, info.FullMethod); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is UnaryServerInterceptor for streams, which are
+// charged once, when they are opened.
+func (l *Limiter) StreamServerInterceptor(destination string, services ...string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !grpcutil.InServices(info.FullMethod, services) {
+			return handler(srv, ss)
+		}
+		if err := l.CheckContext(ss.Context(), destination, info.FullMethod); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
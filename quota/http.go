@@ -0,0 +1,46 @@
+package quota
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/zenoss/zenkit"
+	"google.golang.org/grpc/status"
+)
+
+// Middleware charges the quotas of each HTTP request, using the path as the
+// method. identify may be nil, or return nil for anonymous requests.
+// Exhausted quotas are answered with 429 and a Retry-After header.
+func (l *Limiter) Middleware(destination string, identify func(*http.Request) zenkit.TenantIdentity, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attrs := &Attributes{
+			Destination: destination,
+			Method:      r.URL.Path,
+		}
+		if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+			attrs.SourceIP = host
+		}
+		if identify != nil {
+			attrs.Identity = identify(r)
+		}
+		if err := l.Check(r.Context(), attrs); err != nil {
+			if retry, ok := RetryAfter(err); ok {
+				// Retry-After is in whole seconds, so round up.
+				w.Header().Set("Retry-After", strconv.FormatInt(int64((retry+time.Second-1)/time.Second), 10))
+			}
+			st := status.Convert(err)
+			body, _ := json.Marshal(struct {
+				Code    int32  `json:"code"`
+				Message string `json:"message"`
+			}{int32(st.Code()), st.Message()})
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write(body)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
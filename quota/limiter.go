@@ -0,0 +1,177 @@
+package quota
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/ptypes"
+	"github.com/sirupsen/logrus"
+	"github.com/zenoss/grpctest/metrics"
+	"go.opencensus.io/trace"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Result is the outcome of an allocation.
+type Result struct {
+	Allowed bool
+	// Remaining is what is left of the limit after the allocation.
+	Remaining int64
+	// RetryAfter is how long until the amount that was denied could be
+	// allocated.
+	RetryAfter time.Duration
+}
+
+// Backend stores quota usage.
+type Backend interface {
+	// Alloc takes amount from the usage under key if it fits in limit.
+	Alloc(key string, amount int64, limit Limit, now time.Time) (Result, error)
+}
+
+// Limiter charges requests against the quotas of a Config.
+type Limiter struct {
+	backend Backend
+	log     *logrus.Entry
+	now     func() time.Time
+
+	mu  sync.RWMutex
+	set *quotaSet
+}
+
+// quotaSet is a validated Config with its quotas indexed by name.
+type quotaSet struct {
+	cfg    Config
+	quotas map[string]*Quota
+}
+
+func newQuotaSet(cfg Config) (*quotaSet, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	set := &quotaSet{cfg: cfg, quotas: make(map[string]*Quota, len(cfg.Quotas))}
+	for i := range set.cfg.Quotas {
+		set.quotas[set.cfg.Quotas[i].Name] = &set.cfg.Quotas[i]
+	}
+	return set, nil
+}
+
+// New creates a Limiter, validating cfg.
+func New(cfg Config, backend Backend, log *logrus.Entry) (*Limiter, error) {
+	set, err := newQuotaSet(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Limiter{
+		backend: backend,
+		log:     log,
+		now:     time.Now,
+		set:     set,
+	}, nil
+}
+
+// Update replaces the quotas and rules. Usage already counted is kept for
+// quotas that keep their names. An invalid cfg leaves the limiter as it
+// was.
+func (l *Limiter) Update(cfg Config) error {
+	set, err := newQuotaSet(cfg)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	l.set = set
+	l.mu.Unlock()
+	return nil
+}
+
+// Check charges every quota the request's method is subject to. It returns
+// a ResourceExhausted status carrying RetryInfo and QuotaFailure details
+// when a quota is used up. Backend errors fail open, as Mixer did.
+func (l *Limiter) Check(ctx context.Context, attrs *Attributes) error {
+	ctx, span := trace.StartSpan(ctx, "quota.Check")
+	defer span.End()
+	l.mu.RLock()
+	set := l.set
+	l.mu.RUnlock()
+	values := attrs.dimensions(&set.cfg)
+	key := dimensionKey(values)
+	span.AddAttributes(
+		trace.StringAttribute("method", attrs.Method),
+		trace.StringAttribute("dimensions", key),
+	)
+	now := l.now()
+	for _, rule := range set.cfg.Rules {
+		if !rule.matches(attrs.Method) {
+			continue
+		}
+		for _, ch := range rule.Quotas {
+			q := set.quotas[ch.Quota]
+			limit := q.match(values)
+			res, err := l.backend.Alloc(q.Name+"|"+key, ch.Charge, limit, now)
+			if err != nil {
+				l.log.WithError(err).WithField("quota", q.Name).Warn("unable to check quota")
+				span.Annotate([]trace.Attribute{trace.StringAttribute("quota", q.Name)}, "unable to check quota; allowed")
+				continue
+			}
+			if !res.Allowed {
+				l.log.WithFields(logrus.Fields{
+					"quota":      q.Name,
+					"method":     attrs.Method,
+					"dimensions": key,
+				}).Debug("quota exhausted")
+				var tenant string
+				if attrs.Identity != nil {
+					tenant = attrs.Identity.Tenant()
+				}
+				metrics.RecordQuotaRejection(ctx, tenant, attrs.Method)
+				err := exhausted(q.Name, key, res.RetryAfter)
+				span.SetStatus(trace.Status{Code: trace.StatusCodeResourceExhausted, Message: status.Convert(err).Message()})
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// dimensionKey renders the dimension values in a stable order.
+func dimensionKey(values map[string]string) string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + "=" + values[name]
+	}
+	return strings.Join(parts, ",")
+}
+
+func exhausted(quota, key string, retry time.Duration) error {
+	st := status.New(codes.ResourceExhausted, "quota "+quota+" exhausted")
+	if d, err := st.WithDetails(
+		&errdetails.RetryInfo{RetryDelay: ptypes.DurationProto(retry)},
+		&errdetails.QuotaFailure{Violations: []*errdetails.QuotaFailure_Violation{{
+			Subject:     key,
+			Description: "quota " + quota + " exhausted",
+		}}},
+	); err == nil {
+		st = d
+	}
+	return st.Err()
+}
+
+// RetryAfter returns the retry delay of an error from Check.
+func RetryAfter(err error) (time.Duration, bool) {
+	for _, d := range status.Convert(err).Details() {
+		if info, ok := d.(*errdetails.RetryInfo); ok {
+			if retry, err := ptypes.Duration(info.RetryDelay); err == nil {
+				return retry, true
+			}
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,108 @@
+package quota
+
+import (
+	"sync"
+	"time"
+)
+
+// Memory keeps usage in process, like memquota. Counters are not shared
+// between replicas.
+type Memory struct {
+	mu        sync.Mutex
+	windows   map[string]*window
+	lastSweep time.Time
+}
+
+// window holds the counts of one key. A fixed window has a single bucket.
+type window struct {
+	buckets map[int64]int64
+	expires time.Time
+}
+
+// NewMemory creates an empty in-process backend.
+func NewMemory() *Memory {
+	return &Memory{windows: map[string]*window{}}
+}
+
+// sweepInterval is how often expired windows are dropped.
+const sweepInterval = time.Minute
+
+// Alloc implements Backend.
+func (m *Memory) Alloc(key string, amount int64, limit Limit, now time.Time) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if now.Sub(m.lastSweep) > sweepInterval {
+		for k, w := range m.windows {
+			if !now.Before(w.expires) {
+				delete(m.windows, k)
+			}
+		}
+		m.lastSweep = now
+	}
+
+	w := m.windows[key]
+	if w == nil {
+		w = &window{buckets: map[int64]int64{}}
+		m.windows[key] = w
+	}
+	var res Result
+	if limit.Algorithm == RollingWindow {
+		res = w.allocRolling(amount, limit, now)
+	} else {
+		res = w.allocFixed(amount, limit, now)
+	}
+	return res, nil
+}
+
+func (w *window) allocFixed(amount int64, limit Limit, now time.Time) Result {
+	start := now.Truncate(limit.Window)
+	idx := start.UnixNano()
+	for b := range w.buckets {
+		if b != idx {
+			delete(w.buckets, b)
+		}
+	}
+	end := start.Add(limit.Window)
+	used := w.buckets[idx]
+	if used+amount > limit.Max {
+		return Result{Remaining: limit.Max - used, RetryAfter: end.Sub(now)}
+	}
+	w.buckets[idx] = used + amount
+	w.expires = end
+	return Result{Allowed: true, Remaining: limit.Max - used - amount}
+}
+
+func (w *window) allocRolling(amount int64, limit Limit, now time.Time) Result {
+	cur := now.UnixNano() / int64(limit.Bucket)
+	n := limit.buckets()
+	var used int64
+	for b, count := range w.buckets {
+		if b <= cur-n {
+			delete(w.buckets, b)
+			continue
+		}
+		used += count
+	}
+	if used+amount > limit.Max {
+		return Result{
+			Remaining:  limit.Max - used,
+			RetryAfter: rollingRetry(w.buckets, used+amount-limit.Max, cur, limit, now),
+		}
+	}
+	w.buckets[cur] += amount
+	w.expires = time.Unix(0, (cur+n)*int64(limit.Bucket))
+	return Result{Allowed: true, Remaining: limit.Max - used - amount}
+}
+
+// rollingRetry works out when enough of the oldest buckets will have left
+// the window to free excess.
+func rollingRetry(buckets map[int64]int64, excess, cur int64, limit Limit, now time.Time) time.Duration {
+	n := limit.buckets()
+	for b := cur - n + 1; b <= cur; b++ {
+		excess -= buckets[b]
+		if excess <= 0 {
+			return time.Unix(0, (b+n)*int64(limit.Bucket)).Sub(now)
+		}
+	}
+	return limit.Window
+}
@@ -0,0 +1,95 @@
+package quota
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMemoryAlloc(t *testing.T) {
+	start := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	type alloc struct {
+		// at is when, since start, the amount is allocated.
+		at         time.Duration
+		amount     int64
+		allowed    bool
+		remaining  int64
+		retryAfter time.Duration
+	}
+	for _, tc := range []struct {
+		name   string
+		limit  Limit
+		allocs []alloc
+	}{
+		{
+			name:  "fixed window",
+			limit: Limit{Max: 3, Window: time.Minute, Algorithm: FixedWindow},
+			allocs: []alloc{
+				{at: 0, amount: 1, allowed: true, remaining: 2},
+				{at: 10 * time.Second, amount: 2, allowed: true, remaining: 0},
+				{at: 20 * time.Second, amount: 1, remaining: 0, retryAfter: 40 * time.Second},
+				// The next window starts from nothing.
+				{at: time.Minute, amount: 1, allowed: true, remaining: 2},
+			},
+		},
+		{
+			name:  "fixed window, too large",
+			limit: Limit{Max: 3, Window: time.Minute, Algorithm: FixedWindow},
+			allocs: []alloc{
+				{at: 30 * time.Second, amount: 4, remaining: 3, retryAfter: 30 * time.Second},
+				{at: 40 * time.Second, amount: 3, allowed: true, remaining: 0},
+			},
+		},
+		{
+			name:  "rolling window",
+			limit: Limit{Max: 3, Window: time.Minute, Bucket: 10 * time.Second, Algorithm: RollingWindow},
+			allocs: []alloc{
+				{at: 0, amount: 2, allowed: true, remaining: 1},
+				{at: 30 * time.Second, amount: 1, allowed: true, remaining: 0},
+				// Free once the first bucket leaves the window.
+				{at: 40 * time.Second, amount: 1, remaining: 0, retryAfter: 20 * time.Second},
+				// The bucket at 30s still counts.
+				{at: time.Minute, amount: 1, allowed: true, remaining: 1},
+				{at: 65 * time.Second, amount: 2, remaining: 1, retryAfter: 25 * time.Second},
+				{at: 90 * time.Second, amount: 2, allowed: true, remaining: 0},
+			},
+		},
+		{
+			name:  "rolling window, too large",
+			limit: Limit{Max: 3, Window: time.Minute, Bucket: 10 * time.Second, Algorithm: RollingWindow},
+			allocs: []alloc{
+				{at: 0, amount: 4, remaining: 3, retryAfter: time.Minute},
+				{at: 5 * time.Second, amount: 3, allowed: true, remaining: 0},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m := NewMemory()
+			for i, a := range tc.allocs {
+				res, err := m.Alloc("math", a.amount, tc.limit, start.Add(a.at))
+				if err != nil {
+					t.Fatal(err)
+				}
+				want := Result{Allowed: a.allowed, Remaining: a.remaining, RetryAfter: a.retryAfter}
+				if res != want {
+					t.Errorf("alloc %d of %d at %s: got %+v, want %+v", i, a.amount, a.at, res, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryKeys(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	limit := Limit{Max: 1, Window: time.Minute, Algorithm: FixedWindow}
+	m := NewMemory()
+	for _, key := range []string{"acme", "globex"} {
+		res, err := m.Alloc(key, 1, limit, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !res.Allowed {
+			t.Errorf("%s was denied by another key's usage", key)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package quota
+
+import (
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/pkg/errors"
+)
+
+// Scripter is the part of a Redis client the Redis backend uses; both
+// *redis.Client and the *redis.Ring from zenkit.NewRedisRingId have it.
+type Scripter interface {
+	Eval(script string, keys []string, args ...interface{}) *redis.Cmd
+	EvalSha(sha1 string, keys []string, args ...interface{}) *redis.Cmd
+	ScriptExists(hashes ...string) *redis.BoolSliceCmd
+	ScriptLoad(script string) *redis.StringCmd
+}
+
+// Redis keeps usage in Redis, like redisquota, so every replica shares the
+// same counters. Each allocation is a single script run on the shard that
+// owns the key.
+type Redis struct {
+	client Scripter
+	prefix string
+}
+
+// NewRedis creates a Redis backend whose keys start with prefix.
+func NewRedis(client Scripter, prefix string) *Redis {
+	return &Redis{client: client, prefix: prefix}
+}
+
+// KEYS[1] is the counter of the current window.
+// ARGV: amount, max, milliseconds until the window ends.
+// Returns {allowed, remaining, retry after in ms}.
+var fixedWindowScript = redis.NewScript(`
+local amount, max = tonumber(ARGV[1]), tonumber(ARGV[2])
+local used = tonumber(redis.call("GET", KEYS[1]) or "0")
+if used + amount > max then
+  local ttl = redis.call("PTTL", KEYS[1])
+  if ttl < 0 then ttl = tonumber(ARGV[3]) end
+  return {0, max - used, ttl}
+end
+used = redis.call("INCRBY", KEYS[1], amount)
+if used == amount then
+  redis.call("PEXPIRE", KEYS[1], ARGV[3])
+end
+return {1, max - used, 0}
+`)
+
+// KEYS[1] is a hash of bucket index to count.
+// ARGV: amount, max, current bucket, buckets per window, bucket ms,
+// ms into the current bucket.
+// Returns {allowed, remaining, retry after in ms}.
+var rollingWindowScript = redis.NewScript(`
+local amount, max = tonumber(ARGV[1]), tonumber(ARGV[2])
+local cur, n, bucket = tonumber(ARGV[3]), tonumber(ARGV[4]), tonumber(ARGV[5])
+local counts = redis.call("HGETALL", KEYS[1])
+local used, live = 0, {}
+for i = 1, #counts, 2 do
+  local b, c = tonumber(counts[i]), tonumber(counts[i + 1])
+  if b <= cur - n then
+    redis.call("HDEL", KEYS[1], counts[i])
+  else
+    used = used + c
+    live[b] = c
+  end
+end
+if used + amount > max then
+  local excess = used + amount - max
+  for b = cur - n + 1, cur do
+    excess = excess - (live[b] or 0)
+    if excess <= 0 then
+      return {0, max - used, (b + n - cur) * bucket - tonumber(ARGV[6])}
+    end
+  end
+  return {0, max - used, n * bucket}
+end
+redis.call("HINCRBY", KEYS[1], ARGV[3], amount)
+redis.call("PEXPIRE", KEYS[1], n * bucket)
+return {1, max - used - amount, 0}
+`)
+
+// Alloc implements Backend.
+func (r *Redis) Alloc(key string, amount int64, limit Limit, now time.Time) (Result, error) {
+	var (
+		vals interface{}
+		err  error
+	)
+	key = r.prefix + key
+	if limit.Algorithm == RollingWindow {
+		bucket := int64(limit.Bucket)
+		cur := now.UnixNano() / bucket
+		vals, err = rollingWindowScript.Run(r.client, []string{key},
+			amount, limit.Max, cur, limit.buckets(), millis(limit.Bucket),
+			millis(time.Duration(now.UnixNano()-cur*bucket))).Result()
+	} else {
+		start := now.Truncate(limit.Window)
+		key += ":" + start.UTC().Format("20060102T150405.000")
+		vals, err = fixedWindowScript.Run(r.client, []string{key},
+			amount, limit.Max, millis(start.Add(limit.Window).Sub(now))).Result()
+	}
+	if err != nil {
+		return Result{}, errors.Wrap(err, "unable to allocate quota in redis")
+	}
+	res, ok := vals.([]interface{})
+	if !ok || len(res) != 3 {
+		return Result{}, errors.Errorf("unexpected quota script result %v", vals)
+	}
+	allowed, _ := res[0].(int64)
+	remaining, _ := res[1].(int64)
+	retry, _ := res[2].(int64)
+	return Result{
+		Allowed:    allowed == 1,
+		Remaining:  remaining,
+		RetryAfter: time.Duration(retry) * time.Millisecond,
+	}, nil
+}
+
+// millis rounds d up to whole milliseconds, so keys never expire early.
+func millis(d time.Duration) int64 {
+	return int64((d + time.Millisecond - 1) / time.Millisecond)
+}
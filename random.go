@@ -0,0 +1,212 @@
+package main
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/pkg/errors"
+	pb "github.com/zenoss/grpctest/pb"
+)
+
+const (
+	// randomSeedHeader carries the seed of a RandomStream.
+	randomSeedHeader = "random-seed"
+	// maxRandomCount bounds the values one Random call returns.
+	maxRandomCount = 10000
+	// maxDraws is how often a value outside the bounds is drawn again
+	// before it is clamped instead.
+	maxDraws = 100
+)
+
+// seeds hands out the seeds of unseeded requests, so every response can
+// say how to reproduce it.
+var seeds = struct {
+	sync.Mutex
+	*rand.Rand
+}{Rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+
+func newSeed() int64 {
+	seeds.Lock()
+	defer seeds.Unlock()
+	return seeds.Int63()
+}
+
+// cryptoSource is a rand.Source reading from crypto/rand.
+type cryptoSource struct{}
+
+func (cryptoSource) Int63() int64 {
+	return int64(cryptoSource{}.Uint64() >> 1)
+}
+
+func (cryptoSource) Uint64() uint64 {
+	var b [8]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		// The system's randomness is gone; nothing sensible can continue.
+		panic(errors.Wrap(err, "unable to read random bytes"))
+	}
+	return binary.BigEndian.Uint64(b[:])
+}
+
+func (cryptoSource) Seed(int64) {}
+
+// parseParity reads the random.parity setting: any, even or odd.
+func parseParity(s string) (pb.RandomRequest_Parity, error) {
+	if s == "" {
+		return pb.RandomRequest_ANY, nil
+	}
+	p, ok := pb.RandomRequest_Parity_value[strings.ToUpper(s)]
+	if !ok {
+		return pb.RandomRequest_ANY, errors.Errorf("unknown parity %q", s)
+	}
+	return pb.RandomRequest_Parity(p), nil
+}
+
+// configuredParity is the active profile's parity or, if it has none, the
+// random.parity setting; loadConfig checked both.
+func configuredParity() pb.RandomRequest_Parity {
+	s := activeProfile().Parity
+	if s == "" {
+		s = currentConfig().Random.Parity
+	}
+	p, _ := parseParity(s)
+	return p
+}
+
+// randomizer draws the values a RandomRequest describes.
+type randomizer struct {
+	rng          *rand.Rand
+	min, max     int64
+	distribution pb.RandomRequest_Distribution
+	mean, stddev float64
+	parity       pb.RandomRequest_Parity
+}
+
+// newRandomizer checks the request and sets up its source. parity applies
+// when the request doesn't ask for one. The returned seed is nil for the
+// crypto source.
+func newRandomizer(in *pb.RandomRequest, parity pb.RandomRequest_Parity) (*randomizer, *wrappers.Int64Value, error) {
+	r := &randomizer{
+		min:          0,
+		max:          math.MaxInt32,
+		distribution: in.GetDistribution(),
+		parity:       parity,
+	}
+	if in.GetMin() != nil {
+		r.min = int64(in.GetMin().GetValue())
+	}
+	if in.GetMax() != nil {
+		r.max = int64(in.GetMax().GetValue())
+	}
+	if r.max < r.min {
+		return nil, nil, invalidArgument("max", "max %d is below min %d", r.max, r.min)
+	}
+	if in.GetParity() != pb.RandomRequest_ANY {
+		r.parity = in.GetParity()
+	}
+	if _, ok := pb.RandomRequest_Parity_name[int32(r.parity)]; !ok {
+		return nil, nil, invalidArgument("parity", "unknown parity %d", r.parity)
+	}
+	if r.parity != pb.RandomRequest_ANY && r.min == r.max && !r.matches(r.min) {
+		return nil, nil, invalidArgument("parity", "no %s value between %d and %d", strings.ToLower(r.parity.String()), r.min, r.max)
+	}
+
+	width := float64(r.max - r.min)
+	r.mean = float64(r.min) + width/2
+	if in.GetMean() != nil {
+		r.mean = in.GetMean().GetValue()
+	}
+	r.stddev = width / 6
+	if in.GetStddev() != nil {
+		r.stddev = in.GetStddev().GetValue()
+	}
+	switch r.distribution {
+	case pb.RandomRequest_UNIFORM:
+	case pb.RandomRequest_NORMAL:
+		if math.IsNaN(r.mean) || math.IsInf(r.mean, 0) {
+			return nil, nil, invalidArgument("mean", "mean must be a finite number")
+		}
+		if !(r.stddev >= 0) || math.IsInf(r.stddev, 0) {
+			return nil, nil, invalidArgument("stddev", "stddev must be a finite number of at least 0")
+		}
+	case pb.RandomRequest_EXPONENTIAL:
+		if !(r.mean > float64(r.min)) || math.IsInf(r.mean, 0) {
+			return nil, nil, invalidArgument("mean", "mean must be a finite number above min")
+		}
+	default:
+		return nil, nil, invalidArgument("distribution", "unknown distribution %d", r.distribution)
+	}
+
+	var seed *wrappers.Int64Value
+	switch in.GetSource() {
+	case pb.RandomRequest_MATH:
+		seed = in.GetSeed()
+		if seed == nil {
+			seed = &wrappers.Int64Value{Value: newSeed()}
+		}
+		r.rng = rand.New(rand.NewSource(seed.GetValue()))
+	case pb.RandomRequest_CRYPTO:
+		if in.GetSeed() != nil {
+			return nil, nil, invalidArgument("seed", "the CRYPTO source can't be seeded")
+		}
+		r.rng = rand.New(cryptoSource{})
+	default:
+		return nil, nil, invalidArgument("source", "unknown source %d", in.GetSource())
+	}
+	return r, seed, nil
+}
+
+func (r *randomizer) matches(v int64) bool {
+	switch r.parity {
+	case pb.RandomRequest_EVEN:
+		return v%2 == 0
+	case pb.RandomRequest_ODD:
+		return v%2 != 0
+	}
+	return true
+}
+
+// next draws one value.
+func (r *randomizer) next() int32 {
+	if r.distribution == pb.RandomRequest_UNIFORM {
+		// Pick among the values of the right parity, so they all stay
+		// equally likely.
+		if r.parity == pb.RandomRequest_ANY {
+			return int32(r.min + r.rng.Int63n(r.max-r.min+1))
+		}
+		first := r.min
+		if !r.matches(first) {
+			first++
+		}
+		return int32(first + 2*r.rng.Int63n((r.max-first)/2+1))
+	}
+
+	var f float64
+	for i := 0; i < maxDraws; i++ {
+		if r.distribution == pb.RandomRequest_NORMAL {
+			f = r.rng.NormFloat64()*r.stddev + r.mean
+		} else {
+			f = float64(r.min) + r.rng.ExpFloat64()*(r.mean-float64(r.min))
+		}
+		f = math.Round(f)
+		if f >= float64(r.min) && f <= float64(r.max) {
+			break
+		}
+	}
+	v := int64(math.Max(float64(r.min), math.Min(float64(r.max), f)))
+	if !r.matches(v) {
+		// Step to the neighbour of the right parity that is in range;
+		// newRandomizer made sure there is one.
+		if v+1 <= r.max {
+			v++
+		} else {
+			v--
+		}
+	}
+	return int32(v)
+}
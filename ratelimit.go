@@ -0,0 +1,115 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/zenoss/grpctest/auth"
+	"github.com/zenoss/grpctest/quota"
+	"github.com/zenoss/grpctest/ratelimit"
+	"github.com/zenoss/zenkit"
+)
+
+// newLimiter builds the quota limiter from the quota settings, which take
+// the shape of the Mixer configs in yaml/rate_redis.yaml. Everything but the
+// backend is reloaded when the config file changes, e.g.
+//
+//	quota:
+//	  enabled: true
+//	  backend: redis
+//	  dimensions:
+//	    destination: destination.labels["app"] | "unknown"
+//	    tenant: request.auth.claims["https://dev.zing.ninja/tenant"] | "unknown"
+//	  quotas:
+//	  - name: requestcount
+//	    maxAmount: 1
+//	    validDuration: 1s
+//	    bucketDuration: 500ms
+//	    rateLimitAlgorithm: ROLLING_WINDOW
+//	    overrides:
+//	    - dimensions:
+//	        tenant: qa-long
+//	      maxAmount: 10
+//	  rules:
+//	  - quotas:
+//	    - quota: requestcount
+//	      charge: 1
+func newLimiter(settings QuotaSettings, log *logrus.Entry) (*quota.Limiter, error) {
+	backend, err := newQuotaBackend(QuotaBackendConfig, settings.Backend, settings.Redis.DBID, "quota")
+	if err != nil {
+		return nil, err
+	}
+	return quota.New(settings.Config, backend, log.WithField("component", "quota"))
+}
+
+// newRateLimitServer builds Envoy's rate limit service from the ratelimit
+// settings, whose domains take the shape of a Lyft ratelimit config. As with
+// the quotas, everything but the backend is reloaded, e.g.
+//
+//	ratelimit:
+//	  enabled: true
+//	  domains:
+//	  - domain: grpctest
+//	    descriptors:
+//	    - key: tenant
+//	      rate_limit:
+//	        unit: second
+//	        requests_per_unit: 10
+//	      descriptors:
+//	      - key: user
+//	        value: rphillips@zenoss.com
+//	        rate_limit:
+//	          unit: second
+//	          requests_per_unit: 100
+//	          algorithm: ROLLING_WINDOW
+func newRateLimitServer(settings RateLimitSettings, log *logrus.Entry) (*ratelimit.Server, error) {
+	backend, err := newQuotaBackend(RateLimitBackendConfig, settings.Backend, settings.Redis.DBID, "ratelimit")
+	if err != nil {
+		return nil, err
+	}
+	return ratelimit.New(settings.Config, backend, log.WithField("component", "ratelimit"))
+}
+
+// newQuotaBackend creates the backend of the backendKey setting, memory or
+// redis. Redis keys are prefixed so the quota and rate limit counters never
+// collide.
+func newQuotaBackend(backendKey, backend string, dbid int, prefix string) (quota.Backend, error) {
+	switch backend {
+	case "memory":
+		return quota.NewMemory(), nil
+	case "redis":
+		ring := zenkit.NewRedisRingId(dbid)
+		if ring == nil {
+			return nil, errors.Errorf("the redis backend of %s needs %s", backendKey, zenkit.GCMemstoreAddressConfig)
+		}
+		return quota.NewRedis(ring, serviceName+":"+prefix+":"), nil
+	default:
+		return nil, errors.Errorf("unknown %s %q", backendKey, backend)
+	}
+}
+
+// identifyRequest is the identity for the HTTP quota dimensions; requests
+// without a valid token count as anonymous.
+func identifyRequest(verifier *auth.Verifier) func(*http.Request) zenkit.TenantIdentity {
+	return func(r *http.Request) zenkit.TenantIdentity {
+		ident, err := requestIdentity(r, verifier)
+		if err != nil {
+			return nil
+		}
+		return ident
+	}
+}
+
+// requestIdentity verifies the request's bearer token, or gives the dev
+// identity when auth is disabled.
+func requestIdentity(r *http.Request, verifier *auth.Verifier) (zenkit.TenantIdentity, error) {
+	if currentConfig().Auth.Disabled {
+		ctx, err := zenkit.DevIdentity(r.Context())
+		if err != nil {
+			return nil, err
+		}
+		return zenkit.ContextTenantIdentity(ctx), nil
+	}
+	return verifier.FromRequest(r)
+}
@@ -0,0 +1,180 @@
+// Package ratelimit serves Envoy's global rate limit service
+// (envoy.service.ratelimit.v2.RateLimitService) on top of the quota
+// package's storage, with descriptors configured the way Lyft's ratelimit
+// service configures them.
+package ratelimit
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	pb "github.com/zenoss/grpctest/pb/envoy/ratelimit"
+	"github.com/zenoss/grpctest/quota"
+)
+
+// RateLimit is the limit of a descriptor.
+type RateLimit struct {
+	// Unit is second, minute, hour or day.
+	Unit            string `mapstructure:"unit"`
+	RequestsPerUnit uint32 `mapstructure:"requests_per_unit"`
+	// Algorithm is FIXED_WINDOW, the default, or ROLLING_WINDOW.
+	Algorithm quota.Algorithm `mapstructure:"algorithm"`
+	// BucketDuration is the bucket size of a rolling window; it defaults
+	// to a tenth of the unit.
+	BucketDuration time.Duration `mapstructure:"bucket_duration"`
+}
+
+// Descriptor matches one entry of a request descriptor. An empty Value
+// matches any value of Key, but a descriptor with the exact value wins.
+type Descriptor struct {
+	Key         string       `mapstructure:"key"`
+	Value       string       `mapstructure:"value"`
+	RateLimit   *RateLimit   `mapstructure:"rate_limit"`
+	Descriptors []Descriptor `mapstructure:"descriptors"`
+	// ShadowMode reports this limit without enforcing it.
+	ShadowMode bool `mapstructure:"shadow_mode"`
+}
+
+// Domain holds the descriptors of one rate limit domain.
+type Domain struct {
+	Domain      string       `mapstructure:"domain"`
+	Descriptors []Descriptor `mapstructure:"descriptors"`
+}
+
+// Config is the set of domains the service knows.
+type Config struct {
+	Domains []Domain `mapstructure:"domains"`
+	// ShadowMode reports every limit without enforcing any.
+	ShadowMode bool `mapstructure:"shadow_mode"`
+}
+
+// Validate checks that every domain and descriptor is usable.
+func (c Config) Validate() error {
+	_, err := c.compile()
+	return err
+}
+
+func (c Config) compile() (*limits, error) {
+	l := &limits{
+		domains: make(map[string]map[string]*node, len(c.Domains)),
+		shadow:  c.ShadowMode,
+	}
+	for _, d := range c.Domains {
+		if d.Domain == "" {
+			return nil, errors.New("rate limit domain has no name")
+		}
+		if _, ok := l.domains[d.Domain]; ok {
+			return nil, errors.Errorf("rate limit domain %s is defined twice", d.Domain)
+		}
+		nodes, err := compile(d.Descriptors, d.Domain+".")
+		if err != nil {
+			return nil, err
+		}
+		l.domains[d.Domain] = nodes
+	}
+	return l, nil
+}
+
+var units = map[string]pb.RateLimitResponse_RateLimit_Unit{
+	"second": pb.RateLimitResponse_RateLimit_SECOND,
+	"minute": pb.RateLimitResponse_RateLimit_MINUTE,
+	"hour":   pb.RateLimitResponse_RateLimit_HOUR,
+	"day":    pb.RateLimitResponse_RateLimit_DAY,
+}
+
+func unitDuration(unit pb.RateLimitResponse_RateLimit_Unit) time.Duration {
+	switch unit {
+	case pb.RateLimitResponse_RateLimit_SECOND:
+		return time.Second
+	case pb.RateLimitResponse_RateLimit_MINUTE:
+		return time.Minute
+	case pb.RateLimitResponse_RateLimit_HOUR:
+		return time.Hour
+	case pb.RateLimitResponse_RateLimit_DAY:
+		return 24 * time.Hour
+	}
+	return 0
+}
+
+// node is a compiled Descriptor, with its children indexed by "key" and
+// "key_value".
+type node struct {
+	limit    *limit
+	children map[string]*node
+}
+
+// limit is a compiled RateLimit.
+type limit struct {
+	unit   pb.RateLimitResponse_RateLimit_Unit
+	per    uint32
+	quota  quota.Limit
+	shadow bool
+}
+
+func (l *limit) proto() *pb.RateLimitResponse_RateLimit {
+	return &pb.RateLimitResponse_RateLimit{RequestsPerUnit: l.per, Unit: l.unit}
+}
+
+func newLimit(unit pb.RateLimitResponse_RateLimit_Unit, per uint32, algorithm quota.Algorithm, bucket time.Duration) (*limit, error) {
+	window := unitDuration(unit)
+	if window == 0 {
+		return nil, errors.Errorf("unknown unit %s", unit)
+	}
+	if algorithm == "" {
+		algorithm = quota.FixedWindow
+	}
+	if algorithm == quota.RollingWindow && bucket == 0 {
+		bucket = window / 10
+	}
+	l := &limit{
+		unit: unit,
+		per:  per,
+		quota: quota.Limit{
+			Max:       int64(per),
+			Window:    window,
+			Bucket:    bucket,
+			Algorithm: algorithm,
+		},
+	}
+	if err := l.quota.Validate(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func compile(descriptors []Descriptor, path string) (map[string]*node, error) {
+	nodes := make(map[string]*node, len(descriptors))
+	for _, d := range descriptors {
+		if d.Key == "" {
+			return nil, errors.Errorf("descriptor under %q has no key", path)
+		}
+		id := d.Key
+		if d.Value != "" {
+			id += "_" + d.Value
+		}
+		if _, ok := nodes[id]; ok {
+			return nil, errors.Errorf("descriptor %s%s is defined twice", path, id)
+		}
+		n := &node{}
+		if rl := d.RateLimit; rl != nil {
+			unit, ok := units[strings.ToLower(rl.Unit)]
+			if !ok {
+				return nil, errors.Errorf("descriptor %s%s has unknown unit %q", path, id, rl.Unit)
+			}
+			l, err := newLimit(unit, rl.RequestsPerUnit, rl.Algorithm, rl.BucketDuration)
+			if err != nil {
+				return nil, errors.Wrapf(err, "descriptor %s%s", path, id)
+			}
+			l.shadow = d.ShadowMode
+			n.limit = l
+		}
+		children, err := compile(d.Descriptors, path+id+".")
+		if err != nil {
+			return nil, err
+		}
+		n.children = children
+		nodes[id] = n
+	}
+	return nodes, nil
+}
@@ -0,0 +1,189 @@
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/zenoss/grpctest/auth"
+	pb "github.com/zenoss/grpctest/pb/envoy/ratelimit"
+	"github.com/zenoss/grpctest/quota"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RetryAfterHeader is added to over limit responses, with the seconds
+// until the first exceeded limit has room again.
+const RetryAfterHeader = "retry-after"
+
+// Server implements the RateLimitService.
+type Server struct {
+	backend quota.Backend
+	log     *logrus.Entry
+	now     func() time.Time
+
+	mu     sync.RWMutex
+	limits *limits
+}
+
+// limits is a compiled Config.
+type limits struct {
+	domains map[string]map[string]*node
+	shadow  bool
+}
+
+// New creates a RateLimitService keeping its counters in backend.
+func New(cfg Config, backend quota.Backend, log *logrus.Entry) (*Server, error) {
+	l, err := cfg.compile()
+	if err != nil {
+		return nil, err
+	}
+	return &Server{
+		backend: backend,
+		log:     log,
+		now:     time.Now,
+		limits:  l,
+	}, nil
+}
+
+// Update replaces the domains and shadow mode. Counters of descriptors
+// that keep their limits carry on. An invalid cfg leaves the service as it
+// was.
+func (s *Server) Update(cfg Config) error {
+	l, err := cfg.compile()
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.limits = l
+	s.mu.Unlock()
+	return nil
+}
+
+// AuthFuncOverride lets Envoy call the service without credentials of its
+// own.
+func (s *Server) AuthFuncOverride(ctx context.Context, fullMethodName string) (context.Context, error) {
+	return auth.Anonymous(ctx)
+}
+
+// ShouldRateLimit implements pb.RateLimitServiceServer. Each descriptor is
+// charged hits_addend against the limit of the configured descriptor its
+// entries lead to, or against the limit it carries itself; descriptors
+// with no limit are always OK.
+func (s *Server) ShouldRateLimit(ctx context.Context, req *pb.RateLimitRequest) (*pb.RateLimitResponse, error) {
+	if req.GetDomain() == "" {
+		return nil, status.Error(codes.InvalidArgument, "rate limit domain must not be empty")
+	}
+	if len(req.GetDescriptors()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "rate limit descriptor list must not be empty")
+	}
+	hits := int64(req.GetHitsAddend())
+	if hits == 0 {
+		hits = 1
+	}
+	s.mu.RLock()
+	limits := s.limits
+	s.mu.RUnlock()
+	now := s.now()
+	resp := &pb.RateLimitResponse{OverallCode: pb.RateLimitResponse_OK}
+	var retry time.Duration
+	for _, d := range req.GetDescriptors() {
+		key := descriptorKey(req.GetDomain(), d)
+		log := s.log.WithFields(logrus.Fields{
+			"domain":     req.GetDomain(),
+			"descriptor": key,
+		})
+		l := limits.limit(req.GetDomain(), d, log)
+		if l == nil {
+			resp.Statuses = append(resp.Statuses, &pb.RateLimitResponse_DescriptorStatus{Code: pb.RateLimitResponse_OK})
+			continue
+		}
+		res, err := s.backend.Alloc(key+"|"+l.unit.String(), hits, l.quota, now)
+		if err != nil {
+			// Fail open, as Envoy does when the service is unreachable.
+			log.WithError(err).Warn("unable to check rate limit")
+			res = quota.Result{Allowed: true, Remaining: l.quota.Max}
+		}
+		st := &pb.RateLimitResponse_DescriptorStatus{
+			Code:         pb.RateLimitResponse_OK,
+			CurrentLimit: l.proto(),
+		}
+		if res.Remaining > 0 {
+			st.LimitRemaining = uint32(res.Remaining)
+		}
+		if !res.Allowed {
+			st.Code = pb.RateLimitResponse_OVER_LIMIT
+			if l.shadow || limits.shadow {
+				log.Info("over rate limit in shadow mode")
+			} else {
+				log.Debug("over rate limit")
+				resp.OverallCode = pb.RateLimitResponse_OVER_LIMIT
+				if retry == 0 || res.RetryAfter < retry {
+					retry = res.RetryAfter
+				}
+			}
+		}
+		resp.Statuses = append(resp.Statuses, st)
+	}
+	if resp.OverallCode == pb.RateLimitResponse_OVER_LIMIT {
+		// Retry-After is in whole seconds, so round up.
+		secs := int64((retry + time.Second - 1) / time.Second)
+		resp.Headers = append(resp.Headers, &pb.HeaderValue{Key: RetryAfterHeader, Value: strconv.FormatInt(secs, 10)})
+	}
+	return resp, nil
+}
+
+// limit finds the limit for a descriptor: its own override if it has one,
+// otherwise that of the configured descriptor all its entries match,
+// trying key_value before key at each level.
+func (ls *limits) limit(domain string, d *pb.RateLimitDescriptor, log *logrus.Entry) *limit {
+	var (
+		n     *node
+		nodes = ls.domains[domain]
+	)
+	for _, e := range d.GetEntries() {
+		next := nodes[e.GetKey()+"_"+e.GetValue()]
+		if next == nil {
+			next = nodes[e.GetKey()]
+		}
+		if next == nil {
+			n = nil
+			break
+		}
+		n = next
+		nodes = n.children
+	}
+	var found *limit
+	if n != nil {
+		found = n.limit
+	}
+	if o := d.GetLimit(); o != nil {
+		algorithm := quota.FixedWindow
+		if found != nil {
+			algorithm = found.quota.Algorithm
+		}
+		l, err := newLimit(o.GetUnit(), o.GetRequestsPerUnit(), algorithm, 0)
+		if err != nil {
+			log.WithError(err).Warn("ignoring invalid limit override")
+			return found
+		}
+		if found != nil {
+			l.shadow = found.shadow
+		}
+		return l
+	}
+	return found
+}
+
+// descriptorKey identifies the counter of a descriptor.
+func descriptorKey(domain string, d *pb.RateLimitDescriptor) string {
+	parts := make([]string, 0, len(d.GetEntries())+1)
+	parts = append(parts, domain)
+	for _, e := range d.GetEntries() {
+		parts = append(parts, e.GetKey()+"="+e.GetValue())
+	}
+	return strings.Join(parts, ",")
+}
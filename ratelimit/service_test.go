@@ -0,0 +1,218 @@
+package ratelimit
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	pb "github.com/zenoss/grpctest/pb/envoy/ratelimit"
+	"github.com/zenoss/grpctest/quota"
+)
+
+var testDomains = []Domain{{
+	Domain: "grpctest",
+	Descriptors: []Descriptor{
+		{
+			Key:       "tenant",
+			RateLimit: &RateLimit{Unit: "minute", RequestsPerUnit: 2},
+			Descriptors: []Descriptor{{
+				Key:       "method",
+				Value:     "Square",
+				RateLimit: &RateLimit{Unit: "minute", RequestsPerUnit: 1},
+			}},
+		},
+		{
+			Key:       "tenant",
+			Value:     "ACME",
+			RateLimit: &RateLimit{Unit: "minute", RequestsPerUnit: 5},
+		},
+		{
+			Key:        "remote_address",
+			RateLimit:  &RateLimit{Unit: "minute", RequestsPerUnit: 1},
+			ShadowMode: true,
+		},
+	},
+}}
+
+// descriptor builds a descriptor from key, value pairs.
+func descriptor(override *pb.RateLimitDescriptor_RateLimitOverride, kv ...string) *pb.RateLimitDescriptor {
+	d := &pb.RateLimitDescriptor{Limit: override}
+	for i := 0; i+1 < len(kv); i += 2 {
+		d.Entries = append(d.Entries, &pb.RateLimitDescriptor_Entry{Key: kv[i], Value: kv[i+1]})
+	}
+	return d
+}
+
+func TestShouldRateLimit(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 30, 0, time.UTC)
+	perMinute := func(n uint32) *pb.RateLimitResponse_RateLimit {
+		return &pb.RateLimitResponse_RateLimit{RequestsPerUnit: n, Unit: pb.RateLimitResponse_RateLimit_MINUTE}
+	}
+
+	type descriptorStatus struct {
+		code      pb.RateLimitResponse_Code
+		limit     *pb.RateLimitResponse_RateLimit
+		remaining uint32
+	}
+	for _, tc := range []struct {
+		name        string
+		shadow      bool
+		descriptors []*pb.RateLimitDescriptor
+		// calls is how many times the request is made; the last response
+		// is checked.
+		calls    int
+		overall  pb.RateLimitResponse_Code
+		statuses []descriptorStatus
+		// retryAfter is the Retry-After header, if any.
+		retryAfter string
+	}{
+		{
+			name:        "any value",
+			descriptors: []*pb.RateLimitDescriptor{descriptor(nil, "tenant", "globex")},
+			calls:       3,
+			overall:     pb.RateLimitResponse_OVER_LIMIT,
+			statuses:    []descriptorStatus{{pb.RateLimitResponse_OVER_LIMIT, perMinute(2), 0}},
+			retryAfter:  "30",
+		},
+		{
+			name:        "exact value wins",
+			descriptors: []*pb.RateLimitDescriptor{descriptor(nil, "tenant", "ACME")},
+			calls:       3,
+			overall:     pb.RateLimitResponse_OK,
+			statuses:    []descriptorStatus{{pb.RateLimitResponse_OK, perMinute(5), 2}},
+		},
+		{
+			name:        "nested descriptor",
+			descriptors: []*pb.RateLimitDescriptor{descriptor(nil, "tenant", "globex", "method", "Square")},
+			calls:       2,
+			overall:     pb.RateLimitResponse_OVER_LIMIT,
+			statuses:    []descriptorStatus{{pb.RateLimitResponse_OVER_LIMIT, perMinute(1), 0}},
+			retryAfter:  "30",
+		},
+		{
+			name:        "no nested match",
+			descriptors: []*pb.RateLimitDescriptor{descriptor(nil, "tenant", "globex", "method", "Add")},
+			calls:       3,
+			overall:     pb.RateLimitResponse_OK,
+			statuses:    []descriptorStatus{{code: pb.RateLimitResponse_OK}},
+		},
+		{
+			name:        "unconfigured entry",
+			descriptors: []*pb.RateLimitDescriptor{descriptor(nil, "user", "zcuser")},
+			calls:       3,
+			overall:     pb.RateLimitResponse_OK,
+			statuses:    []descriptorStatus{{code: pb.RateLimitResponse_OK}},
+		},
+		{
+			name: "one of several over",
+			descriptors: []*pb.RateLimitDescriptor{
+				descriptor(nil, "tenant", "ACME"),
+				descriptor(nil, "tenant", "globex", "method", "Square"),
+			},
+			calls:   2,
+			overall: pb.RateLimitResponse_OVER_LIMIT,
+			statuses: []descriptorStatus{
+				{pb.RateLimitResponse_OK, perMinute(5), 3},
+				{pb.RateLimitResponse_OVER_LIMIT, perMinute(1), 0},
+			},
+			retryAfter: "30",
+		},
+		{
+			name: "override",
+			descriptors: []*pb.RateLimitDescriptor{descriptor(
+				&pb.RateLimitDescriptor_RateLimitOverride{RequestsPerUnit: 10, Unit: pb.RateLimitResponse_RateLimit_MINUTE},
+				"tenant", "globex",
+			)},
+			calls:    3,
+			overall:  pb.RateLimitResponse_OK,
+			statuses: []descriptorStatus{{pb.RateLimitResponse_OK, perMinute(10), 7}},
+		},
+		{
+			name: "override of an unconfigured descriptor",
+			descriptors: []*pb.RateLimitDescriptor{descriptor(
+				&pb.RateLimitDescriptor_RateLimitOverride{RequestsPerUnit: 1, Unit: pb.RateLimitResponse_RateLimit_SECOND},
+				"user", "zcuser",
+			)},
+			calls:   2,
+			overall: pb.RateLimitResponse_OVER_LIMIT,
+			statuses: []descriptorStatus{{
+				pb.RateLimitResponse_OVER_LIMIT,
+				&pb.RateLimitResponse_RateLimit{RequestsPerUnit: 1, Unit: pb.RateLimitResponse_RateLimit_SECOND},
+				0,
+			}},
+			retryAfter: "1",
+		},
+		{
+			name:        "shadow descriptor",
+			descriptors: []*pb.RateLimitDescriptor{descriptor(nil, "remote_address", "10.0.0.1")},
+			calls:       2,
+			overall:     pb.RateLimitResponse_OK,
+			statuses:    []descriptorStatus{{pb.RateLimitResponse_OVER_LIMIT, perMinute(1), 0}},
+		},
+		{
+			name: "override keeps shadow mode",
+			descriptors: []*pb.RateLimitDescriptor{descriptor(
+				&pb.RateLimitDescriptor_RateLimitOverride{RequestsPerUnit: 2, Unit: pb.RateLimitResponse_RateLimit_MINUTE},
+				"remote_address", "10.0.0.1",
+			)},
+			calls:    3,
+			overall:  pb.RateLimitResponse_OK,
+			statuses: []descriptorStatus{{pb.RateLimitResponse_OVER_LIMIT, perMinute(2), 0}},
+		},
+		{
+			name:        "shadow service",
+			shadow:      true,
+			descriptors: []*pb.RateLimitDescriptor{descriptor(nil, "tenant", "globex")},
+			calls:       3,
+			overall:     pb.RateLimitResponse_OK,
+			statuses:    []descriptorStatus{{pb.RateLimitResponse_OVER_LIMIT, perMinute(2), 0}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			log := logrus.New()
+			log.Out = ioutil.Discard
+			s, err := New(Config{Domains: testDomains, ShadowMode: tc.shadow}, quota.NewMemory(), logrus.NewEntry(log))
+			if err != nil {
+				t.Fatal(err)
+			}
+			s.now = func() time.Time { return now }
+			req := &pb.RateLimitRequest{Domain: "grpctest", Descriptors: tc.descriptors}
+
+			var resp *pb.RateLimitResponse
+			for i := 0; i < tc.calls; i++ {
+				if resp, err = s.ShouldRateLimit(context.Background(), req); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if resp.OverallCode != tc.overall {
+				t.Errorf("got overall code %s, want %s", resp.OverallCode, tc.overall)
+			}
+			if len(resp.Statuses) != len(tc.statuses) {
+				t.Fatalf("got %d statuses, want %d", len(resp.Statuses), len(tc.statuses))
+			}
+			for i, want := range tc.statuses {
+				got := resp.Statuses[i]
+				if got.Code != want.code {
+					t.Errorf("status %d: got code %s, want %s", i, got.Code, want.code)
+				}
+				if got.CurrentLimit.GetRequestsPerUnit() != want.limit.GetRequestsPerUnit() || got.CurrentLimit.GetUnit() != want.limit.GetUnit() {
+					t.Errorf("status %d: got limit %v, want %v", i, got.CurrentLimit, want.limit)
+				}
+				if got.LimitRemaining != want.remaining {
+					t.Errorf("status %d: got %d remaining, want %d", i, got.LimitRemaining, want.remaining)
+				}
+			}
+			var retryAfter string
+			for _, h := range resp.Headers {
+				if h.Key == RetryAfterHeader {
+					retryAfter = h.Value
+				}
+			}
+			if retryAfter != tc.retryAfter {
+				t.Errorf("got Retry-After %q, want %q", retryAfter, tc.retryAfter)
+			}
+		})
+	}
+}
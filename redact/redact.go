@@ -0,0 +1,178 @@
+// Package redact keeps credentials out of the headers and metadata the
+// debugging endpoints send back, which end up in logs and screenshots.
+package redact
+
+import (
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/metadata"
+)
+
+// Mask replaces what is redacted.
+const Mask = "[redacted]"
+
+// Sensitive are the headers always redacted.
+var Sensitive = []string{
+	"authorization",
+	"proxy-authorization",
+	"cookie",
+	"set-cookie",
+	"z-api-key",
+	"x-api-key",
+}
+
+// Config adds to the Sensitive headers.
+type Config struct {
+	// Headers are more header names to redact, or path.Match patterns of
+	// them such as x-*-token. Case doesn't matter.
+	Headers []string `mapstructure:"headers"`
+	// Values are regular expressions whose matches are redacted from the
+	// values of every header.
+	Values []string `mapstructure:"values"`
+	// Claims shows the claims of the bearer token, decoded without being
+	// verified, in place of the token itself.
+	Claims bool `mapstructure:"claims"`
+}
+
+// Validate checks the patterns.
+func (c Config) Validate() error {
+	_, err := newPolicy(c)
+	return err
+}
+
+// policy is a validated Config.
+type policy struct {
+	headers  []string
+	patterns []*regexp.Regexp
+	claims   bool
+}
+
+func newPolicy(cfg Config) (*policy, error) {
+	p := &policy{claims: cfg.Claims}
+	for _, h := range append(append([]string(nil), Sensitive...), cfg.Headers...) {
+		h = strings.ToLower(h)
+		if _, err := path.Match(h, ""); err != nil {
+			return nil, errors.Wrapf(err, "header pattern %q", h)
+		}
+		p.headers = append(p.headers, h)
+	}
+	for _, v := range cfg.Values {
+		re, err := regexp.Compile(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "value pattern %q", v)
+		}
+		p.patterns = append(p.patterns, re)
+	}
+	return p, nil
+}
+
+// Redactor applies a Config that can be updated while in use.
+type Redactor struct {
+	mu     sync.RWMutex
+	policy *policy
+}
+
+func New(cfg Config) (*Redactor, error) {
+	p, err := newPolicy(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Redactor{policy: p}, nil
+}
+
+// Update replaces the config.
+func (r *Redactor) Update(cfg Config) error {
+	p, err := newPolicy(cfg)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.policy = p
+	r.mu.Unlock()
+	return nil
+}
+
+func (r *Redactor) current() *policy {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.policy
+}
+
+// Claims is whether the bearer token's claims may be shown.
+func (r *Redactor) Claims() bool {
+	return r.current().claims
+}
+
+func (p *policy) sensitive(name string) bool {
+	name = strings.ToLower(name)
+	for _, h := range p.headers {
+		if ok, _ := path.Match(h, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Value is the header's value as it may be shown. The values of sensitive
+// headers are masked, keeping an authorization scheme such as Bearer; in
+// the rest, the matches of the value patterns are.
+func (r *Redactor) Value(name, value string) string {
+	return r.current().value(name, value)
+}
+
+func (p *policy) value(name, value string) string {
+	if p.sensitive(name) {
+		parts := strings.SplitN(strings.TrimSpace(value), " ", 2)
+		if len(parts) == 2 && isScheme(parts[0]) {
+			return parts[0] + " " + Mask
+		}
+		return Mask
+	}
+	for _, re := range p.patterns {
+		value = re.ReplaceAllLiteralString(value, Mask)
+	}
+	return value
+}
+
+// isScheme is whether s looks like an HTTP authentication scheme.
+func isScheme(s string) bool {
+	for _, c := range s {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '-') {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// Header is a redacted copy of h.
+func (r *Redactor) Header(h http.Header) http.Header {
+	p := r.current()
+	out := make(http.Header, len(h))
+	for name, values := range h {
+		out[name] = p.values(name, values)
+	}
+	return out
+}
+
+// Metadata is a redacted copy of md.
+func (r *Redactor) Metadata(md metadata.MD) metadata.MD {
+	p := r.current()
+	out := make(metadata.MD, len(md))
+	for key, values := range md {
+		out[key] = p.values(key, values)
+	}
+	return out
+}
+
+func (p *policy) values(name string, values []string) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = p.value(name, v)
+	}
+	return out
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/zenoss/grpctest/tracing"
+	"go.opencensus.io/trace"
+)
+
+// zipkinTimeout bounds each post of a batch of spans.
+const zipkinTimeout = 10 * time.Second
+
+// newTraceExporter is the exporter tracing.exporter names, nil for none.
+func newTraceExporter(cfg TracingSettings, log *logrus.Entry) (tracing.Exporter, error) {
+	switch cfg.Exporter {
+	case "jsonl":
+		exporter, err := tracing.OpenJSONLines(cfg.JSONL.Path, serviceName)
+		if err != nil {
+			return nil, err
+		}
+		return exporter, nil
+	case "zipkin":
+		client := &http.Client{Timeout: zipkinTimeout}
+		return tracing.NewZipkinExporter(cfg.Zipkin.URL, serviceName, client, log.WithField("component", "tracing")), nil
+	}
+	return nil, nil
+}
+
+// traceSampler samples nothing without an exporter. Otherwise it samples
+// the traces callers sampled, and samplerate of the rest.
+func traceSampler(cfg TracingSettings) trace.Sampler {
+	if cfg.Exporter == "none" {
+		return trace.NeverSample()
+	}
+	return trace.ProbabilitySampler(cfg.SampleRate)
+}
+
+// instrumented is whether calls go through the OpenCensus handlers, which
+// both trace them and record the stats the metrics are made of.
+func instrumented(cfg *Config) bool {
+	return cfg.Prometheus.Enabled || cfg.Tracing.Exporter != "none"
+}
+
+// untraced keeps the requests for paths, the probes and scrapes, out of
+// the traces.
+func untraced(paths ...string) func(*http.Request) trace.StartOptions {
+	return func(r *http.Request) trace.StartOptions {
+		for _, path := range paths {
+			if r.URL.Path == path {
+				return trace.StartOptions{Sampler: trace.NeverSample()}
+			}
+		}
+		return trace.StartOptions{}
+	}
+}
@@ -0,0 +1,62 @@
+package tracing
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/pkg/errors"
+	"go.opencensus.io/trace"
+)
+
+// JSONLinesExporter writes each span as a line of Zipkin JSON, which can be
+// read as is or posted to a collector later.
+type JSONLinesExporter struct {
+	service string
+
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+// NewJSONLinesExporter writes the spans of service to w.
+func NewJSONLinesExporter(w io.Writer, service string) *JSONLinesExporter {
+	return &JSONLinesExporter{service: service, w: w}
+}
+
+// OpenJSONLines appends the spans of service to the file at path, creating
+// it if needed. A path of - is stdout.
+func OpenJSONLines(path, service string) (*JSONLinesExporter, error) {
+	if path == "-" {
+		return NewJSONLinesExporter(os.Stdout, service), nil
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to open span file")
+	}
+	e := NewJSONLinesExporter(f, service)
+	e.closer = f
+	return e, nil
+}
+
+// ExportSpan implements trace.Exporter.
+func (e *JSONLinesExporter) ExportSpan(sd *trace.SpanData) {
+	line, err := json.Marshal(NewSpan(sd, e.service))
+	if err != nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.w.Write(append(line, '\n'))
+}
+
+// Close closes the file OpenJSONLines opened.
+func (e *JSONLinesExporter) Close() error {
+	if e.closer == nil {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.closer.Close()
+}
@@ -0,0 +1,144 @@
+// Package tracing joins grpctest's OpenCensus spans to the traces of the
+// mesh, and exports them. Istio's sidecars propagate B3 headers, and W3C
+// traceparent in newer versions, while OpenCensus only reads B3 over HTTP
+// and its own grpc-trace-bin from gRPC metadata, so both are read here.
+// The exporters write spans in the Zipkin v2 JSON model.
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"strings"
+
+	"go.opencensus.io/plugin/ocgrpc"
+	"go.opencensus.io/plugin/ochttp/propagation/b3"
+	"go.opencensus.io/trace"
+	"go.opencensus.io/trace/propagation"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/stats"
+)
+
+const (
+	TraceParentHeader = "traceparent"
+	// B3FlagsHeader is 1 for debug traces, which are always sampled.
+	B3FlagsHeader = "X-B3-Flags"
+
+	grpcTraceBinKey = "grpc-trace-bin"
+)
+
+// HTTPFormat is an ochttp propagation format that reads a traceparent
+// header, or failing that B3 headers, and writes both.
+type HTTPFormat struct{}
+
+var _ propagation.HTTPFormat = HTTPFormat{}
+
+func (HTTPFormat) SpanContextFromRequest(r *http.Request) (trace.SpanContext, bool) {
+	return FromHeader(r.Header)
+}
+
+func (HTTPFormat) SpanContextToRequest(sc trace.SpanContext, r *http.Request) {
+	(&b3.HTTPFormat{}).SpanContextToRequest(sc, r)
+	r.Header.Set(TraceParentHeader, TraceParent(sc))
+}
+
+// FromHeader reads the span context from a traceparent header or B3
+// headers.
+func FromHeader(h http.Header) (trace.SpanContext, bool) {
+	if sc, ok := ParseTraceParent(h.Get(TraceParentHeader)); ok {
+		return sc, true
+	}
+	return parseB3(h)
+}
+
+// FromMetadata is FromHeader for gRPC metadata.
+func FromMetadata(md metadata.MD) (trace.SpanContext, bool) {
+	h := make(http.Header, len(md))
+	for key, values := range md {
+		h[textproto.CanonicalMIMEHeaderKey(key)] = values
+	}
+	return FromHeader(h)
+}
+
+func parseB3(h http.Header) (trace.SpanContext, bool) {
+	// b3's parsers panic on IDs longer than they expect.
+	tid, sid := h.Get(b3.TraceIDHeader), h.Get(b3.SpanIDHeader)
+	if len(tid) != 16 && len(tid) != 32 || len(sid) != 16 {
+		return trace.SpanContext{}, false
+	}
+	var (
+		sc  trace.SpanContext
+		ok1 bool
+		ok2 bool
+	)
+	sc.TraceID, ok1 = b3.ParseTraceID(tid)
+	sc.SpanID, ok2 = b3.ParseSpanID(sid)
+	if !ok1 || !ok2 {
+		return trace.SpanContext{}, false
+	}
+	sc.TraceOptions, _ = b3.ParseSampled(h.Get(b3.SampledHeader))
+	if h.Get(B3FlagsHeader) == "1" {
+		sc.TraceOptions = 1
+	}
+	return sc, true
+}
+
+// ParseTraceParent parses a W3C traceparent header, e.g.
+// 00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01.
+func ParseTraceParent(value string) (trace.SpanContext, bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 {
+		return trace.SpanContext{}, false
+	}
+	var version, flags [1]byte
+	var sc trace.SpanContext
+	if !decodeHex(version[:], parts[0]) || version[0] == 0xff {
+		return trace.SpanContext{}, false
+	}
+	// Later versions may add fields; version 00 has exactly four.
+	if version[0] == 0 && len(parts) != 4 {
+		return trace.SpanContext{}, false
+	}
+	if !decodeHex(sc.TraceID[:], parts[1]) || !decodeHex(sc.SpanID[:], parts[2]) || !decodeHex(flags[:], parts[3]) {
+		return trace.SpanContext{}, false
+	}
+	if sc.TraceID == (trace.TraceID{}) || sc.SpanID == (trace.SpanID{}) {
+		return trace.SpanContext{}, false
+	}
+	sc.TraceOptions = trace.TraceOptions(flags[0] & 1)
+	return sc, true
+}
+
+// decodeHex decodes s, which must be lower case, into all of b.
+func decodeHex(b []byte, s string) bool {
+	if len(s) != 2*len(b) || strings.ToLower(s) != s {
+		return false
+	}
+	_, err := hex.Decode(b, []byte(s))
+	return err == nil
+}
+
+// TraceParent renders sc as a version 00 traceparent header.
+func TraceParent(sc trace.SpanContext) string {
+	return fmt.Sprintf("00-%x-%x-%02x", sc.TraceID[:], sc.SpanID[:], uint32(sc.TraceOptions)&1)
+}
+
+// ServerHandler is ocgrpc's ServerHandler, but a call without
+// grpc-trace-bin, such as one from the sidecar, takes its parent from
+// traceparent or B3 metadata instead.
+type ServerHandler struct {
+	ocgrpc.ServerHandler
+}
+
+func (h *ServerHandler) TagRPC(ctx context.Context, info *stats.RPCTagInfo) context.Context {
+	if md, ok := metadata.FromIncomingContext(ctx); ok && len(md[grpcTraceBinKey]) == 0 {
+		if sc, ok := FromMetadata(md); ok {
+			md = md.Copy()
+			md[grpcTraceBinKey] = []string{string(propagation.Binary(sc))}
+			ctx = metadata.NewIncomingContext(ctx, md)
+		}
+	}
+	return h.ServerHandler.TagRPC(ctx, info)
+}
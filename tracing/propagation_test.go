@@ -0,0 +1,254 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http"
+	"testing"
+
+	"go.opencensus.io/trace"
+	"go.opencensus.io/trace/propagation"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/stats"
+)
+
+const (
+	testTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testSpanID  = "00f067aa0ba902b7"
+)
+
+// spanContext builds a span context from hex IDs; a short trace ID fills
+// the lower half, as B3's 64-bit IDs do.
+func spanContext(t *testing.T, traceID, spanID string, sampled bool) trace.SpanContext {
+	t.Helper()
+	var sc trace.SpanContext
+	tid, err := hex.DecodeString(traceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	copy(sc.TraceID[len(sc.TraceID)-len(tid):], tid)
+	sid, err := hex.DecodeString(spanID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	copy(sc.SpanID[:], sid)
+	if sampled {
+		sc.TraceOptions = 1
+	}
+	return sc
+}
+
+func TestFromHeader(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		headers map[string]string
+		ok      bool
+		traceID string
+		sampled bool
+	}{
+		{
+			name:    "sampled traceparent",
+			headers: map[string]string{"traceparent": "00-" + testTraceID + "-" + testSpanID + "-01"},
+			ok:      true, traceID: testTraceID, sampled: true,
+		},
+		{
+			name:    "unsampled traceparent",
+			headers: map[string]string{"traceparent": "00-" + testTraceID + "-" + testSpanID + "-00"},
+			ok:      true, traceID: testTraceID,
+		},
+		{
+			name:    "later version with more fields",
+			headers: map[string]string{"traceparent": "01-" + testTraceID + "-" + testSpanID + "-01-what-comes-next"},
+			ok:      true, traceID: testTraceID, sampled: true,
+		},
+		{
+			name:    "version 00 with more fields",
+			headers: map[string]string{"traceparent": "00-" + testTraceID + "-" + testSpanID + "-01-extra"},
+		},
+		{
+			name:    "upper case",
+			headers: map[string]string{"traceparent": "00-4BF92F3577B34DA6A3CE929D0E0E4736-" + testSpanID + "-01"},
+		},
+		{
+			name:    "zero trace ID",
+			headers: map[string]string{"traceparent": "00-00000000000000000000000000000000-" + testSpanID + "-01"},
+		},
+		{
+			name:    "zero span ID",
+			headers: map[string]string{"traceparent": "00-" + testTraceID + "-0000000000000000-01"},
+		},
+		{
+			name:    "version ff",
+			headers: map[string]string{"traceparent": "ff-" + testTraceID + "-" + testSpanID + "-01"},
+		},
+		{
+			name:    "short traceparent",
+			headers: map[string]string{"traceparent": "00-" + testTraceID + "-" + testSpanID},
+		},
+		{
+			name: "B3 128-bit trace ID",
+			headers: map[string]string{
+				"X-B3-TraceId": testTraceID,
+				"X-B3-SpanId":  testSpanID,
+				"X-B3-Sampled": "1",
+			},
+			ok: true, traceID: testTraceID, sampled: true,
+		},
+		{
+			name: "B3 64-bit trace ID",
+			headers: map[string]string{
+				"X-B3-TraceId": "a3ce929d0e0e4736",
+				"X-B3-SpanId":  testSpanID,
+				"X-B3-Sampled": "0",
+			},
+			ok: true, traceID: "a3ce929d0e0e4736",
+		},
+		{
+			name: "B3 trace ID too long",
+			headers: map[string]string{
+				"X-B3-TraceId": testTraceID + "ab",
+				"X-B3-SpanId":  testSpanID,
+			},
+		},
+		{
+			name: "B3 span ID too long",
+			headers: map[string]string{
+				"X-B3-TraceId": testTraceID,
+				"X-B3-SpanId":  testSpanID + "ab",
+			},
+		},
+		{
+			name: "B3 not hex",
+			headers: map[string]string{
+				"X-B3-TraceId": "4bf92f3577b34da6a3ce929d0e0e473z",
+				"X-B3-SpanId":  testSpanID,
+			},
+		},
+		{
+			name: "B3 debug flag",
+			headers: map[string]string{
+				"X-B3-TraceId": testTraceID,
+				"X-B3-SpanId":  testSpanID,
+				"X-B3-Flags":   "1",
+			},
+			ok: true, traceID: testTraceID, sampled: true,
+		},
+		{
+			name: "traceparent over B3",
+			headers: map[string]string{
+				"traceparent":  "00-" + testTraceID + "-" + testSpanID + "-00",
+				"X-B3-TraceId": "0af7651916cd43dd8448eb211c80319c",
+				"X-B3-SpanId":  testSpanID,
+				"X-B3-Sampled": "1",
+			},
+			ok: true, traceID: testTraceID,
+		},
+		{
+			name: "B3 when traceparent is invalid",
+			headers: map[string]string{
+				"traceparent":  "00-" + testTraceID + "-" + testSpanID,
+				"X-B3-TraceId": "0af7651916cd43dd8448eb211c80319c",
+				"X-B3-SpanId":  testSpanID,
+			},
+			ok: true, traceID: "0af7651916cd43dd8448eb211c80319c",
+		},
+		{name: "none", headers: map[string]string{}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h := http.Header{}
+			for k, v := range tc.headers {
+				h.Set(k, v)
+			}
+			sc, ok := FromHeader(h)
+			if ok != tc.ok {
+				t.Fatalf("got ok %t, want %t", ok, tc.ok)
+			}
+			if !ok {
+				return
+			}
+			if want := spanContext(t, tc.traceID, testSpanID, tc.sampled); sc != want {
+				t.Errorf("got %+v, want %+v", sc, want)
+			}
+		})
+	}
+}
+
+func TestTraceParentRoundTrip(t *testing.T) {
+	for _, sampled := range []bool{true, false} {
+		sc := spanContext(t, testTraceID, testSpanID, sampled)
+		got, ok := ParseTraceParent(TraceParent(sc))
+		if !ok || got != sc {
+			t.Errorf("%s parsed as %+v, %t", TraceParent(sc), got, ok)
+		}
+	}
+}
+
+func TestServerHandlerTagRPC(t *testing.T) {
+	b3 := spanContext(t, testTraceID, testSpanID, true)
+	bin := spanContext(t, "0af7651916cd43dd8448eb211c80319c", "b7ad6b7169203331", true)
+
+	for _, tc := range []struct {
+		name string
+		md   metadata.MD
+		// parent is the span context grpc-trace-bin carries afterwards,
+		// if any.
+		parent *trace.SpanContext
+	}{
+		{
+			name:   "B3 only",
+			md:     metadata.Pairs("x-b3-traceid", testTraceID, "x-b3-spanid", testSpanID, "x-b3-sampled", "1"),
+			parent: &b3,
+		},
+		{
+			name:   "traceparent only",
+			md:     metadata.Pairs("traceparent", TraceParent(b3)),
+			parent: &b3,
+		},
+		{
+			name: "grpc-trace-bin kept",
+			md: metadata.Pairs(
+				"grpc-trace-bin", string(propagation.Binary(bin)),
+				"traceparent", TraceParent(b3),
+			),
+			parent: &bin,
+		},
+		{
+			name: "nothing to propagate",
+			md:   metadata.Pairs("user-agent", "grpc-go"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h := &ServerHandler{}
+			h.StartOptions.Sampler = trace.AlwaysSample()
+			had := len(tc.md[grpcTraceBinKey])
+			ctx := metadata.NewIncomingContext(context.Background(), tc.md)
+			ctx = h.TagRPC(ctx, &stats.RPCTagInfo{FullMethodName: "/MathService/Square"})
+			if len(tc.md[grpcTraceBinKey]) != had {
+				t.Error("the incoming metadata was modified in place")
+			}
+
+			md, _ := metadata.FromIncomingContext(ctx)
+			bins := md[grpcTraceBinKey]
+			if tc.parent == nil {
+				if len(bins) != 0 {
+					t.Fatalf("got grpc-trace-bin %x, want none", bins)
+				}
+				return
+			}
+			if len(bins) != 1 {
+				t.Fatalf("got %d grpc-trace-bin values, want 1", len(bins))
+			}
+			if got, _ := propagation.FromBinary([]byte(bins[0])); got != *tc.parent {
+				t.Errorf("got grpc-trace-bin %+v, want %+v", got, *tc.parent)
+			}
+			span := trace.FromContext(ctx)
+			if span == nil {
+				t.Fatal("no span started")
+			}
+			defer span.End()
+			if got := span.SpanContext().TraceID; got != tc.parent.TraceID {
+				t.Errorf("span joined trace %x, want %x", got, tc.parent.TraceID)
+			}
+		})
+	}
+}
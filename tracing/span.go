@@ -0,0 +1,105 @@
+package tracing
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"go.opencensus.io/trace"
+	"google.golang.org/grpc/codes"
+)
+
+// Exporter is a trace.Exporter to close once no more spans will come, so
+// it can export what it buffered.
+type Exporter interface {
+	trace.Exporter
+	io.Closer
+}
+
+// Span is a span in the Zipkin v2 JSON model, see
+// https://zipkin.io/zipkin-api/#/default/post_spans.
+type Span struct {
+	TraceID       string            `json:"traceId"`
+	ID            string            `json:"id"`
+	ParentID      string            `json:"parentId,omitempty"`
+	Name          string            `json:"name"`
+	Kind          string            `json:"kind,omitempty"`
+	Timestamp     int64             `json:"timestamp"`
+	Duration      int64             `json:"duration"`
+	LocalEndpoint *Endpoint         `json:"localEndpoint,omitempty"`
+	Annotations   []Annotation      `json:"annotations,omitempty"`
+	Tags          map[string]string `json:"tags,omitempty"`
+}
+
+type Endpoint struct {
+	ServiceName string `json:"serviceName"`
+}
+
+type Annotation struct {
+	Timestamp int64  `json:"timestamp"`
+	Value     string `json:"value"`
+}
+
+// NewSpan converts an OpenCensus span of service. Times are in
+// microseconds, the attributes become tags, and a status other than OK
+// sets the error tag.
+func NewSpan(sd *trace.SpanData, service string) Span {
+	s := Span{
+		TraceID:       hex.EncodeToString(sd.TraceID[:]),
+		ID:            hex.EncodeToString(sd.SpanID[:]),
+		Name:          sd.Name,
+		Timestamp:     micros(sd.StartTime),
+		Duration:      int64(sd.EndTime.Sub(sd.StartTime) / time.Microsecond),
+		LocalEndpoint: &Endpoint{ServiceName: service},
+	}
+	// Zipkin refuses spans without a duration.
+	if s.Duration < 1 {
+		s.Duration = 1
+	}
+	if sd.ParentSpanID != (trace.SpanID{}) {
+		s.ParentID = hex.EncodeToString(sd.ParentSpanID[:])
+	}
+	switch sd.SpanKind {
+	case trace.SpanKindServer:
+		s.Kind = "SERVER"
+	case trace.SpanKindClient:
+		s.Kind = "CLIENT"
+	}
+
+	if len(sd.Attributes) > 0 || sd.Code != trace.StatusCodeOK {
+		s.Tags = make(map[string]string, len(sd.Attributes)+2)
+	}
+	for key, value := range sd.Attributes {
+		s.Tags[key] = fmt.Sprint(value)
+	}
+	if sd.Code != trace.StatusCodeOK {
+		// OpenCensus status codes are gRPC's.
+		code := codes.Code(sd.Code).String()
+		s.Tags["opencensus.status_code"] = code
+		s.Tags["error"] = code
+		if sd.Message != "" {
+			s.Tags["error"] = sd.Message
+		}
+	}
+
+	for _, a := range sd.Annotations {
+		s.Annotations = append(s.Annotations, Annotation{micros(a.Time), a.Message})
+	}
+	for _, e := range sd.MessageEvents {
+		value := "RECV"
+		if e.EventType == trace.MessageEventTypeSent {
+			value = "SENT"
+		}
+		s.Annotations = append(s.Annotations, Annotation{micros(e.Time), value})
+	}
+	sort.SliceStable(s.Annotations, func(i, j int) bool {
+		return s.Annotations[i].Timestamp < s.Annotations[j].Timestamp
+	})
+	return s
+}
+
+func micros(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Microsecond)
+}
@@ -0,0 +1,132 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.opencensus.io/trace"
+)
+
+const (
+	// Spans are posted DefaultBatchSize at a time, or whatever has been
+	// queued every DefaultFlushInterval.
+	DefaultBatchSize     = 100
+	DefaultFlushInterval = time.Second
+	// DefaultQueueSize spans can wait to be posted; any more are dropped.
+	DefaultQueueSize = 1000
+)
+
+// ZipkinExporter posts spans in batches to a Zipkin v2 collector, e.g.
+// http://zipkin:9411/api/v2/spans. It drops spans when the collector can't
+// keep up, rather than slow down calls.
+type ZipkinExporter struct {
+	url     string
+	service string
+	client  *http.Client
+	log     *logrus.Entry
+
+	spans   chan Span
+	stop    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+	dropped int64
+}
+
+// NewZipkinExporter posts the spans of service to url. A nil client uses
+// http.DefaultClient.
+func NewZipkinExporter(url, service string, client *http.Client, log *logrus.Entry) *ZipkinExporter {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	e := &ZipkinExporter{
+		url:     url,
+		service: service,
+		client:  client,
+		log:     log,
+		spans:   make(chan Span, DefaultQueueSize),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+// ExportSpan implements trace.Exporter.
+func (e *ZipkinExporter) ExportSpan(sd *trace.SpanData) {
+	select {
+	case <-e.stop:
+	case e.spans <- NewSpan(sd, e.service):
+	default:
+		atomic.AddInt64(&e.dropped, 1)
+	}
+}
+
+// Close posts the spans still queued and stops.
+func (e *ZipkinExporter) Close() error {
+	e.once.Do(func() { close(e.stop) })
+	<-e.done
+	return nil
+}
+
+func (e *ZipkinExporter) run() {
+	defer close(e.done)
+	ticker := time.NewTicker(DefaultFlushInterval)
+	defer ticker.Stop()
+	var batch []Span
+	for {
+		select {
+		case s := <-e.spans:
+			batch = append(batch, s)
+			if len(batch) < DefaultBatchSize {
+				continue
+			}
+		case <-ticker.C:
+		case <-e.stop:
+			for {
+				select {
+				case s := <-e.spans:
+					batch = append(batch, s)
+				default:
+					e.post(batch)
+					return
+				}
+			}
+		}
+		e.post(batch)
+		batch = nil
+	}
+}
+
+func (e *ZipkinExporter) post(batch []Span) {
+	if n := atomic.SwapInt64(&e.dropped, 0); n > 0 {
+		e.log.WithField("spans", n).Warn("span queue full; dropped spans")
+	}
+	if len(batch) == 0 {
+		return
+	}
+	body, err := json.Marshal(batch)
+	if err != nil {
+		e.log.WithError(err).Error("unable to encode spans")
+		return
+	}
+	resp, err := e.client.Post(e.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		e.log.WithError(err).WithField("spans", len(batch)).Warn("unable to post spans")
+		return
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		e.log.WithFields(logrus.Fields{
+			"spans":  len(batch),
+			"status": resp.Status,
+		}).Warn("collector refused spans")
+	}
+}
@@ -0,0 +1,225 @@
+package tracing
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.opencensus.io/trace"
+)
+
+// collector is a Zipkin collector keeping the batches posted to it.
+type collector struct {
+	mu      sync.Mutex
+	status  int
+	batches [][]Span
+	err     string
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch {
+	case r.Method != http.MethodPost || r.URL.Path != "/api/v2/spans":
+		c.err = "got " + r.Method + " " + r.URL.Path
+	case r.Header.Get("Content-Type") != "application/json":
+		c.err = "got Content-Type " + r.Header.Get("Content-Type")
+	}
+	var batch []Span
+	if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+		c.err = err.Error()
+	}
+	c.batches = append(c.batches, batch)
+	if c.status != 0 {
+		w.WriteHeader(c.status)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// export posts spans to a collector answering status, and returns the
+// batches it got.
+func export(t *testing.T, status int, spans ...*trace.SpanData) [][]Span {
+	t.Helper()
+	c := &collector{status: status}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+	log := logrus.New()
+	log.Out = ioutil.Discard
+
+	e := NewZipkinExporter(srv.URL+"/api/v2/spans", "grpctest", srv.Client(), logrus.NewEntry(log))
+	for _, sd := range spans {
+		e.ExportSpan(sd)
+	}
+	e.Close()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err != "" {
+		t.Fatal(c.err)
+	}
+	return c.batches
+}
+
+func TestZipkinExporter(t *testing.T) {
+	start := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	startMicros := start.UnixNano() / int64(time.Microsecond)
+	sc := trace.SpanContext{
+		TraceID: trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:  trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+	}
+	const (
+		traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		spanID  = "00f067aa0ba902b7"
+	)
+
+	for _, tc := range []struct {
+		name string
+		// status is what the collector answers; a refused batch is not
+		// retried.
+		status int
+		span   *trace.SpanData
+		want   Span
+	}{
+		{
+			name: "server span",
+			span: &trace.SpanData{
+				SpanContext: sc,
+				SpanKind:    trace.SpanKindServer,
+				Name:        "MathService.Square",
+				StartTime:   start,
+				EndTime:     start.Add(1500 * time.Microsecond),
+				Attributes:  map[string]interface{}{"tenant": "ACME", "cached": false},
+				Annotations: []trace.Annotation{{Time: start.Add(time.Millisecond), Message: "computed"}},
+				MessageEvents: []trace.MessageEvent{
+					{Time: start.Add(1200 * time.Microsecond), EventType: trace.MessageEventTypeSent},
+					{Time: start, EventType: trace.MessageEventTypeRecv},
+				},
+			},
+			want: Span{
+				TraceID:       traceID,
+				ID:            spanID,
+				Name:          "MathService.Square",
+				Kind:          "SERVER",
+				Timestamp:     startMicros,
+				Duration:      1500,
+				LocalEndpoint: &Endpoint{ServiceName: "grpctest"},
+				Annotations: []Annotation{
+					{startMicros, "RECV"},
+					{startMicros + 1000, "computed"},
+					{startMicros + 1200, "SENT"},
+				},
+				Tags: map[string]string{"tenant": "ACME", "cached": "false"},
+			},
+		},
+		{
+			name: "failed client span",
+			span: &trace.SpanData{
+				SpanContext:  sc,
+				ParentSpanID: trace.SpanID{0x01},
+				SpanKind:     trace.SpanKindClient,
+				Name:         "auth.jwks",
+				StartTime:    start,
+				EndTime:      start.Add(time.Second),
+				Status:       trace.Status{Code: trace.StatusCodeUnavailable, Message: "connection refused"},
+			},
+			want: Span{
+				TraceID:       traceID,
+				ID:            spanID,
+				ParentID:      "0100000000000000",
+				Name:          "auth.jwks",
+				Kind:          "CLIENT",
+				Timestamp:     startMicros,
+				Duration:      1000000,
+				LocalEndpoint: &Endpoint{ServiceName: "grpctest"},
+				Tags: map[string]string{
+					"opencensus.status_code": "Unavailable",
+					"error":                  "connection refused",
+				},
+			},
+		},
+		{
+			name: "no duration",
+			span: &trace.SpanData{
+				SpanContext: sc,
+				Name:        "quota.alloc",
+				StartTime:   start,
+				EndTime:     start,
+			},
+			want: Span{
+				TraceID:       traceID,
+				ID:            spanID,
+				Name:          "quota.alloc",
+				Timestamp:     startMicros,
+				Duration:      1,
+				LocalEndpoint: &Endpoint{ServiceName: "grpctest"},
+			},
+		},
+		{
+			name:   "refused",
+			status: http.StatusBadRequest,
+			span: &trace.SpanData{
+				SpanContext: sc,
+				Name:        "quota.alloc",
+				StartTime:   start,
+				EndTime:     start.Add(time.Microsecond),
+			},
+			want: Span{
+				TraceID:       traceID,
+				ID:            spanID,
+				Name:          "quota.alloc",
+				Timestamp:     startMicros,
+				Duration:      1,
+				LocalEndpoint: &Endpoint{ServiceName: "grpctest"},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			batches := export(t, tc.status, tc.span)
+			if len(batches) != 1 || len(batches[0]) != 1 {
+				t.Fatalf("got batches %v, want one span", batches)
+			}
+			if got := batches[0][0]; !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got span\n%+v\nwant\n%+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestZipkinExporterBatches(t *testing.T) {
+	c := &collector{}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+	log := logrus.New()
+	log.Out = ioutil.Discard
+	e := NewZipkinExporter(srv.URL+"/api/v2/spans", "grpctest", srv.Client(), logrus.NewEntry(log))
+	defer e.Close()
+
+	start := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < DefaultBatchSize; i++ {
+		e.ExportSpan(&trace.SpanData{Name: "span", StartTime: start, EndTime: start})
+	}
+	// A full batch is posted without waiting for the flush interval.
+	deadline := time.Now().Add(DefaultFlushInterval / 2)
+	for {
+		c.mu.Lock()
+		batches := c.batches
+		c.mu.Unlock()
+		if len(batches) > 0 {
+			if len(batches[0]) != DefaultBatchSize {
+				t.Errorf("got a batch of %d spans, want %d", len(batches[0]), DefaultBatchSize)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("a full batch was not posted")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
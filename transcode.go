@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"net"
+
+	"github.com/sirupsen/logrus"
+	"github.com/zenoss/grpctest/certs"
+	"github.com/zenoss/grpctest/gateway"
+	"go.opencensus.io/plugin/ocgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// newGateway serves the REST mapping from the google.api.http annotations on
+// the HTTP port, calling back into our own gRPC listener so requests pass
+// through the same interceptors as native gRPC calls. Without a descriptor
+// file configured the descriptor compiled into the pb package is used.
+// With TLS the gateway presents the server's own certificate, which the
+// listener trusts even when it requires client certificates. When tracing,
+// its calls are spans of the HTTP request's trace.
+func newGateway(ctx context.Context, cfg *Config, store *certs.Store, log *logrus.Entry) (*gateway.Gateway, error) {
+	opts := []grpc.DialOption{grpc.WithInsecure()}
+	if store != nil {
+		opts[0] = grpc.WithTransportCredentials(credentials.NewTLS(store.ClientConfig()))
+	}
+	if cfg.Tracing.Exporter != "none" {
+		opts = append(opts, grpc.WithStatsHandler(&ocgrpc.ClientHandler{}))
+	}
+	conn, err := grpc.Dial(loopbackAddr(cfg.GRPC.ListenAddr), opts...)
+	if err != nil {
+		return nil, err
+	}
+	gw := gateway.New(conn, gateway.Config{
+		Services:    cfg.Gateway.Services,
+		AutoMapping: cfg.Gateway.AutoMapping,
+	}, log.WithField("component", "gateway"))
+
+	path := cfg.Gateway.Descriptor
+	if path == "" {
+		set, err := gateway.EmbeddedDescriptorSet("pb/grpc_test.proto")
+		if err != nil {
+			return nil, err
+		}
+		return gw, gw.Load(set)
+	}
+	if err := gw.LoadFile(path); err != nil {
+		return nil, err
+	}
+	return gw, gw.WatchFile(ctx, path)
+}
+
+// loopbackAddr turns a listen address into one we can dial ourselves.
+func loopbackAddr(listen string) string {
+	host, port, err := net.SplitHostPort(listen)
+	if err != nil {
+		return listen
+	}
+	switch host {
+	case "", "0.0.0.0", "::":
+		host = "localhost"
+	}
+	return net.JoinHostPort(host, port)
+}